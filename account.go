@@ -6,7 +6,7 @@ import "time"
 type Account struct {
 	ID                           int                 `json:"id"`
 	Title                        string              `json:"title"`
-	Type                         string              `json:"type"`
+	Type                         AccountType         `json:"type"`
 	IsNetWorth                   bool                `json:"is_net_worth"`
 	CurrencyCode                 string              `json:"currency_code"`
 	CurrentBalance               float64             `json:"current_balance"`
@@ -19,3 +19,46 @@ type Account struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// AccountType defines the type of a PocketSmith account.
+type AccountType string
+
+const (
+	AccountTypeBank           AccountType = "bank"
+	AccountTypeCredits        AccountType = "credits"
+	AccountTypeCash           AccountType = "cash"
+	AccountTypeStocks         AccountType = "stocks"
+	AccountTypeMortgage       AccountType = "mortgage"
+	AccountTypeLoans          AccountType = "loans"
+	AccountTypeVehicle        AccountType = "vehicle"
+	AccountTypeProperty       AccountType = "property"
+	AccountTypeInsurance      AccountType = "insurance"
+	AccountTypeOtherAsset     AccountType = "other_asset"
+	AccountTypeOtherLiability AccountType = "other_liability"
+)
+
+// AccountTypes are every type of account, in the order the API documents
+// them.
+var AccountTypes = []AccountType{
+	AccountTypeBank,
+	AccountTypeCredits,
+	AccountTypeCash,
+	AccountTypeStocks,
+	AccountTypeMortgage,
+	AccountTypeLoans,
+	AccountTypeVehicle,
+	AccountTypeProperty,
+	AccountTypeInsurance,
+	AccountTypeOtherAsset,
+	AccountTypeOtherLiability,
+}
+
+// Valid returns if the account type is one the API accepts.
+func (t AccountType) Valid() bool {
+	for _, v := range AccountTypes {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
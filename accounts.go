@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -15,10 +14,10 @@ type Accounts []Account
 
 // CreateAccountOptions defines the options for creating an account for a user.
 type CreateAccountOptions struct {
-	InstitutionID int    `json:"institution_id"`
-	Title         string `json:"title"`
-	CurrencyCode  string `json:"currency_code"`
-	Type          string `json:"type"` // TODO enum?
+	InstitutionID int         `json:"institution_id"`
+	Title         string      `json:"title"`
+	CurrencyCode  string      `json:"currency_code"`
+	Type          AccountType `json:"type"`
 }
 
 // CreateAccountForUserOptions ...
@@ -80,6 +79,200 @@ func (c *Client) CreateAccount(
 	)
 }
 
+// GetAccountOptions defines the options for getting an account, by the
+// account id.
+type GetAccountOptions struct {
+	AccountID int `validator:"required"`
+}
+
+// GetAccount, using the given account id, gets an account.
+// https://developers.pocketsmith.com/reference/get_accounts-id-1.
+func (c *Client) GetAccount(ctx context.Context, options *GetAccountOptions) (account *Account, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "GetAccount")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// get account.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/accounts/%v", options.AccountID),
+	}, &account)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to get account: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return account, nil
+}
+
+// UpdateAccountOptions defines the options for updating an account, by the
+// account id. Fields left empty are unchanged.
+type UpdateAccountOptions struct {
+	AccountID    int         `json:"-"                       validator:"required"`
+	Title        string      `json:"title,omitempty"`
+	CurrencyCode string      `json:"currency_code,omitempty"`
+	Type         AccountType `json:"type,omitempty"`
+	IsNetWorth   *bool       `json:"is_net_worth,omitempty"` // nil leaves the flag unchanged.
+}
+
+// UpdateAccount, using the given account id, updates an account.
+// https://developers.pocketsmith.com/reference/put_accounts-id-1.
+func (c *Client) UpdateAccount(
+	ctx context.Context,
+	options *UpdateAccountOptions,
+) (account *Account, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "UpdateAccount")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// update account.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/accounts/%v", options.AccountID),
+		body:   options,
+	}, &account)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to update account: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return account, nil
+}
+
+// UpdateAccountsDisplayOrderOptions defines the options for changing the
+// order the accounts of the authed user are displayed in.
+type UpdateAccountsDisplayOrderOptions struct {
+	AccountIDs []int `validator:"required"` // The ids of the accounts, in the order to display them.
+}
+
+// UpdateAccountsDisplayOrderForUserOptions defines the options for changing
+// the order the accounts of a user are displayed in, by the user id.
+type UpdateAccountsDisplayOrderForUserOptions struct {
+	UserID int `validator:"required"`
+
+	UpdateAccountsDisplayOrderOptions
+}
+
+// UpdateAccountsDisplayOrderForUser, using the given user id, changes the
+// order the accounts of a user are displayed in, returning the accounts in
+// their new order. Accounts that aren't given keep their place after those
+// that are.
+// https://developers.pocketsmith.com/reference/put_users-id-accounts-1.
+func (c *Client) UpdateAccountsDisplayOrderForUser(
+	ctx context.Context,
+	options *UpdateAccountsDisplayOrderForUserOptions,
+) (accounts Accounts, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "UpdateAccountsDisplayOrderForUser")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// update display order; the API orders accounts by their position in the
+	// list sent.
+	type order struct {
+		ID int `json:"id"`
+	}
+	body := struct {
+		Accounts []order `json:"accounts"`
+	}{}
+	for _, id := range options.AccountIDs {
+		body.Accounts = append(body.Accounts, order{id})
+	}
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/users/%v/accounts", options.UserID),
+		body:   body,
+	}, &accounts)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to update accounts display order: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// UpdateAccountsDisplayOrder, using the token attached to the client, changes
+// the order the accounts of the authed user are displayed in.
+func (c *Client) UpdateAccountsDisplayOrder(
+	ctx context.Context,
+	options *UpdateAccountsDisplayOrderOptions,
+) (Accounts, error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "UpdateAccountsDisplayOrder")
+	defer span.End()
+
+	// update display order for authed user.
+	return c.UpdateAccountsDisplayOrderForUser(
+		newCtx,
+		&UpdateAccountsDisplayOrderForUserOptions{
+			UserID:                            c.authedUser.ID,
+			UpdateAccountsDisplayOrderOptions: *options,
+		},
+	)
+}
+
+// ListInstitutionAccountsOptions defines the options for listing the accounts
+// in an institution, by the institution id.
+type ListInstitutionAccountsOptions struct {
+	InstitutionID int `validator:"required"`
+}
+
+// ListInstitutionAccounts, using the given institution id, lists the accounts
+// in an institution.
+// https://developers.pocketsmith.com/reference/get_institutions-id-accounts-1.
+func (c *Client) ListInstitutionAccounts(
+	ctx context.Context,
+	options *ListInstitutionAccountsOptions,
+) (accounts Accounts, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListInstitutionAccounts")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// list accounts.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/institutions/%v/accounts", options.InstitutionID),
+	}, &accounts)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to list institution accounts: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return accounts, nil
+}
+
 // DeleteAccountOptions ...
 type DeleteAccountOptions struct {
 	AccountID int `validator:"required"`
@@ -175,7 +368,7 @@ type ListAccountTransactionsOptions struct {
 	Page          int    `json:"page,omitempty"`
 }
 
-// ListTransactionAccountTransactions, using the given account id, lists the
+// ListAccountTransactions, using the given account id, lists the
 // transactions for an account.
 // https://developers.pocketsmith.com/reference/get_accounts-id-transactions-1
 func (c *Client) ListAccountTransactions(
@@ -187,41 +380,56 @@ func (c *Client) ListAccountTransactions(
 	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListAccountTransactions")
 	defer span.End()
 
+	// retrieve transactions for account.
+	err = c.ListAccountTransactionsPages(newCtx, options, func(batch Transactions) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ListAccountTransactionsPages, using the given account id, lists the
+// transactions for an account, calling fn with each page of transactions as it
+// is returned from the API. Returning an error from fn stops the listing and
+// returns that error.
+func (c *Client) ListAccountTransactionsPages(
+	ctx context.Context,
+	options *ListAccountTransactionsOptions,
+	fn func(Transactions) error,
+) error {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListAccountTransactionsPages")
+	defer span.End()
+
 	// validate options.
 	if err := c.validator.StructCtx(newCtx, options); err != nil {
 		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
 		span.RecordError(err)
-		return nil, err
+		return err
 	}
 
 	// setup request.
+	queries, err := toQueries(options)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to setup queries: %v", err))
+		span.RecordError(err)
+		return err
+	}
 	sr := senderRequest{
 		method:  http.MethodGet,
 		path:    fmt.Sprintf("/accounts/%v/transactions", options.AccountID),
-		body:    options,
-		queries: setupQueries(nil),
+		queries: setupQueries(queries),
 	}
 
 	// retrieve transactions for account.
-	for {
-
-		// get respons
-		var batch Transactions
-		resp, err := c.sender(newCtx, sr, &batch)
-		if err != nil {
-			span.SetStatus(codes.Error, fmt.Sprintf("failed to list account transactions: %v", err))
-			span.RecordError(err)
-			return nil, err
-		}
-		transactions = append(transactions, batch...)
-
-		// paginate?
-		next := getHeader(resp.Header, "next")
-		if next == "" {
-			break
-		}
-		sr.path = strings.Replace(next, c.endpoint, "", -1)
+	if err := c.transactionPages(newCtx, sr, fn); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to list account transactions: %v", err))
+		span.RecordError(err)
+		return err
 	}
-
-	return transactions, nil
+	return nil
 }
@@ -0,0 +1,108 @@
+package pocketsmith
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func Test_accounts(t *testing.T) {
+
+	// setup mock; it records each request, and returns an empty account.
+	var got []string
+	c := newMockClient(func(req *http.Request) *http.Response {
+		body := ""
+		if req.Body != nil {
+			b, _ := io.ReadAll(req.Body)
+			body = string(b)
+		}
+		got = append(got, req.Method+" "+req.URL.Path+" "+body)
+		response := `{"id":1}`
+		if req.Method == http.MethodPut && req.URL.Path == "/users/1/accounts" ||
+			req.URL.Path == "/institutions/3/accounts" {
+			response = `[{"id":2},{"id":1}]`
+		}
+		return mockResponse(http.StatusOK, response)
+	})
+	c.authedUser = &User{ID: 1}
+	netWorth := false
+	tests := map[string]struct {
+		fn   func(ctx context.Context) error
+		want string
+	}{
+		"get": {
+			fn: func(ctx context.Context) error {
+				_, err := c.GetAccount(ctx, &GetAccountOptions{AccountID: 5})
+				return err
+			},
+			want: "GET /accounts/5 ",
+		},
+		"update": {
+			fn: func(ctx context.Context) error {
+				_, err := c.UpdateAccount(ctx, &UpdateAccountOptions{
+					AccountID:  5,
+					Title:      "Everyday",
+					Type:       AccountTypeBank,
+					IsNetWorth: &netWorth,
+				})
+				return err
+			},
+			want: `PUT /accounts/5 {"title":"Everyday","type":"bank","is_net_worth":false}`,
+		},
+		"reorder": {
+			fn: func(ctx context.Context) error {
+				accounts, err := c.UpdateAccountsDisplayOrder(ctx, &UpdateAccountsDisplayOrderOptions{
+					AccountIDs: []int{2, 1},
+				})
+				if err == nil && len(accounts) != 2 {
+					t.Errorf("UpdateAccountsDisplayOrder() returned unexpected accounts; got=%+v", accounts)
+				}
+				return err
+			},
+			want: `PUT /users/1/accounts {"accounts":[{"id":2},{"id":1}]}`,
+		},
+		"list by institution": {
+			fn: func(ctx context.Context) error {
+				accounts, err := c.ListInstitutionAccounts(ctx, &ListInstitutionAccountsOptions{InstitutionID: 3})
+				if err == nil && len(accounts) != 2 {
+					t.Errorf("ListInstitutionAccounts() returned unexpected accounts; got=%+v", accounts)
+				}
+				return err
+			},
+			want: "GET /institutions/3/accounts ",
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got = nil
+			if err := tt.fn(context.Background()); err != nil {
+				t.Fatalf("unexpected error returned: %v", err)
+			}
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("unexpected request sent; got: %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_AccountType_Valid(t *testing.T) {
+	tests := map[string]struct {
+		t    AccountType
+		want bool
+	}{
+		"bank":            {t: AccountTypeBank, want: true},
+		"other liability": {t: "other_liability", want: true},
+		"unknown":         {t: "savings", want: false},
+		"empty":           {t: "", want: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := tt.t.Valid(); got != tt.want {
+				t.Errorf("unexpected value returned; got: %v, want: %v\n", got, tt.want)
+			}
+		})
+	}
+}
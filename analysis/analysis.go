@@ -0,0 +1,48 @@
+// Package analysis finds patterns across a user's PocketSmith transactions,
+// such as transfers between their own accounts, duplicated transactions,
+// recurring payments, or balances that no longer add up.
+//
+// The analysers work on transactions that have already been listed (eg. via
+// ListTransactionsForUser, or a sync.Store), and return reports. Anything that
+// changes transactions in PocketSmith is done separately, through the API
+// interface, so that a report can be reviewed first.
+package analysis
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// The name of the tracer output in the traces.
+const tracerName = "pocketsmith-go/analysis"
+
+// The format of the dates returned from the API.
+const dateFormat = "2006-01-02"
+
+// API defines the parts of the *pocketsmith.Client used by the analysers.
+type API interface {
+	UpdateTransaction(
+		ctx context.Context,
+		options *pocketsmith.UpdateTransactionOptions,
+	) (*pocketsmith.Transaction, error)
+}
+
+// parseDate parses the given date, as returned from the API.
+func parseDate(date string) (time.Time, bool) {
+	d, err := time.Parse(dateFormat, date)
+	return d, err == nil
+}
+
+// daysBetween returns the absolute number of days between the given dates, or
+// -1 if either date is invalid.
+func daysBetween(a, b string) int {
+	da, okA := parseDate(a)
+	db, okB := parseDate(b)
+	if !okA || !okB {
+		return -1
+	}
+	return int(math.Abs(math.Round(da.Sub(db).Hours() / 24)))
+}
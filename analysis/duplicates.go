@@ -0,0 +1,418 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/internal/fuzzy"
+)
+
+// DuplicateOptions defines the options for finding duplicate transactions.
+type DuplicateOptions struct {
+
+	// MaxDays is the maximum number of days between duplicates. Defaults to 0
+	// (same day only).
+	MaxDays int
+
+	// AmountTolerance is the maximum absolute difference between the amounts
+	// of duplicates. Defaults to 0.005 (an exact match).
+	AmountTolerance float64
+
+	// PayeeSimilarity is the minimum similarity (from 0 to 1) between the
+	// payees of duplicates. Defaults to 0.8.
+	PayeeSimilarity float64
+
+	// OriginalPayeeSimilarity is the minimum similarity (from 0 to 1) between
+	// the original payees of duplicates. Transactions are duplicates if either
+	// their payees or their original payees are similar enough. Defaults to
+	// 0.8.
+	OriginalPayeeSimilarity float64
+}
+
+// DuplicateGroup defines a group of transactions that are likely duplicates
+// of each other.
+type DuplicateGroup struct {
+	Transactions pocketsmith.Transactions `json:"transactions"`
+	Confidence   float64                  `json:"confidence"` // How likely these are duplicates, from 0 to 1.
+}
+
+// String returns the group as a single line.
+func (g DuplicateGroup) String() string {
+	ids := make([]string, len(g.Transactions))
+	for i, t := range g.Transactions {
+		ids[i] = fmt.Sprint(t.ID)
+	}
+	t := g.Transactions[0]
+	return fmt.Sprintf(
+		"%s %.2f %s: transactions %s, confidence %.2f",
+		t.Date,
+		t.Amount,
+		t.Payee,
+		strings.Join(ids, ", "),
+		g.Confidence,
+	)
+}
+
+// FindDuplicates returns the groups of likely duplicate transactions in the
+// given transactions. Only transactions in the same transaction account are
+// compared.
+func FindDuplicates(
+	transactions pocketsmith.Transactions,
+	options *DuplicateOptions,
+) (groups []DuplicateGroup) {
+
+	// default options.
+	o := DuplicateOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.AmountTolerance == 0 {
+		o.AmountTolerance = 0.005
+	}
+	if o.PayeeSimilarity == 0 {
+		o.PayeeSimilarity = 0.8
+	}
+	if o.OriginalPayeeSimilarity == 0 {
+		o.OriginalPayeeSimilarity = 0.8
+	}
+
+	// group transactions by transaction account, ordered by date.
+	byAccount := make(map[int]pocketsmith.Transactions)
+	for _, t := range transactions {
+		byAccount[t.TransactionAccount.ID] = append(byAccount[t.TransactionAccount.ID], t)
+	}
+	accounts := make([]int, 0, len(byAccount))
+	for id := range byAccount {
+		accounts = append(accounts, id)
+	}
+	sort.Ints(accounts)
+
+	for _, id := range accounts {
+		ts := byAccount[id]
+		sort.SliceStable(ts, func(i, j int) bool { return ts[i].Date < ts[j].Date })
+
+		// link duplicate pairs; a union-find is used so that duplicates of
+		// duplicates end up in the same group.
+		parent := make([]int, len(ts))
+		for i := range parent {
+			parent[i] = i
+		}
+		var find func(i int) int
+		find = func(i int) int {
+			if parent[i] != i {
+				parent[i] = find(parent[i])
+			}
+			return parent[i]
+		}
+		scores := make(map[int][]float64)
+		for i := range ts {
+			for j := i + 1; j < len(ts); j++ {
+				days := daysBetween(ts[i].Date, ts[j].Date)
+				if days < 0 || days > o.MaxDays {
+					if days > o.MaxDays {
+						break // ordered by date, so nothing later can match.
+					}
+					continue
+				}
+				score, ok := o.score(ts[i], ts[j], days)
+				if !ok {
+					continue
+				}
+				a, b := find(i), find(j)
+				if a != b {
+					parent[b] = a
+					scores[a] = append(scores[a], scores[b]...)
+					delete(scores, b)
+				}
+				scores[a] = append(scores[a], score)
+			}
+		}
+
+		// collect groups.
+		members := make(map[int]pocketsmith.Transactions)
+		var roots []int
+		for i := range ts {
+			r := find(i)
+			if _, ok := members[r]; !ok {
+				roots = append(roots, r)
+			}
+			members[r] = append(members[r], ts[i])
+		}
+		for _, r := range roots {
+			if len(members[r]) < 2 {
+				continue
+			}
+			sum := 0.0
+			for _, s := range scores[r] {
+				sum += s
+			}
+			groups = append(groups, DuplicateGroup{
+				Transactions: members[r],
+				Confidence:   math.Round(sum/float64(len(scores[r]))*100) / 100,
+			})
+		}
+	}
+	return groups
+}
+
+// score returns how likely the given transactions are duplicates, from 0 to 1,
+// and if they are similar enough to be considered duplicates at all.
+func (o *DuplicateOptions) score(a, b pocketsmith.Transaction, days int) (float64, bool) {
+	diff := math.Abs(a.Amount - b.Amount)
+	if diff > o.AmountTolerance {
+		return 0, false
+	}
+
+	// compare payees; empty payees on both sides match on date & amount alone.
+	similarity := 1.0
+	payee := fuzzy.Similarity(a.Payee, b.Payee)
+	original := fuzzy.Similarity(a.OriginalPayee, b.OriginalPayee)
+	switch {
+	case a.Payee == "" && b.Payee == "" && a.OriginalPayee == "" && b.OriginalPayee == "":
+	case payee >= o.PayeeSimilarity || original >= o.OriginalPayeeSimilarity:
+		similarity = math.Max(payee, original)
+	default:
+		return 0, false
+	}
+
+	// NOTE: the score drops as the duplicates get further apart in date and
+	// amount, and as their payees become less similar.
+	score := similarity
+	score -= 0.3 * float64(days) / float64(o.MaxDays+1)
+	score -= 0.2 * diff / math.Max(o.AmountTolerance, 0.005)
+	return math.Max(0, score), true
+}
+
+// BestCopy returns the index of the transaction in the given group that is
+// the best categorised; the one to keep when resolving the group. Categorised
+// transactions are preferred, then reviewed ones, then those with labels, then
+// notes, then original payees, then the oldest.
+func BestCopy(group DuplicateGroup) int {
+	best, bestScore := 0, -1
+	for i, t := range group.Transactions {
+		score := 0
+		if t.Category.ID != 0 {
+			score += 16
+		}
+		if !t.NeedsReview {
+			score += 8
+		}
+		if len(t.Labels) > 0 {
+			score += 4
+		}
+		if t.Note != "" {
+			score += 2
+		}
+		if t.OriginalPayee != "" {
+			score++
+		}
+		if score > bestScore ||
+			(score == bestScore && t.ID < group.Transactions[best].ID) {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// DuplicateAPI defines the parts of the *pocketsmith.Client used to resolve
+// duplicates.
+type DuplicateAPI interface {
+	API
+	ListTransactionAttachments(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionAttachmentsOptions,
+	) (pocketsmith.Attachments, error)
+	AssignAttachmentToTransaction(
+		ctx context.Context,
+		options *pocketsmith.AssignAttachmentToTransactionOptions,
+	) (*pocketsmith.Attachment, error)
+	DeleteTransaction(ctx context.Context, options *pocketsmith.DeleteTransactionOptions) error
+}
+
+// ResolveOptions defines the options for resolving duplicates.
+type ResolveOptions struct {
+
+	// MinConfidence is the minimum confidence a group must have to be
+	// resolved; groups below it are skipped. Defaults to
+	// DefaultMinConfidence; set it below 0 to resolve every group.
+	MinConfidence float64
+}
+
+// DefaultMinConfidence is the MinConfidence used when none is given; only
+// groups this likely to be duplicates are resolved, as resolving deletes
+// transactions.
+const DefaultMinConfidence = 0.9
+
+// ResolveResult defines the outcome of resolving a duplicate group.
+type ResolveResult struct {
+	Group   DuplicateGroup
+	Kept    pocketsmith.Transaction // The transaction that was kept.
+	Deleted []int32                 // The ids of the transactions that were deleted.
+	Skipped bool                    // If the group was skipped, as it was below the minimum confidence.
+	Err     error                   // The error that stopped the group being resolved, if any.
+}
+
+// ResolveDuplicates resolves each of the given groups; keeping the best
+// categorised copy (see BestCopy), moving the labels, notes and attachments of
+// the other copies onto it, then deleting the other copies. A group that fails
+// to be resolved doesn't stop the others; its error is recorded in its result.
+func ResolveDuplicates(
+	ctx context.Context,
+	api DuplicateAPI,
+	groups []DuplicateGroup,
+	options *ResolveOptions,
+) (results []ResolveResult) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "ResolveDuplicates")
+	defer span.End()
+
+	o := ResolveOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.MinConfidence == 0 {
+		o.MinConfidence = DefaultMinConfidence
+	}
+	failed := 0
+	for _, g := range groups {
+		r := ResolveResult{Group: g}
+		if g.Confidence < o.MinConfidence || len(g.Transactions) < 2 {
+			r.Skipped = true
+			results = append(results, r)
+			continue
+		}
+		r.Kept, r.Deleted, r.Err = resolve(newCtx, api, g)
+		if r.Err != nil {
+			failed++
+		}
+		results = append(results, r)
+	}
+	span.SetAttributes(attribute.Int("groups", len(groups)), attribute.Int("failed", failed))
+	if failed > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to resolve %v groups", failed))
+	}
+	return results
+}
+
+// resolve resolves the given group.
+func resolve(
+	ctx context.Context,
+	api DuplicateAPI,
+	g DuplicateGroup,
+) (kept pocketsmith.Transaction, deleted []int32, err error) {
+	best := BestCopy(g)
+	kept = g.Transactions[best]
+	var others pocketsmith.Transactions
+	for i, t := range g.Transactions {
+		if i != best {
+			others = append(others, t)
+		}
+	}
+
+	// merge labels, notes & category onto the kept copy.
+	labels := append([]string(nil), kept.Labels...)
+	notes := []string{}
+	if kept.Note != "" {
+		notes = append(notes, kept.Note)
+	}
+	category := kept.Category.ID
+	for _, t := range others {
+		for _, l := range t.Labels {
+			if !containsFold(labels, l) {
+				labels = append(labels, l)
+			}
+		}
+		if t.Note != "" && !containsFold(notes, t.Note) {
+			notes = append(notes, t.Note)
+		}
+		if category == 0 {
+			category = t.Category.ID
+		}
+	}
+	update := &pocketsmith.UpdateTransactionOptions{TransactionID: kept.ID}
+	changed := false
+	if strings.Join(labels, ",") != strings.Join(kept.Labels, ",") {
+		update.Labels, changed = strings.Join(labels, ","), true
+	}
+	if note := strings.Join(notes, " | "); note != kept.Note {
+		update.Note, changed = note, true
+	}
+	if category != kept.Category.ID {
+		update.CategoryID, changed = category, true
+	}
+	if changed {
+		t, err := api.UpdateTransaction(ctx, update)
+		if err != nil {
+			return kept, nil, err
+		}
+		if t != nil {
+			kept = *t
+		}
+	}
+
+	// move attachments onto the kept copy.
+	existing, err := api.ListTransactionAttachments(
+		ctx,
+		&pocketsmith.ListTransactionAttachmentsOptions{TransactionID: kept.ID},
+	)
+	if err != nil {
+		return kept, nil, err
+	}
+	assigned := make(map[int]bool, len(existing))
+	for _, a := range existing {
+		assigned[a.ID] = true
+	}
+	for _, t := range others {
+		attachments, err := api.ListTransactionAttachments(
+			ctx,
+			&pocketsmith.ListTransactionAttachmentsOptions{TransactionID: t.ID},
+		)
+		if err != nil {
+			return kept, deleted, err
+		}
+		for _, a := range attachments {
+			if assigned[a.ID] {
+				continue
+			}
+			_, err := api.AssignAttachmentToTransaction(
+				ctx,
+				&pocketsmith.AssignAttachmentToTransactionOptions{
+					TransactionID: kept.ID,
+					AttachmentID:  a.ID,
+				},
+			)
+			if err != nil {
+				return kept, deleted, err
+			}
+			assigned[a.ID] = true
+		}
+
+		// delete the other copy.
+		err = api.DeleteTransaction(ctx, &pocketsmith.DeleteTransactionOptions{TransactionID: t.ID})
+		if err != nil {
+			return kept, deleted, err
+		}
+		deleted = append(deleted, t.ID)
+	}
+	return kept, deleted, nil
+}
+
+// containsFold returns if the given values contain the given value, ignoring
+// case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
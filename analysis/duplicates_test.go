@@ -0,0 +1,163 @@
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_FindDuplicates(t *testing.T) {
+	transactions := pocketsmith.Transactions{
+		{ID: 1, Date: "2024-01-01", Amount: -10, Payee: "Coffee Co", OriginalPayee: "COFFEE CO 1234", TransactionAccount: account(1, "aud")},
+		{ID: 2, Date: "2024-01-02", Amount: -10, Payee: "COFFEE CO.", TransactionAccount: account(1, "aud")},
+		{ID: 3, Date: "2024-01-03", Amount: -10, Payee: "Bakery", OriginalPayee: "COFFEE CO", TransactionAccount: account(1, "aud")},
+		{ID: 4, Date: "2024-01-01", Amount: -10, Payee: "Coffee Co", TransactionAccount: account(2, "aud")}, // different account.
+		{ID: 5, Date: "2024-01-01", Amount: -11, Payee: "Coffee Co", TransactionAccount: account(1, "aud")}, // different amount.
+		{ID: 6, Date: "2024-01-10", Amount: -10, Payee: "Coffee Co", TransactionAccount: account(1, "aud")}, // too far apart.
+		{ID: 7, Date: "2024-01-03", Amount: -10, Payee: "Coffee Co", TransactionAccount: account(1, "aud")},
+	}
+	tests := map[string]struct {
+		options *DuplicateOptions
+		want    [][]int32
+	}{
+		"same day only": {},
+		"within days, grouping duplicates of duplicates": {
+			options: &DuplicateOptions{MaxDays: 1},
+			want:    [][]int32{{1, 2, 7}},
+		},
+		"matching on original payee": {
+			options: &DuplicateOptions{MaxDays: 2},
+			want:    [][]int32{{1, 2, 3, 7}},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			var got [][]int32
+			for _, g := range FindDuplicates(transactions, tt.options) {
+				var ids []int32
+				for _, t := range g.Transactions {
+					ids = append(ids, t.ID)
+				}
+				got = append(got, ids)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindDuplicates() returned unexpected groups;\nwant=%v\ngot=%v\n", tt.want, got)
+			}
+		})
+	}
+}
+
+// mockDuplicateAPI is a mock implementation of the DuplicateAPI interface.
+type mockDuplicateAPI struct {
+	mockAPI
+	attachments map[int32]pocketsmith.Attachments
+	assigned    []*pocketsmith.AssignAttachmentToTransactionOptions
+	deleted     []int32
+}
+
+func (m *mockDuplicateAPI) ListTransactionAttachments(
+	_ context.Context,
+	options *pocketsmith.ListTransactionAttachmentsOptions,
+) (pocketsmith.Attachments, error) {
+	return m.attachments[options.TransactionID], nil
+}
+
+func (m *mockDuplicateAPI) AssignAttachmentToTransaction(
+	_ context.Context,
+	options *pocketsmith.AssignAttachmentToTransactionOptions,
+) (*pocketsmith.Attachment, error) {
+	m.assigned = append(m.assigned, options)
+	return &pocketsmith.Attachment{ID: options.AttachmentID}, nil
+}
+
+func (m *mockDuplicateAPI) DeleteTransaction(
+	_ context.Context,
+	options *pocketsmith.DeleteTransactionOptions,
+) error {
+	m.deleted = append(m.deleted, options.TransactionID)
+	return nil
+}
+
+func Test_ResolveDuplicates(t *testing.T) {
+	api := &mockDuplicateAPI{
+		attachments: map[int32]pocketsmith.Attachments{
+			1: {{ID: 10}, {ID: 11}},
+			2: {{ID: 11}},
+		},
+	}
+	group := DuplicateGroup{
+		Confidence: 0.9,
+		Transactions: pocketsmith.Transactions{
+			{ID: 1, Labels: []string{"a"}, Note: "first", NeedsReview: true},
+			{ID: 2, Labels: []string{"b"}, Category: pocketsmith.Category{ID: 5}},
+		},
+	}
+	unlikely := DuplicateGroup{
+		Confidence:   0.6,
+		Transactions: pocketsmith.Transactions{{ID: 3}, {ID: 4}},
+	}
+	results := ResolveDuplicates(context.Background(), api, []DuplicateGroup{group, unlikely}, nil)
+	if len(results) != 2 || results[0].Err != nil {
+		t.Fatalf("ResolveDuplicates() returned unexpected results; got=%+v", results)
+	}
+	if !results[1].Skipped {
+		t.Errorf("ResolveDuplicates() resolved a group below the default minimum confidence; got=%+v", results[1])
+	}
+
+	// the categorised copy is kept, taking the labels, notes & attachments of
+	// the other copy.
+	want := []*pocketsmith.UpdateTransactionOptions{
+		{TransactionID: 2, Labels: "b,a", Note: "first"},
+	}
+	if !reflect.DeepEqual(api.updated, want) {
+		t.Errorf("ResolveDuplicates() sent unexpected updates;\nwant=%+v\ngot=%+v\n", want, api.updated)
+	}
+	wantAssigned := []*pocketsmith.AssignAttachmentToTransactionOptions{
+		{TransactionID: 2, AttachmentID: 10},
+	}
+	if !reflect.DeepEqual(api.assigned, wantAssigned) {
+		t.Errorf("ResolveDuplicates() assigned unexpected attachments; got=%+v", api.assigned)
+	}
+	if !reflect.DeepEqual(api.deleted, []int32{1}) {
+		t.Errorf("ResolveDuplicates() deleted unexpected transactions; got=%v", api.deleted)
+	}
+}
+
+func Test_BestCopy(t *testing.T) {
+	tests := map[string]struct {
+		transactions pocketsmith.Transactions
+		want         int
+	}{
+		"categorised": {
+			transactions: pocketsmith.Transactions{
+				{ID: 1, Labels: []string{"a"}, Note: "b", OriginalPayee: "SQ *COFFEE"},
+				{ID: 2, Category: pocketsmith.Category{ID: 5}},
+			},
+			want: 1,
+		},
+		"original payee": {
+			transactions: pocketsmith.Transactions{
+				{ID: 1, Payee: "Coffee"},
+				{ID: 2, Payee: "Coffee", OriginalPayee: "SQ *COFFEE"},
+			},
+			want: 1,
+		},
+		"oldest": {
+			transactions: pocketsmith.Transactions{{ID: 2}, {ID: 1}},
+			want:         1,
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := BestCopy(DuplicateGroup{Transactions: tt.transactions}); got != tt.want {
+				t.Errorf("BestCopy() returned an unexpected copy; want=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
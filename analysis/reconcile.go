@@ -0,0 +1,187 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// ReconcileOptions defines the options for reconciling balances.
+type ReconcileOptions struct {
+
+	// Tolerance is the maximum absolute difference between a rebuilt balance
+	// and a balance from the API for them to be considered equal. Defaults to
+	// 0.005.
+	Tolerance float64
+}
+
+// Divergence defines a date where the rebuilt balance of a transaction
+// account no longer matches the closing balance from the API.
+type Divergence struct {
+	Date     string  `json:"date"`
+	Expected float64 `json:"expected"` // The balance rebuilt from the starting balance & transactions.
+	Actual   float64 `json:"actual"`   // The closing balance from the API.
+	Gap      float64 `json:"gap"`      // Actual - Expected.
+}
+
+// Reconciliation defines the outcome of reconciling a transaction account.
+type Reconciliation struct {
+	TransactionAccount pocketsmith.TransactionAccount `json:"transaction_account"`
+
+	// Transactions is the number of transactions used to rebuild the balance.
+	// Transactions dated before the starting balance date aren't used.
+	Transactions int `json:"transactions"`
+
+	// Calculated is the balance rebuilt from the starting balance and every
+	// transaction.
+	Calculated float64 `json:"calculated"`
+
+	// CurrentGap is TransactionAccount.CurrentBalance - Calculated.
+	CurrentGap float64 `json:"current_gap"`
+
+	// FirstDivergence is the first date where the rebuilt balance doesn't
+	// match the closing balance, if any.
+	FirstDivergence *Divergence `json:"first_divergence,omitempty"`
+
+	// Divergences are every date where the gap between the rebuilt balance
+	// and the closing balance changes. Each one usually points to a missing
+	// or doubled transaction on, or just before, that date.
+	Divergences []Divergence `json:"divergences,omitempty"`
+}
+
+// Reconciled returns if the rebuilt balance matches both the closing balances
+// and the current balance.
+func (r Reconciliation) Reconciled() bool {
+	return r.FirstDivergence == nil && r.CurrentGap == 0
+}
+
+// String returns the reconciliation as a single line.
+func (r Reconciliation) String() string {
+	name := r.TransactionAccount.Name
+	if r.Reconciled() {
+		return fmt.Sprintf("%s: reconciled at %.2f", name, r.Calculated)
+	}
+	out := fmt.Sprintf(
+		"%s: calculated %.2f, current %.2f (gap %.2f)",
+		name,
+		r.Calculated,
+		r.TransactionAccount.CurrentBalance,
+		r.CurrentGap,
+	)
+	if d := r.FirstDivergence; d != nil {
+		out += fmt.Sprintf(
+			"; first diverges on %s, expected %.2f, closing %.2f (gap %.2f)",
+			d.Date,
+			d.Expected,
+			d.Actual,
+			d.Gap,
+		)
+	}
+	return out
+}
+
+// Reconcile rebuilds the running balance of each of the given transaction
+// accounts, from its starting balance, starting balance date and the given
+// transactions, then compares it against the closing balance of each
+// transaction and the current balance of the transaction account.
+//
+// Since the order of transactions within a day isn't known, balances are
+// compared at the end of each day; a day matches if any of its transactions
+// has a closing balance equal to the rebuilt end of day balance. Pending
+// transactions are included in the balance, but their closing balances are
+// not compared.
+func Reconcile(
+	accounts pocketsmith.TransactionAccounts,
+	transactions pocketsmith.Transactions,
+	options *ReconcileOptions,
+) (reconciliations []Reconciliation) {
+
+	// default options.
+	tolerance := 0.005
+	if options != nil && options.Tolerance > 0 {
+		tolerance = options.Tolerance
+	}
+
+	// group transactions by transaction account.
+	byAccount := make(map[int]pocketsmith.Transactions)
+	for _, t := range transactions {
+		byAccount[t.TransactionAccount.ID] = append(byAccount[t.TransactionAccount.ID], t)
+	}
+
+	for _, ta := range accounts {
+		r := Reconciliation{TransactionAccount: ta}
+
+		// group transactions by date.
+		days := make(map[string]pocketsmith.Transactions)
+		var dates []string
+		for _, t := range byAccount[ta.ID] {
+			if ta.StartingBalanceDate != "" && t.Date < ta.StartingBalanceDate {
+				continue
+			}
+			if _, ok := days[t.Date]; !ok {
+				dates = append(dates, t.Date)
+			}
+			days[t.Date] = append(days[t.Date], t)
+			r.Transactions++
+		}
+		sort.Strings(dates)
+
+		// rebuild the running balance, one day at a time.
+		balance := ta.StartingBalance
+		gap := 0.0
+		for _, date := range dates {
+			var closing []float64
+			for _, t := range days[date] {
+				balance += t.Amount
+				if t.Status != "pending" {
+					closing = append(closing, t.ClosingBalance)
+				}
+			}
+			balance = round(balance)
+			if len(closing) == 0 {
+				continue
+			}
+
+			// does any closing balance match the end of day balance?
+			actual, matched := closing[0], false
+			for _, c := range closing {
+				if math.Abs(c-balance) <= tolerance {
+					matched = true
+					break
+				}
+				if math.Abs(c-balance) < math.Abs(actual-balance) {
+					actual = c
+				}
+			}
+			dayGap := 0.0
+			if !matched {
+				dayGap = round(actual - balance)
+			}
+			if math.Abs(dayGap-gap) > tolerance {
+				d := Divergence{Date: date, Expected: balance, Actual: actual, Gap: dayGap}
+				if r.FirstDivergence == nil && dayGap != 0 {
+					first := d
+					r.FirstDivergence = &first
+				}
+				r.Divergences = append(r.Divergences, d)
+				gap = dayGap
+			}
+		}
+
+		// compare against the current balance.
+		r.Calculated = balance
+		if diff := round(ta.CurrentBalance - balance); math.Abs(diff) > tolerance {
+			r.CurrentGap = diff
+		}
+		reconciliations = append(reconciliations, r)
+	}
+	return reconciliations
+}
+
+// round rounds the given amount to cents, to avoid floating point drift when
+// summing many amounts.
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,91 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_Reconcile(t *testing.T) {
+	ta := pocketsmith.TransactionAccount{
+		ID:                  1,
+		Name:                "Everyday",
+		StartingBalance:     100,
+		StartingBalanceDate: "2024-01-01",
+	}
+	tests := map[string]struct {
+		current      float64
+		transactions pocketsmith.Transactions
+		want         Reconciliation
+	}{
+		"reconciled": {
+			current: 70,
+			transactions: pocketsmith.Transactions{
+				{ID: 1, Date: "2023-12-31", Amount: -1000}, // before the starting balance date.
+				{ID: 2, Date: "2024-01-01", Amount: -10, ClosingBalance: 90},
+				{ID: 3, Date: "2024-01-02", Amount: -5, ClosingBalance: 80}, // same day; order unknown.
+				{ID: 4, Date: "2024-01-02", Amount: -15, ClosingBalance: 70},
+			},
+			want: Reconciliation{Transactions: 3, Calculated: 70},
+		},
+		"missing transaction": {
+			current: 60,
+			transactions: pocketsmith.Transactions{
+				{ID: 2, Date: "2024-01-01", Amount: -10, ClosingBalance: 90},
+				// a -10 transaction is missing here, on 2024-01-02.
+				{ID: 4, Date: "2024-01-03", Amount: -20, ClosingBalance: 60},
+				{ID: 5, Date: "2024-01-04", Amount: 0.1, ClosingBalance: 60.1, Status: "pending"},
+			},
+			want: Reconciliation{
+				Transactions:    3,
+				Calculated:      70.1,
+				CurrentGap:      -10.1,
+				FirstDivergence: &Divergence{Date: "2024-01-03", Expected: 70, Actual: 60, Gap: -10},
+				Divergences: []Divergence{
+					{Date: "2024-01-03", Expected: 70, Actual: 60, Gap: -10},
+				},
+			},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			account := ta
+			account.CurrentBalance = tt.current
+			for i := range tt.transactions {
+				tt.transactions[i].TransactionAccount = account
+			}
+			got := Reconcile(pocketsmith.TransactionAccounts{account}, tt.transactions, nil)
+			tt.want.TransactionAccount = account
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("Reconcile() returned unexpected results;\nwant=%+v\ngot=%+v\n", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_Reconcile_startingBalanceFromStatement(t *testing.T) {
+	account := pocketsmith.TransactionAccount{ID: 1, StartingBalanceDate: "2024-01-01", CurrentBalance: 70}
+	transactions := pocketsmith.Transactions{
+		{ID: 1, Date: "2024-01-01", Amount: -10, ClosingBalance: 90},
+		{ID: 2, Date: "2024-01-02", Amount: -20, ClosingBalance: 70},
+	}
+	for i := range transactions {
+		transactions[i].TransactionAccount = account
+	}
+
+	// fix the starting balance from a statement, then reconcile with it.
+	balance, err := pocketsmith.StartingBalanceFromStatement(&account, transactions, "2024-01-02", 70)
+	if err != nil {
+		t.Fatalf("StartingBalanceFromStatement() returned an error: %v", err)
+	}
+	account.StartingBalance = balance
+
+	// run tests.
+	got := Reconcile(pocketsmith.TransactionAccounts{account}, transactions, nil)
+	if len(got) != 1 || !got[0].Reconciled() {
+		t.Errorf("Reconcile() diverged from the fixed starting balance %v; got=%+v", balance, got)
+	}
+}
@@ -0,0 +1,297 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/internal/fuzzy"
+)
+
+// Frequency defines how often a recurring payment happens.
+type Frequency string
+
+// The frequencies a recurring payment can have.
+const (
+	FrequencyWeekly      Frequency = "weekly"
+	FrequencyFortnightly Frequency = "fortnightly"
+	FrequencyMonthly     Frequency = "monthly"
+	FrequencyAnnual      Frequency = "annual"
+)
+
+// frequencies defines the range of days between payments for each frequency,
+// and the number of days a payment can be late before it's considered
+// stopped.
+var frequencies = []struct {
+	frequency Frequency
+	min, max  int
+	grace     int
+}{
+	{FrequencyWeekly, 6, 8, 4},
+	{FrequencyFortnightly, 12, 16, 7},
+	{FrequencyMonthly, 27, 34, 15},
+	{FrequencyAnnual, 350, 380, 31},
+}
+
+// RecurringOptions defines the options for finding recurring payments.
+type RecurringOptions struct {
+
+	// AmountTolerance is the maximum relative difference between the amounts
+	// of a recurring payment, eg. 0.1 allows amounts within 10%. Defaults to
+	// 0.1.
+	AmountTolerance float64
+
+	// MinOccurrences is the minimum number of payments before they are
+	// considered recurring. Defaults to 3.
+	MinOccurrences int
+
+	// MinConfidence is the minimum fraction (from 0 to 1) of the gaps between
+	// payments that must match the frequency. Defaults to 0.75.
+	MinConfidence float64
+
+	// Today, in the format "2006-01-02", is used to find recurring payments
+	// that have stopped. Defaults to the current date.
+	Today string
+
+	// IncludeTransfers includes transfers, which are skipped by default.
+	IncludeTransfers bool
+}
+
+// PriceChange defines a change in the amount of a recurring payment.
+type PriceChange struct {
+	Date string  `json:"date"` // The date of the first payment at the new amount.
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// Recurring defines a payment that recurs, such as a subscription or a bill.
+type Recurring struct {
+	Payee        string                   `json:"payee"` // The payee of the latest payment.
+	Frequency    Frequency                `json:"frequency"`
+	Transactions pocketsmith.Transactions `json:"transactions"` // Ordered by date.
+
+	Amount        float64 `json:"amount"` // The amount of the latest payment.
+	AverageAmount float64 `json:"average_amount"`
+
+	LastDate string `json:"last_date"`
+	NextDate string `json:"next_date"` // When the next payment is expected.
+
+	// Stopped is set when the next payment is overdue, which usually means
+	// the subscription was cancelled.
+	Stopped bool `json:"stopped"`
+
+	// PriceChange is the latest change in the amount, if any.
+	PriceChange *PriceChange `json:"price_change,omitempty"`
+
+	// Confidence is the fraction of the gaps between payments that match the
+	// frequency, from 0 to 1.
+	Confidence float64 `json:"confidence"`
+}
+
+// String returns the recurring payment as a single line.
+func (r Recurring) String() string {
+	out := fmt.Sprintf(
+		"%s: %s %.2f (average %.2f), last %s, next %s",
+		r.Payee,
+		r.Frequency,
+		r.Amount,
+		r.AverageAmount,
+		r.LastDate,
+		r.NextDate,
+	)
+	if c := r.PriceChange; c != nil {
+		out += fmt.Sprintf(", changed from %.2f to %.2f on %s", c.From, c.To, c.Date)
+	}
+	if r.Stopped {
+		out += ", stopped"
+	}
+	return out
+}
+
+// EventSuggestion defines a PocketSmith event that matches a recurring
+// payment, so it can be included in forecasts. The fields match the body of
+// the "create event in scenario" endpoint.
+type EventSuggestion struct {
+	CategoryID     int32   `json:"category_id,omitempty"`
+	Amount         float64 `json:"amount"`
+	Date           string  `json:"date"`
+	RepeatType     string  `json:"repeat_type"`
+	RepeatInterval int     `json:"repeat_interval"`
+	Note           string  `json:"note"`
+}
+
+// Event returns a suggested PocketSmith event for the recurring payment,
+// starting at the next expected date.
+func (r Recurring) Event() EventSuggestion {
+	e := EventSuggestion{
+		CategoryID:     r.Transactions[len(r.Transactions)-1].Category.ID,
+		Amount:         r.Amount,
+		Date:           r.NextDate,
+		RepeatInterval: 1,
+		Note:           r.Payee,
+	}
+	switch r.Frequency {
+	case FrequencyWeekly:
+		e.RepeatType = "weekly"
+	case FrequencyFortnightly:
+		e.RepeatType = "weekly"
+		e.RepeatInterval = 2
+	case FrequencyMonthly:
+		e.RepeatType = "monthly"
+	case FrequencyAnnual:
+		e.RepeatType = "yearly"
+	}
+	return e
+}
+
+// FindRecurring returns the recurring payments in the given transactions.
+// Transactions are clustered by their normalised payee, and by whether they
+// are debits or credits, then each cluster is checked for a regular gap
+// between payments and a stable amount.
+func FindRecurring(
+	transactions pocketsmith.Transactions,
+	options *RecurringOptions,
+) (recurring []Recurring) {
+
+	// default options.
+	o := RecurringOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.AmountTolerance == 0 {
+		o.AmountTolerance = 0.1
+	}
+	if o.MinOccurrences == 0 {
+		o.MinOccurrences = 3
+	}
+	if o.MinConfidence == 0 {
+		o.MinConfidence = 0.75
+	}
+	if o.Today == "" {
+		o.Today = time.Now().Format(dateFormat)
+	}
+
+	// cluster transactions.
+	clusters := make(map[string]pocketsmith.Transactions)
+	var keys []string
+	for _, t := range transactions {
+		if !o.IncludeTransfers && (t.IsTransfer || t.Category.IsTransfer) {
+			continue
+		}
+		payee := t.Payee
+		if payee == "" {
+			payee = t.OriginalPayee
+		}
+		key := fuzzy.Normalise(payee)
+		if key == "" || t.Amount == 0 {
+			continue
+		}
+		if t.Amount > 0 {
+			key = "+" + key
+		}
+		if _, ok := clusters[key]; !ok {
+			keys = append(keys, key)
+		}
+		clusters[key] = append(clusters[key], t)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if r, ok := o.recurring(clusters[key]); ok {
+			recurring = append(recurring, r)
+		}
+	}
+	return recurring
+}
+
+// recurring returns the recurring payment for the given cluster, if the
+// payments in it recur.
+func (o *RecurringOptions) recurring(ts pocketsmith.Transactions) (Recurring, bool) {
+	if len(ts) < o.MinOccurrences {
+		return Recurring{}, false
+	}
+	sort.SliceStable(ts, func(i, j int) bool { return ts[i].Date < ts[j].Date })
+
+	// find the frequency that best matches the gaps between payments.
+	var gaps []int
+	for i := 1; i < len(ts); i++ {
+		gaps = append(gaps, daysBetween(ts[i-1].Date, ts[i].Date))
+	}
+	best, confidence := -1, 0.0
+	for i, f := range frequencies {
+		matched := 0
+		for _, gap := range gaps {
+			if gap >= f.min && gap <= f.max {
+				matched++
+			}
+		}
+		if c := float64(matched) / float64(len(gaps)); c > confidence {
+			best, confidence = i, c
+		}
+	}
+	if best < 0 || confidence < o.MinConfidence {
+		return Recurring{}, false
+	}
+
+	// check the amounts are stable; a change in price is allowed, as long as
+	// it sticks, but amounts that change all the time aren't recurring.
+	var changes []PriceChange
+	sum := 0.0
+	for i, t := range ts {
+		sum += t.Amount
+		if i == 0 {
+			continue
+		}
+		prev := ts[i-1].Amount
+		if math.Abs(t.Amount-prev) > o.AmountTolerance*math.Abs(prev) {
+			changes = append(changes, PriceChange{Date: t.Date, From: prev, To: t.Amount})
+		}
+	}
+	if len(changes) > max(1, len(ts)/4) {
+		return Recurring{}, false
+	}
+
+	f := frequencies[best]
+	last := ts[len(ts)-1]
+	r := Recurring{
+		Payee:         last.Payee,
+		Frequency:     f.frequency,
+		Transactions:  ts,
+		Amount:        last.Amount,
+		AverageAmount: math.Round(sum/float64(len(ts))*100) / 100,
+		LastDate:      last.Date,
+		NextDate:      nextDate(last.Date, f.frequency),
+		Confidence:    math.Round(confidence*100) / 100,
+	}
+	if r.Payee == "" {
+		r.Payee = last.OriginalPayee
+	}
+	if len(changes) > 0 {
+		r.PriceChange = &changes[len(changes)-1]
+	}
+	if overdue, ok := parseDate(r.NextDate); ok {
+		r.Stopped = overdue.AddDate(0, 0, f.grace).Format(dateFormat) < o.Today
+	}
+	return r, true
+}
+
+// nextDate returns the date after the given date, at the given frequency.
+func nextDate(date string, frequency Frequency) string {
+	d, ok := parseDate(date)
+	if !ok {
+		return ""
+	}
+	switch frequency {
+	case FrequencyWeekly:
+		d = d.AddDate(0, 0, 7)
+	case FrequencyFortnightly:
+		d = d.AddDate(0, 0, 14)
+	case FrequencyMonthly:
+		d = d.AddDate(0, 1, 0)
+	case FrequencyAnnual:
+		d = d.AddDate(1, 0, 0)
+	}
+	return d.Format(dateFormat)
+}
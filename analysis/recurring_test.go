@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_FindRecurring(t *testing.T) {
+	transactions := pocketsmith.Transactions{
+
+		// a monthly subscription that changed price.
+		{ID: 1, Date: "2024-01-05", Amount: -15.99, Payee: "NETFLIX.COM 1234"},
+		{ID: 2, Date: "2024-02-05", Amount: -15.99, Payee: "Netflix.com 5678"},
+		{ID: 3, Date: "2024-03-05", Amount: -15.99, Payee: "NETFLIX.COM"},
+		{ID: 4, Date: "2024-04-05", Amount: -18.99, Payee: "NETFLIX.COM", Category: pocketsmith.Category{ID: 7}},
+
+		// a weekly payment that stopped.
+		{ID: 5, Date: "2024-01-01", Amount: -10, Payee: "Gym"},
+		{ID: 6, Date: "2024-01-08", Amount: -10, Payee: "Gym"},
+		{ID: 7, Date: "2024-01-15", Amount: -10, Payee: "Gym"},
+
+		// irregular payments.
+		{ID: 8, Date: "2024-01-01", Amount: -5, Payee: "Cafe"},
+		{ID: 9, Date: "2024-01-03", Amount: -5, Payee: "Cafe"},
+		{ID: 10, Date: "2024-02-20", Amount: -5, Payee: "Cafe"},
+
+		// amounts that change all the time.
+		{ID: 11, Date: "2024-01-10", Amount: -50, Payee: "Power"},
+		{ID: 12, Date: "2024-02-10", Amount: -80, Payee: "Power"},
+		{ID: 13, Date: "2024-03-10", Amount: -40, Payee: "Power"},
+	}
+	got := FindRecurring(transactions, &RecurringOptions{Today: "2024-04-20"})
+	if len(got) != 2 {
+		t.Fatalf("FindRecurring() returned unexpected results; got=%v", got)
+	}
+
+	gym := got[0]
+	if gym.Payee != "Gym" || gym.Frequency != FrequencyWeekly || !gym.Stopped || gym.NextDate != "2024-01-22" {
+		t.Errorf("FindRecurring() returned an unexpected gym payment; got=%v", gym)
+	}
+
+	netflix := got[1]
+	want := Recurring{
+		Payee:         "NETFLIX.COM",
+		Frequency:     FrequencyMonthly,
+		Transactions:  transactions[:4],
+		Amount:        -18.99,
+		AverageAmount: -16.74,
+		LastDate:      "2024-04-05",
+		NextDate:      "2024-05-05",
+		PriceChange:   &PriceChange{Date: "2024-04-05", From: -15.99, To: -18.99},
+		Confidence:    1,
+	}
+	if !reflect.DeepEqual(netflix, want) {
+		t.Errorf("FindRecurring() returned an unexpected netflix payment;\nwant=%v\ngot=%v", want, netflix)
+	}
+	wantEvent := EventSuggestion{
+		CategoryID:     7,
+		Amount:         -18.99,
+		Date:           "2024-05-05",
+		RepeatType:     "monthly",
+		RepeatInterval: 1,
+		Note:           "NETFLIX.COM",
+	}
+	if e := netflix.Event(); e != wantEvent {
+		t.Errorf("Event() returned an unexpected event; want=%+v, got=%+v", wantEvent, e)
+	}
+}
@@ -0,0 +1,266 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// TransferOptions defines the options for finding transfers.
+type TransferOptions struct {
+
+	// MaxDays is the maximum number of days between both sides of a transfer.
+	// Defaults to 0 (same day only).
+	MaxDays int
+
+	// Tolerance is the maximum absolute difference between the amounts of
+	// both sides of a transfer, when they are in the same currency. Defaults
+	// to 0.005 (an exact match).
+	Tolerance float64
+
+	// ConvertCurrency allows transfers between transaction accounts in
+	// different currencies, by comparing Transaction.AmountInBaseCurrency.
+	ConvertCurrency bool
+
+	// CurrencyTolerance is the maximum relative difference (eg. 0.02 for 2%)
+	// between the base currency amounts of both sides of a transfer in
+	// different currencies, to allow for exchange rate spreads. Defaults to
+	// 0.02.
+	CurrencyTolerance float64
+
+	// IncludeMarked includes transactions that are already marked as
+	// transfers. By default they are skipped.
+	IncludeMarked bool
+}
+
+// TransferPair defines a likely transfer between two transaction accounts.
+type TransferPair struct {
+	From       pocketsmith.Transaction `json:"from"`       // The side leaving an account (negative amount).
+	To         pocketsmith.Transaction `json:"to"`         // The side arriving in an account (positive amount).
+	Days       int                     `json:"days"`       // The number of days between both sides.
+	Converted  bool                    `json:"converted"`  // If the sides are in different currencies.
+	Confidence float64                 `json:"confidence"` // How likely this is a transfer, from 0 to 1.
+}
+
+// String returns the pair as a single line.
+func (p TransferPair) String() string {
+	return fmt.Sprintf(
+		"%s %.2f %s (%s) → %s %.2f %s (%s), confidence %.2f",
+		p.From.Date,
+		p.From.Amount,
+		p.From.TransactionAccount.Name,
+		p.From.Payee,
+		p.To.Date,
+		p.To.Amount,
+		p.To.TransactionAccount.Name,
+		p.To.Payee,
+		p.Confidence,
+	)
+}
+
+// FindTransfers returns the likely transfer pairs in the given transactions;
+// transactions in different transaction accounts with opposite amounts,
+// within the given number of days of each other, whichever side was posted
+// first. Transactions with invalid dates are skipped. Each transaction is only
+// ever part of one pair; when a transaction could pair with several others,
+// the closest in date and amount is chosen.
+func FindTransfers(
+	transactions pocketsmith.Transactions,
+	options *TransferOptions,
+) (pairs []TransferPair) {
+
+	// default options.
+	o := TransferOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.Tolerance == 0 {
+		o.Tolerance = 0.005
+	}
+	if o.CurrencyTolerance == 0 {
+		o.CurrencyTolerance = 0.02
+	}
+
+	// split into debits & credits, parsing their dates once.
+	var debits, credits []transferSide
+	for _, t := range transactions {
+		if t.IsTransfer && !o.IncludeMarked {
+			continue
+		}
+		date, ok := parseDate(t.Date)
+		if !ok {
+			continue
+		}
+		side := transferSide{t, int(date.Unix() / (24 * 60 * 60))}
+		switch {
+		case t.Amount < 0:
+			debits = append(debits, side)
+		case t.Amount > 0:
+			credits = append(credits, side)
+		}
+	}
+
+	// find candidate pairs; the credits are sorted by date, so only those
+	// within MaxDays either side of each debit are compared.
+	sort.SliceStable(credits, func(i, j int) bool { return credits[i].day < credits[j].day })
+	var candidates []TransferPair
+	for _, d := range debits {
+		first := sort.Search(len(credits), func(i int) bool {
+			return credits[i].day >= d.day-o.MaxDays
+		})
+		for _, c := range credits[first:] {
+			if c.day > d.day+o.MaxDays {
+				break
+			}
+			if d.TransactionAccount.ID == c.TransactionAccount.ID {
+				continue
+			}
+			days := c.day - d.day
+			if days < 0 {
+				days = -days
+			}
+			p, ok := o.pair(d.Transaction, c.Transaction, days)
+			if ok {
+				candidates = append(candidates, p)
+			}
+		}
+	}
+
+	// pick the most confident pairs, using each transaction once.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].From.ID < candidates[j].From.ID
+	})
+	used := make(map[int32]bool)
+	for _, p := range candidates {
+		if used[p.From.ID] || used[p.To.ID] {
+			continue
+		}
+		used[p.From.ID], used[p.To.ID] = true, true
+		pairs = append(pairs, p)
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].From.Date < pairs[j].From.Date })
+	return pairs
+}
+
+// transferSide defines a transaction that could be one side of a transfer,
+// with its date as the number of days since the unix epoch.
+type transferSide struct {
+	pocketsmith.Transaction
+	day int
+}
+
+// pair returns the given debit & credit as a transfer pair, if their amounts
+// match.
+func (o *TransferOptions) pair(d, c pocketsmith.Transaction, days int) (TransferPair, bool) {
+	p := TransferPair{From: d, To: c, Days: days}
+	sameCurrency := strings.EqualFold(
+		d.TransactionAccount.CurrencyCode,
+		c.TransactionAccount.CurrencyCode,
+	)
+
+	// determine how far apart the amounts are, from 0 (exact) to 1 (at the
+	// tolerance).
+	var gap float64
+	switch {
+	case sameCurrency:
+		diff := math.Abs(d.Amount + c.Amount)
+		if diff > o.Tolerance {
+			return p, false
+		}
+		gap = diff / math.Max(o.Tolerance, 0.005)
+	case o.ConvertCurrency:
+		from, to := math.Abs(d.AmountInBaseCurrency), math.Abs(c.AmountInBaseCurrency)
+		if from == 0 || to == 0 {
+			return p, false
+		}
+		diff := math.Abs(from-to) / math.Max(from, to)
+		if diff > o.CurrencyTolerance {
+			return p, false
+		}
+		gap = diff / o.CurrencyTolerance
+		p.Converted = true
+	default:
+		return p, false
+	}
+
+	// NOTE: confidence drops as the sides get further apart in date and
+	// amount, and is capped lower for converted currencies.
+	confidence := 1 - 0.5*float64(days)/float64(o.MaxDays+1) - 0.25*math.Min(gap, 1)
+	if p.Converted {
+		confidence -= 0.1
+	}
+	if d.IsTransfer || c.IsTransfer || d.Category.IsTransfer || c.Category.IsTransfer {
+		confidence = math.Min(1, confidence+0.1)
+	}
+	p.Confidence = math.Round(confidence*100) / 100
+	return p, true
+}
+
+// MarkTransferOptions defines the options for marking transfer pairs.
+type MarkTransferOptions struct {
+	CategoryID int32 // The category assigned to both sides; optional.
+}
+
+// MarkTransferResult defines the outcome of marking a transfer pair.
+type MarkTransferResult struct {
+	Pair TransferPair
+	Err  error
+}
+
+// MarkTransfers marks both sides of each of the given pairs as a transfer,
+// through UpdateTransaction, optionally assigning a transfer category. A pair
+// that fails to be marked doesn't stop the others; its error is recorded in
+// its result.
+func MarkTransfers(
+	ctx context.Context,
+	api API,
+	pairs []TransferPair,
+	options *MarkTransferOptions,
+) (results []MarkTransferResult) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "MarkTransfers")
+	defer span.End()
+
+	var categoryID int32
+	if options != nil {
+		categoryID = options.CategoryID
+	}
+	failed := 0
+	for _, p := range pairs {
+		var err error
+		for _, t := range []pocketsmith.Transaction{p.From, p.To} {
+			if t.IsTransfer && (categoryID == 0 || t.Category.ID == categoryID) {
+				continue // already marked.
+			}
+			_, err = api.UpdateTransaction(newCtx, &pocketsmith.UpdateTransactionOptions{
+				TransactionID: t.ID,
+				IsTransfer:    true,
+				CategoryID:    categoryID,
+			})
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			failed++
+		}
+		results = append(results, MarkTransferResult{Pair: p, Err: err})
+	}
+	span.SetAttributes(attribute.Int("marked", len(pairs)-failed), attribute.Int("failed", failed))
+	if failed > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to mark %v transfers", failed))
+	}
+	return results
+}
@@ -0,0 +1,87 @@
+package analysis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// account returns a transaction account with the given id & currency.
+func account(id int, currency string) pocketsmith.TransactionAccount {
+	return pocketsmith.TransactionAccount{ID: id, CurrencyCode: currency}
+}
+
+func Test_FindTransfers(t *testing.T) {
+	transactions := pocketsmith.Transactions{
+		{ID: 1, Date: "2024-01-01", Amount: -100, TransactionAccount: account(1, "aud")},
+		{ID: 2, Date: "2024-01-02", Amount: 100, TransactionAccount: account(2, "aud")},
+		{ID: 3, Date: "2024-01-01", Amount: 100, TransactionAccount: account(3, "aud")}, // closer; wins over 2.
+		{ID: 4, Date: "2024-01-01", Amount: 100, TransactionAccount: account(1, "aud")}, // same account.
+		{ID: 5, Date: "2024-01-05", Amount: -50, AmountInBaseCurrency: -50, TransactionAccount: account(1, "aud")},
+		{ID: 6, Date: "2024-01-06", Amount: 33, AmountInBaseCurrency: 49.5, TransactionAccount: account(4, "usd")},
+		{ID: 7, Date: "2024-01-20", Amount: -20, TransactionAccount: account(1, "aud")}, // too far apart.
+		{ID: 8, Date: "2024-01-25", Amount: 20, TransactionAccount: account(2, "aud")},
+		{ID: 9, Date: "2024-02-02", Amount: -70, TransactionAccount: account(1, "aud")},
+		{ID: 10, Date: "2024-02-01", Amount: 70, TransactionAccount: account(2, "aud")},  // arrives a day early.
+		{ID: 11, Date: "2024-03-01", Amount: -30, TransactionAccount: account(3, "aud")}, // its credit has an invalid date.
+		{ID: 12, Date: "01/03/2024", Amount: 30, TransactionAccount: account(2, "aud")},
+	}
+	tests := map[string]struct {
+		options *TransferOptions
+		want    [][2]int32
+	}{
+		"same day only": {
+			want: [][2]int32{{1, 3}},
+		},
+		"within days": {
+			options: &TransferOptions{MaxDays: 2},
+			want:    [][2]int32{{1, 3}, {9, 10}},
+		},
+		"with currency conversion": {
+			options: &TransferOptions{MaxDays: 2, ConvertCurrency: true},
+			want:    [][2]int32{{1, 3}, {5, 6}, {9, 10}},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			var got [][2]int32
+			for _, p := range FindTransfers(transactions, tt.options) {
+				got = append(got, [2]int32{p.From.ID, p.To.ID})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindTransfers() returned unexpected pairs;\nwant=%v\ngot=%v\n", tt.want, got)
+			}
+		})
+	}
+}
+
+// mockAPI is a mock implementation of the API interface.
+type mockAPI struct {
+	updated []*pocketsmith.UpdateTransactionOptions
+}
+
+func (m *mockAPI) UpdateTransaction(
+	_ context.Context,
+	options *pocketsmith.UpdateTransactionOptions,
+) (*pocketsmith.Transaction, error) {
+	m.updated = append(m.updated, options)
+	return &pocketsmith.Transaction{ID: options.TransactionID}, nil
+}
+
+func Test_MarkTransfers(t *testing.T) {
+	api := &mockAPI{}
+	results := MarkTransfers(context.Background(), api, []TransferPair{
+		{From: pocketsmith.Transaction{ID: 1}, To: pocketsmith.Transaction{ID: 2, IsTransfer: true}},
+	}, nil)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("MarkTransfers() returned unexpected results; got=%+v", results)
+	}
+	want := []*pocketsmith.UpdateTransactionOptions{{TransactionID: 1, IsTransfer: true}}
+	if !reflect.DeepEqual(api.updated, want) {
+		t.Errorf("MarkTransfers() sent unexpected updates;\nwant=%+v\ngot=%+v\n", want, api.updated)
+	}
+}
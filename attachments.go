@@ -175,6 +175,46 @@ func (c *Client) ListAttachments(
 	)
 }
 
+// ListTransactionAttachmentsOptions defines the options for listing the
+// attachments assigned to a transaction.
+type ListTransactionAttachmentsOptions struct {
+	TransactionID int32 `json:"-" validator:"required"`
+}
+
+// ListTransactionAttachments lists the attachments assigned to a transaction.
+// https://developers.pocketsmith.com/reference/get_transactions-id-attachments-1.
+func (c *Client) ListTransactionAttachments(
+	ctx context.Context,
+	options *ListTransactionAttachmentsOptions,
+) (attachments Attachments, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListTransactionAttachments")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// list transaction attachments.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/transactions/%v/attachments", options.TransactionID),
+	}, &attachments)
+	if err != nil {
+		span.SetStatus(
+			codes.Error,
+			fmt.Sprintf("failed to list transaction attachments: %v", err),
+		)
+		span.RecordError(err)
+		return nil, err
+	}
+	return attachments, nil
+}
+
 // AssignAttachmentToTransactionOptions defines the options for assigning an
 // attachment to a transaction.
 type AssignAttachmentToTransactionOptions struct {
@@ -216,3 +256,77 @@ func (c *Client) AssignAttachmentToTransaction(
 	}
 	return attachment, nil
 }
+
+// GetAttachmentOptions defines the options for getting an attachment.
+type GetAttachmentOptions struct {
+	AttachmentID int `json:"-" validator:"required"`
+}
+
+// GetAttachment gets an attachment from Pocketsmith, by the attachment id.
+// https://developers.pocketsmith.com/reference/get_attachments-id-1.
+func (c *Client) GetAttachment(
+	ctx context.Context,
+	options *GetAttachmentOptions,
+) (attachment *Attachment, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "GetAttachment")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// get attachment.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/attachments/%v", options.AttachmentID),
+	}, &attachment)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to get attachment: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// UpdateAttachmentOptions defines the options for updating an attachment.
+type UpdateAttachmentOptions struct {
+	AttachmentID int    `json:"-"               validator:"required"`
+	Title        string `json:"title,omitempty"`
+}
+
+// UpdateAttachment updates an attachment in Pocketsmith, by the attachment id.
+// https://developers.pocketsmith.com/reference/put_attachments-id-1.
+func (c *Client) UpdateAttachment(
+	ctx context.Context,
+	options *UpdateAttachmentOptions,
+) (attachment *Attachment, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "UpdateAttachment")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// update attachment.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/attachments/%v", options.AttachmentID),
+		body:   options,
+	}, &attachment)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to update attachment: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return attachment, nil
+}
@@ -0,0 +1,123 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// The name of the archive JSON file inside a written archive.
+const archiveFile = "archive.json"
+
+// WriteArchive writes the given archive to w, as a gzipped tar holding the
+// archive as JSON, along with any downloaded attachment files.
+func WriteArchive(w io.Writer, archive *Archive) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	b, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return ErrArchiveFailedWrite{err}
+	}
+	write := func(name string, b []byte) error {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0o600,
+			Size:    int64(len(b)),
+			ModTime: archive.CreatedAt,
+		})
+		if err != nil {
+			return ErrArchiveFailedWrite{err}
+		}
+		if _, err := tw.Write(b); err != nil {
+			return ErrArchiveFailedWrite{err}
+		}
+		return nil
+	}
+	if err := write(archiveFile, b); err != nil {
+		return err
+	}
+	for _, a := range archive.Attachments {
+		if a.File == "" {
+			continue
+		}
+		if err := write(a.File, a.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return ErrArchiveFailedWrite{err}
+	}
+	if err := gw.Close(); err != nil {
+		return ErrArchiveFailedWrite{err}
+	}
+	return nil
+}
+
+// ReadArchive reads an archive written by WriteArchive from r.
+func ReadArchive(r io.Reader) (*Archive, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrArchiveFailedRead{err}
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	var archive *Archive
+	files := make(map[string][]byte)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrArchiveFailedRead{err}
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, ErrArchiveFailedRead{err}
+		}
+		if h.Name != archiveFile {
+			files[h.Name] = b
+			continue
+		}
+		archive = &Archive{}
+		if err := json.Unmarshal(b, archive); err != nil {
+			return nil, ErrArchiveFailedRead{err}
+		}
+	}
+	if archive == nil {
+		return nil, ErrArchiveFailedRead{errors.New("missing " + archiveFile)}
+	}
+	if archive.Version < 1 || archive.Version > Version {
+		return nil, ErrArchiveUnsupportedVersion{archive.Version}
+	}
+	for i := range archive.Attachments {
+		a := &archive.Attachments[i]
+		if a.File == "" {
+			continue
+		}
+		b, ok := files[a.File]
+		if !ok {
+			return nil, ErrArchiveFailedRead{errors.New("missing " + a.File)}
+		}
+		a.data = b
+	}
+	return archive, nil
+}
+
+// fileName returns a safe name for the file of the given attachment.
+func fileName(a pocketsmith.Attachment) string {
+	name := path.Base(strings.ReplaceAll(a.FileName, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		name = "file"
+		if ext := a.ContentTypeMeta.Extension; ext != "" {
+			name += "." + strings.TrimPrefix(ext, ".")
+		}
+	}
+	return name
+}
@@ -0,0 +1,300 @@
+// Package backup takes a full, offline copy of a PocketSmith user, and
+// restores it into another user.
+//
+// Backup lists everything the user has into an Archive; their settings,
+// institutions, accounts, transaction accounts, categories (with their
+// hierarchy), transactions and, optionally, their attachments. An Archive is
+// written to disk with WriteArchive, as a gzipped tar holding the archive as
+// JSON alongside any attachment files, and read back with ReadArchive.
+//
+// Restore recreates an Archive in another user, mapping the ids in the
+// archive to the ids of the entities it creates:
+//
+//	archive, err := backup.Backup(ctx, c, userID, &backup.Options{Files: true})
+//	...
+//	result, err := backup.Restore(ctx, c, archive, otherUserID, nil)
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// The name of the tracer output in the traces.
+const tracerName = "pocketsmith-go/backup"
+
+// Version is the version of the archives written by this package. It is
+// increased whenever the layout of an Archive changes, so that older archives
+// can still be read.
+const Version = 1
+
+// API defines the parts of the *pocketsmith.Client used by Backup.
+type API interface {
+	GetUser(ctx context.Context, options *pocketsmith.GetUserOptions) (*pocketsmith.User, error)
+	ListInstitutionsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListInstitutionsForUser,
+	) (pocketsmith.Institutions, error)
+	ListAccountsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListAccountsForUserOptions,
+	) (pocketsmith.Accounts, error)
+	ListTransactionAccountsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionAccountsForUserOptions,
+	) (pocketsmith.TransactionAccounts, error)
+	ListCategoriesForUser(
+		ctx context.Context,
+		options *pocketsmith.ListCategoriesForUserOptions,
+	) (pocketsmith.Categories, error)
+	ListTransactionsForUserPages(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionsForUserOptions,
+		fn func(pocketsmith.Transactions) error,
+	) error
+	ListAttachmentsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListAttachmentsForUserOptions,
+	) (pocketsmith.Attachments, error)
+	ListTransactionAttachments(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionAttachmentsOptions,
+	) (pocketsmith.Attachments, error)
+}
+
+// Archive defines a full copy of a PocketSmith user.
+type Archive struct {
+	Version             int                             `json:"version"`
+	CreatedAt           time.Time                       `json:"created_at"`
+	User                pocketsmith.User                `json:"user"`
+	Institutions        pocketsmith.Institutions        `json:"institutions"`
+	Accounts            pocketsmith.Accounts            `json:"accounts"`
+	TransactionAccounts pocketsmith.TransactionAccounts `json:"transaction_accounts"`
+	Categories          pocketsmith.Categories          `json:"categories"` // The category tree, as returned from the API.
+	Transactions        pocketsmith.Transactions        `json:"transactions"`
+	Attachments         []Attachment                    `json:"attachments"`
+}
+
+// Attachment defines an attachment in an Archive.
+type Attachment struct {
+	pocketsmith.Attachment
+
+	// TransactionIDs are the ids of the transactions the attachment is
+	// assigned to.
+	TransactionIDs []int32 `json:"transaction_ids"`
+
+	// File is the path of the attachment file in the archive, if it was
+	// downloaded.
+	File string `json:"file,omitempty"`
+
+	// data is the content of the attachment file, if it was downloaded.
+	data []byte
+}
+
+// Data returns the content of the attachment file, if it was downloaded.
+func (a *Attachment) Data() []byte {
+	return a.data
+}
+
+// Options defines the options for Backup.
+type Options struct {
+
+	// Attachments includes the metadata of the attachments, and which
+	// transactions they are assigned to. This makes one request per
+	// transaction, since the API doesn't return the attachments of a
+	// transaction when listing them. Defaults to false.
+	Attachments bool
+
+	// Files downloads the attachment files into the archive, so that they can
+	// be restored. Implies Attachments. Defaults to false.
+	Files bool
+
+	// HTTPClient is used to download the attachment files. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+
+	// Now returns the time the archive was created. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Backup lists everything the given user has into an Archive.
+func Backup(
+	ctx context.Context,
+	api API,
+	userID int,
+	options *Options,
+) (archive *Archive, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Backup")
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to backup: %v", err))
+			span.RecordError(err)
+		}
+	}()
+
+	// default options.
+	o := Options{}
+	if options != nil {
+		o = *options
+	}
+	if o.Files {
+		o.Attachments = true
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = http.DefaultClient
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	archive = &Archive{Version: Version, CreatedAt: o.Now().UTC()}
+
+	// list everything but transactions & attachments.
+	user, err := api.GetUser(newCtx, &pocketsmith.GetUserOptions{UserID: userID})
+	if err != nil {
+		return nil, ErrBackupFailedList{"user", err}
+	}
+	archive.User = *user
+	archive.Institutions, err = api.ListInstitutionsForUser(
+		newCtx,
+		&pocketsmith.ListInstitutionsForUser{UserID: userID},
+	)
+	if err != nil {
+		return nil, ErrBackupFailedList{"institutions", err}
+	}
+	archive.Accounts, err = api.ListAccountsForUser(
+		newCtx,
+		&pocketsmith.ListAccountsForUserOptions{UserID: userID},
+	)
+	if err != nil {
+		return nil, ErrBackupFailedList{"accounts", err}
+	}
+	archive.TransactionAccounts, err = api.ListTransactionAccountsForUser(
+		newCtx,
+		&pocketsmith.ListTransactionAccountsForUserOptions{UserID: userID},
+	)
+	if err != nil {
+		return nil, ErrBackupFailedList{"transaction accounts", err}
+	}
+	archive.Categories, err = api.ListCategoriesForUser(
+		newCtx,
+		&pocketsmith.ListCategoriesForUserOptions{UserID: userID},
+	)
+	if err != nil {
+		return nil, ErrBackupFailedList{"categories", err}
+	}
+
+	// list transactions.
+	err = api.ListTransactionsForUserPages(
+		newCtx,
+		&pocketsmith.ListTransactionsForUserOptions{UserID: userID},
+		func(batch pocketsmith.Transactions) error {
+			archive.Transactions = append(archive.Transactions, batch...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, ErrBackupFailedList{"transactions", err}
+	}
+
+	// list attachments.
+	if o.Attachments {
+		if archive.Attachments, err = listAttachments(newCtx, api, userID, archive.Transactions); err != nil {
+			return nil, err
+		}
+	}
+	if o.Files {
+		for i := range archive.Attachments {
+			a := &archive.Attachments[i]
+			if a.data, err = download(newCtx, o.HTTPClient, a.OriginalURL); err != nil {
+				return nil, ErrBackupFailedDownload{a.ID, err}
+			}
+			a.File = fmt.Sprintf("attachments/%v/%s", a.ID, fileName(a.Attachment))
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("transactions", len(archive.Transactions)),
+		attribute.Int("attachments", len(archive.Attachments)),
+	)
+	return archive, nil
+}
+
+// listAttachments lists the attachments of the given user, along with the
+// transactions each is assigned to.
+func listAttachments(
+	ctx context.Context,
+	api API,
+	userID int,
+	transactions pocketsmith.Transactions,
+) (attachments []Attachment, err error) {
+	byID := make(map[int]int)
+	add := func(a pocketsmith.Attachment) *Attachment {
+		i, ok := byID[a.ID]
+		if !ok {
+			i = len(attachments)
+			byID[a.ID] = i
+			attachments = append(attachments, Attachment{Attachment: a})
+		}
+		return &attachments[i]
+	}
+
+	// NOTE: the API only lists the attachments of a user that aren't assigned
+	// to a transaction; the assigned ones are found by listing the attachments
+	// of each transaction.
+	for _, t := range transactions {
+		assigned, err := api.ListTransactionAttachments(
+			ctx,
+			&pocketsmith.ListTransactionAttachmentsOptions{TransactionID: t.ID},
+		)
+		if err != nil {
+			return nil, ErrBackupFailedList{"attachments", err}
+		}
+		for _, a := range assigned {
+			added := add(a)
+			added.TransactionIDs = append(added.TransactionIDs, t.ID)
+		}
+	}
+	unassigned, err := api.ListAttachmentsForUser(ctx, &pocketsmith.ListAttachmentsForUserOptions{
+		UserID:                 userID,
+		ListAttachmentsOptions: pocketsmith.ListAttachmentsOptions{Unassigned: 1},
+	})
+	if err != nil {
+		return nil, ErrBackupFailedList{"attachments", err}
+	}
+	for _, a := range unassigned {
+		add(a)
+	}
+	return attachments, nil
+}
+
+// download returns the content at the given url.
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if url == "" {
+		return nil, fmt.Errorf("attachment has no url")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
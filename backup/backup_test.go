@@ -0,0 +1,367 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// mockAPI is a mock implementation of the API and RestoreAPI interfaces. It
+// returns the entities it holds, and records the entities created in it.
+type mockAPI struct {
+	user                pocketsmith.User
+	institutions        pocketsmith.Institutions
+	accounts            pocketsmith.Accounts
+	transactionAccounts pocketsmith.TransactionAccounts
+	categories          pocketsmith.Categories
+	transactions        pocketsmith.Transactions
+	attachments         map[int32]pocketsmith.Attachments // By transaction id; 0 holds the unassigned.
+
+	// created.
+	nextID              int
+	created             []string
+	createdTransactions []*pocketsmith.CreateTransactionAccountTransactionOptions
+	parents             map[string]string // The parent id given to each created category, by title.
+	assigned            map[int32]int
+	startingBalances    map[int]string // The starting balance given to each transaction account, by id.
+}
+
+func (m *mockAPI) id() int {
+	m.nextID++
+	return 100 + m.nextID
+}
+
+func (m *mockAPI) GetUser(context.Context, *pocketsmith.GetUserOptions) (*pocketsmith.User, error) {
+	return &m.user, nil
+}
+
+func (m *mockAPI) ListInstitutionsForUser(
+	context.Context,
+	*pocketsmith.ListInstitutionsForUser,
+) (pocketsmith.Institutions, error) {
+	return m.institutions, nil
+}
+
+func (m *mockAPI) ListAccountsForUser(
+	context.Context,
+	*pocketsmith.ListAccountsForUserOptions,
+) (pocketsmith.Accounts, error) {
+	return m.accounts, nil
+}
+
+func (m *mockAPI) ListTransactionAccountsForUser(
+	context.Context,
+	*pocketsmith.ListTransactionAccountsForUserOptions,
+) (pocketsmith.TransactionAccounts, error) {
+	return m.transactionAccounts, nil
+}
+
+func (m *mockAPI) ListCategoriesForUser(
+	context.Context,
+	*pocketsmith.ListCategoriesForUserOptions,
+) (pocketsmith.Categories, error) {
+	return m.categories, nil
+}
+
+func (m *mockAPI) ListTransactionsForUserPages(
+	_ context.Context,
+	_ *pocketsmith.ListTransactionsForUserOptions,
+	fn func(pocketsmith.Transactions) error,
+) error {
+	return fn(m.transactions)
+}
+
+func (m *mockAPI) ListAttachmentsForUser(
+	context.Context,
+	*pocketsmith.ListAttachmentsForUserOptions,
+) (pocketsmith.Attachments, error) {
+	return m.attachments[0], nil
+}
+
+func (m *mockAPI) ListTransactionAttachments(
+	_ context.Context,
+	options *pocketsmith.ListTransactionAttachmentsOptions,
+) (pocketsmith.Attachments, error) {
+	return m.attachments[options.TransactionID], nil
+}
+
+func (m *mockAPI) CreateInstitutionForUser(
+	_ context.Context,
+	options *pocketsmith.CreateInstitutionOptionsForUser,
+) (*pocketsmith.Institution, error) {
+	m.created = append(m.created, "institution "+options.Title)
+	i := pocketsmith.Institution{ID: m.id(), Title: options.Title, CurrencyCode: options.CurrencyCode}
+	m.institutions = append(m.institutions, i)
+	return &i, nil
+}
+
+func (m *mockAPI) CreateAccountForUser(
+	_ context.Context,
+	options *pocketsmith.CreateAccountForUserOptions,
+) (*pocketsmith.Account, error) {
+	m.created = append(m.created, "account "+options.Title)
+	a := pocketsmith.Account{
+		ID:           m.id(),
+		Title:        options.Title,
+		CurrencyCode: options.CurrencyCode,
+		PrimaryTransactionAccount: pocketsmith.TransactionAccount{
+			ID:          m.id(),
+			Institution: pocketsmith.Institution{ID: options.InstitutionID},
+		},
+	}
+	m.accounts = append(m.accounts, a)
+	return &a, nil
+}
+
+func (m *mockAPI) UpdateTransactionAccount(
+	_ context.Context,
+	options *pocketsmith.UpdateTransactionAccountOptions,
+) (*pocketsmith.TransactionAccount, error) {
+	if m.startingBalances == nil {
+		m.startingBalances = make(map[int]string)
+	}
+	m.startingBalances[options.TransactionAccountID] = fmt.Sprintf(
+		"%.2f on %s",
+		*options.StartingBalance,
+		options.StartingBalanceDate,
+	)
+	return &pocketsmith.TransactionAccount{ID: options.TransactionAccountID}, nil
+}
+
+func (m *mockAPI) CreateCategoryForUser(
+	_ context.Context,
+	options *pocketsmith.CreateCategoryForUserOptions,
+) (*pocketsmith.Category, error) {
+	m.created = append(m.created, "category "+options.Title)
+	if m.parents == nil {
+		m.parents = make(map[string]string)
+	}
+	m.parents[options.Title] = options.ParentID
+	return &pocketsmith.Category{ID: int32(m.id()), Title: options.Title}, nil
+}
+
+func (m *mockAPI) CreateTransactionAccountTransaction(
+	_ context.Context,
+	options *pocketsmith.CreateTransactionAccountTransactionOptions,
+) (*pocketsmith.Transaction, error) {
+	m.createdTransactions = append(m.createdTransactions, options)
+	return &pocketsmith.Transaction{ID: int32(m.id())}, nil
+}
+
+func (m *mockAPI) CreateAttachmentForUser(
+	_ context.Context,
+	options *pocketsmith.CreateAttachmentForUserOptions,
+) (*pocketsmith.Attachment, error) {
+	data, _ := base64.StdEncoding.DecodeString(options.FileData)
+	m.created = append(m.created, "attachment "+options.FileName+" "+string(data))
+	if m.attachments == nil {
+		m.attachments = make(map[int32]pocketsmith.Attachments)
+	}
+	attachment := pocketsmith.Attachment{ID: m.id(), Title: options.Title, FileName: options.FileName}
+	m.attachments[0] = append(m.attachments[0], attachment)
+	return &attachment, nil
+}
+
+func (m *mockAPI) AssignAttachmentToTransaction(
+	_ context.Context,
+	options *pocketsmith.AssignAttachmentToTransactionOptions,
+) (*pocketsmith.Attachment, error) {
+	if m.assigned == nil {
+		m.assigned = make(map[int32]int)
+	}
+	m.assigned[options.TransactionID] = options.AttachmentID
+	return &pocketsmith.Attachment{ID: options.AttachmentID}, nil
+}
+
+func Test_BackupRestore(t *testing.T) {
+
+	// serve the attachment files.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("receipt of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	// setup source.
+	bank := pocketsmith.Institution{ID: 1, Title: "Bank", CurrencyCode: "aud"}
+	everyday := pocketsmith.TransactionAccount{
+		ID:                  10,
+		Name:                "Everyday",
+		Institution:         bank,
+		StartingBalance:     250,
+		StartingBalanceDate: "2023-12-31",
+	}
+	savings := pocketsmith.TransactionAccount{ID: 11, Name: "Savings", Institution: bank}
+	groceries := &pocketsmith.Category{ID: 3, Title: "Groceries"}
+	source := &mockAPI{
+		user:         pocketsmith.User{ID: 1, Login: "source"},
+		institutions: pocketsmith.Institutions{bank},
+		accounts: pocketsmith.Accounts{{
+			ID:                        5,
+			Title:                     "Bank",
+			PrimaryTransactionAccount: everyday,
+			TransactionAccounts:       pocketsmith.TransactionAccounts{everyday, savings},
+		}},
+		transactionAccounts: pocketsmith.TransactionAccounts{everyday, savings},
+		categories: pocketsmith.Categories{
+			{ID: 2, Title: "Food", Children: []*pocketsmith.Category{groceries}},
+			{ID: 4, Title: "Transport"},
+		},
+		transactions: pocketsmith.Transactions{
+			{ID: 21, Date: "2024-01-02", Payee: "Coles", Amount: -20, Category: *groceries, TransactionAccount: everyday},
+			{ID: 20, Date: "2024-01-01", Payee: "Shell", Amount: -50, Labels: []string{"car"}, TransactionAccount: everyday},
+			{ID: 22, Date: "2024-01-03", Payee: "Interest", Amount: 1, TransactionAccount: savings},
+		},
+		attachments: map[int32]pocketsmith.Attachments{
+			21: {{ID: 7, Title: "Receipt", FileName: "../receipt.pdf", OriginalURL: server.URL + "/7"}},
+		},
+	}
+
+	// backup, then round trip the archive.
+	archive, err := Backup(context.Background(), source, 1, &Options{
+		Files: true,
+		Now:   func() time.Time { return time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC) },
+	})
+	if err != nil {
+		t.Fatalf("Backup() returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, archive); err != nil {
+		t.Fatalf("WriteArchive() returned an error: %v", err)
+	}
+	archive, err = ReadArchive(&buf)
+	if err != nil {
+		t.Fatalf("ReadArchive() returned an error: %v", err)
+	}
+	if archive.Version != Version || archive.User.Login != "source" || len(archive.Transactions) != 3 {
+		t.Fatalf("ReadArchive() returned unexpected archive: %+v", archive)
+	}
+	if want := []int32{21}; !reflect.DeepEqual(archive.Attachments[0].TransactionIDs, want) {
+		t.Errorf("Backup() assigned attachment to %v; want %v", archive.Attachments[0].TransactionIDs, want)
+	}
+
+	// restore into a user that already has the "Food" category.
+	target := &mockAPI{
+		categories: pocketsmith.Categories{{ID: 50, Title: "food"}},
+	}
+	result, err := Restore(context.Background(), target, archive, 2, nil)
+	if err != nil {
+		t.Fatalf("Restore() returned an error: %v", err)
+	}
+	wantCreated := []string{
+		"institution Bank",
+		"account Bank",
+		"category Groceries",
+		"category Transport",
+		"attachment receipt.pdf receipt of /7",
+	}
+	if !reflect.DeepEqual(target.created, wantCreated) {
+		t.Errorf("Restore() created unexpected entities;\nwant=%v\ngot=%v", wantCreated, target.created)
+	}
+	if got := target.parents["Groceries"]; got != "50" {
+		t.Errorf("Restore() created Groceries under parent %q; want 50", got)
+	}
+	wantStartingBalances := map[int]string{result.IDs.TransactionAccounts[10]: "250.00 on 2023-12-31"}
+	if !reflect.DeepEqual(target.startingBalances, wantStartingBalances) {
+		t.Errorf("Restore() set unexpected starting balances;\nwant=%v\ngot=%v", wantStartingBalances, target.startingBalances)
+	}
+
+	// check transactions are restored oldest first, with their ids remapped.
+	ta := int(result.IDs.TransactionAccounts[10])
+	wantTransactions := []*pocketsmith.CreateTransactionAccountTransactionOptions{
+		{TransactionAccountID: ta, Payee: "Shell", Amount: -50, Date: "2024-01-01", Labels: "car"},
+		{TransactionAccountID: ta, Payee: "Coles", Amount: -20, Date: "2024-01-02", CategoryID: result.IDs.Categories[3]},
+	}
+	if !reflect.DeepEqual(target.createdTransactions, wantTransactions) {
+		t.Errorf("Restore() created unexpected transactions;\nwant=%+v\ngot=%+v", wantTransactions, target.createdTransactions)
+	}
+	if got := target.assigned[result.IDs.Transactions[21]]; got != result.IDs.Attachments[7] {
+		t.Errorf("Restore() assigned attachment %v; want %v", got, result.IDs.Attachments[7])
+	}
+
+	// check the savings transaction account couldn't be restored.
+	var failed []string
+	for _, f := range result.Failures {
+		failed = append(failed, f.String())
+	}
+	wantFailed := []string{
+		"transaction account 11: can't be restored; only the primary transaction account of an account can be restored",
+		"transaction 22: transaction account 11 wasn't restored",
+	}
+	if !reflect.DeepEqual(failed, wantFailed) {
+		t.Errorf("Restore() returned unexpected failures;\nwant=%v\ngot=%v", wantFailed, failed)
+	}
+}
+
+func Test_Restore_resumable(t *testing.T) {
+	bank := pocketsmith.Institution{ID: 1, Title: "Bank", CurrencyCode: "aud"}
+	everyday := pocketsmith.TransactionAccount{ID: 10, Name: "Everyday", Institution: bank}
+	archive := &Archive{
+		Version:      Version,
+		Institutions: pocketsmith.Institutions{bank},
+		Accounts: pocketsmith.Accounts{{
+			ID:                        5,
+			Title:                     "Everyday",
+			CurrencyCode:              "aud",
+			PrimaryTransactionAccount: everyday,
+		}},
+		Transactions: pocketsmith.Transactions{
+			{ID: 20, Date: "2024-01-01", Payee: "Cafe", Amount: -5, TransactionAccount: everyday},
+			{ID: 21, Date: "2024-01-01", Payee: "Cafe", Amount: -5, TransactionAccount: everyday},
+		},
+		Attachments: []Attachment{{
+			Attachment:     pocketsmith.Attachment{ID: 7, Title: "Receipt", FileName: "receipt.pdf"},
+			TransactionIDs: []int32{21},
+			File:           "attachments/7/receipt.pdf",
+			data:           []byte("receipt"),
+		}},
+	}
+
+	// restore twice, as if the first restore was interrupted.
+	target := &mockAPI{}
+	for i := 0; i < 2; i++ {
+		result, err := Restore(context.Background(), target, archive, 2, &RestoreOptions{Resumable: true})
+		if err != nil {
+			t.Fatalf("Restore() returned an error: %v", err)
+		}
+		if len(result.Failures) > 0 {
+			t.Fatalf("Restore() returned unexpected failures: %v", result.Failures)
+		}
+	}
+
+	// check the account and attachment are only created once, and that
+	// identical transactions are keyed by their id in the archive.
+	wantCreated := []string{"institution Bank", "account Everyday", "attachment receipt.pdf receipt"}
+	if !reflect.DeepEqual(target.created, wantCreated) {
+		t.Errorf("Restore() created unexpected entities;\nwant=%v\ngot=%v", wantCreated, target.created)
+	}
+	var keys []string
+	for _, o := range target.createdTransactions {
+		if !o.Idempotent {
+			t.Errorf("Restore() created transaction %q without being idempotent", o.IdempotencyKey)
+		}
+		keys = append(keys, o.IdempotencyKey)
+	}
+	want := []string{"archive:20", "archive:21", "archive:20", "archive:21"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Restore() used unexpected idempotency keys;\nwant=%v\ngot=%v", want, keys)
+	}
+}
+
+func Test_ReadArchive_unsupportedVersion(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, &Archive{Version: Version + 1}); err != nil {
+		t.Fatalf("WriteArchive() returned an error: %v", err)
+	}
+	_, err := ReadArchive(&buf)
+	if want := (ErrArchiveUnsupportedVersion{Version + 1}); err != want {
+		t.Errorf("ReadArchive() returned unexpected error; want=%v, got=%v", want, err)
+	}
+}
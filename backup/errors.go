@@ -0,0 +1,98 @@
+package backup
+
+import (
+	"fmt"
+)
+
+// ErrBackupFailedList is returned when Backup fails to list an entity from
+// the API.
+type ErrBackupFailedList struct {
+	entity string
+	err    error
+}
+
+func (e ErrBackupFailedList) Error() string {
+	return fmt.Sprintf("failed to list %s: %v", e.entity, e.err)
+}
+
+// ErrBackupFailedDownload is returned when Backup fails to download the file
+// of an attachment.
+type ErrBackupFailedDownload struct {
+	id  int
+	err error
+}
+
+func (e ErrBackupFailedDownload) Error() string {
+	return fmt.Sprintf("failed to download attachment %v: %v", e.id, e.err)
+}
+
+// ErrArchiveFailedWrite is returned when an archive fails to be written.
+type ErrArchiveFailedWrite struct {
+	err error
+}
+
+func (e ErrArchiveFailedWrite) Error() string {
+	return fmt.Sprintf("failed to write archive: %v", e.err)
+}
+
+// ErrArchiveFailedRead is returned when an archive fails to be read.
+type ErrArchiveFailedRead struct {
+	err error
+}
+
+func (e ErrArchiveFailedRead) Error() string {
+	return fmt.Sprintf("failed to read archive: %v", e.err)
+}
+
+// ErrArchiveUnsupportedVersion is returned when an archive was written by a
+// newer, or unknown, version of this package.
+type ErrArchiveUnsupportedVersion struct {
+	version int
+}
+
+func (e ErrArchiveUnsupportedVersion) Error() string {
+	return fmt.Sprintf("unsupported archive version %v; expected 1 to %v", e.version, Version)
+}
+
+// ErrRestoreFailedList is returned when Restore fails to list the existing
+// entities of the user being restored into.
+type ErrRestoreFailedList struct {
+	entity string
+	err    error
+}
+
+func (e ErrRestoreFailedList) Error() string {
+	return fmt.Sprintf("failed to list existing %s: %v", e.entity, e.err)
+}
+
+// ErrRestoreUnmapped is returned when an entity can't be restored, since an
+// entity it depends on wasn't restored.
+type ErrRestoreUnmapped struct {
+	entity string
+	id     int64
+}
+
+func (e ErrRestoreUnmapped) Error() string {
+	return fmt.Sprintf("%s %v wasn't restored", e.entity, e.id)
+}
+
+// ErrRestoreFailedStartingBalance is returned when Restore fails to set the
+// starting balance of a restored transaction account, so its balances won't
+// match those in the archive.
+type ErrRestoreFailedStartingBalance struct {
+	err error
+}
+
+func (e ErrRestoreFailedStartingBalance) Error() string {
+	return fmt.Sprintf("the starting balance wasn't restored: %v", e.err)
+}
+
+// ErrRestoreUnsupported is returned when an entity can't be restored through
+// the API.
+type ErrRestoreUnsupported struct {
+	reason string
+}
+
+func (e ErrRestoreUnsupported) Error() string {
+	return fmt.Sprintf("can't be restored; %s", e.reason)
+}
@@ -0,0 +1,484 @@
+package backup
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// RestoreAPI defines the parts of the *pocketsmith.Client used by Restore.
+type RestoreAPI interface {
+	ListInstitutionsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListInstitutionsForUser,
+	) (pocketsmith.Institutions, error)
+	ListCategoriesForUser(
+		ctx context.Context,
+		options *pocketsmith.ListCategoriesForUserOptions,
+	) (pocketsmith.Categories, error)
+	ListAccountsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListAccountsForUserOptions,
+	) (pocketsmith.Accounts, error)
+	CreateInstitutionForUser(
+		ctx context.Context,
+		options *pocketsmith.CreateInstitutionOptionsForUser,
+	) (*pocketsmith.Institution, error)
+	CreateAccountForUser(
+		ctx context.Context,
+		options *pocketsmith.CreateAccountForUserOptions,
+	) (*pocketsmith.Account, error)
+	UpdateTransactionAccount(
+		ctx context.Context,
+		options *pocketsmith.UpdateTransactionAccountOptions,
+	) (*pocketsmith.TransactionAccount, error)
+	CreateCategoryForUser(
+		ctx context.Context,
+		options *pocketsmith.CreateCategoryForUserOptions,
+	) (*pocketsmith.Category, error)
+	CreateTransactionAccountTransaction(
+		ctx context.Context,
+		options *pocketsmith.CreateTransactionAccountTransactionOptions,
+	) (*pocketsmith.Transaction, error)
+	ListAttachmentsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListAttachmentsForUserOptions,
+	) (pocketsmith.Attachments, error)
+	CreateAttachmentForUser(
+		ctx context.Context,
+		options *pocketsmith.CreateAttachmentForUserOptions,
+	) (*pocketsmith.Attachment, error)
+	AssignAttachmentToTransaction(
+		ctx context.Context,
+		options *pocketsmith.AssignAttachmentToTransactionOptions,
+	) (*pocketsmith.Attachment, error)
+}
+
+// RestoreOptions defines the options for Restore.
+type RestoreOptions struct {
+
+	// Resumable lets a restore that was interrupted be run again without
+	// creating the same entities twice. Accounts that already exist with the
+	// same title, currency and institution are reused, and transactions are
+	// created idempotently (see
+	// pocketsmith.CreateTransactionAccountTransactionOptions.Idempotent),
+	// keyed by their id in the archive. Unless the client has an idempotency
+	// ledger, this adds a marker to the note of each transaction. Attachments
+	// that already exist with the same title and file name are reused, and
+	// assigned to their transactions again. Defaults to false.
+	Resumable bool
+}
+
+// IDs maps the ids in an Archive to the ids of the entities restored from
+// it.
+type IDs struct {
+	Institutions        map[int]int     `json:"institutions"`
+	Accounts            map[int]int     `json:"accounts"`
+	TransactionAccounts map[int]int     `json:"transaction_accounts"`
+	Categories          map[int32]int32 `json:"categories"`
+	Transactions        map[int32]int32 `json:"transactions"`
+	Attachments         map[int]int     `json:"attachments"`
+}
+
+// Failure defines an entity in an Archive that failed to be restored.
+type Failure struct {
+	Entity string // The type of entity, eg. "transaction".
+	ID     int64  // The id of the entity in the archive.
+	Err    error
+}
+
+// String returns the failure as a single line.
+func (f Failure) String() string {
+	return fmt.Sprintf("%s %v: %v", f.Entity, f.ID, f.Err)
+}
+
+// RestoreResult defines the outcome of a restore.
+type RestoreResult struct {
+	IDs      IDs
+	Failures []Failure
+}
+
+// Restore recreates the given archive in the given user, returning how the
+// ids in the archive map to the ids of the restored entities. Institutions
+// and categories that already exist in the user, by title (and currency, for
+// institutions) or by category path, are reused rather than created again.
+// An entity that fails to be restored doesn't stop the restore; it is
+// recorded as a Failure, as are the entities that depend on it.
+//
+// The starting balance of each restored transaction account is set to the
+// one in the archive, so that the restored balances match.
+//
+// Some things can't be restored through the API, and are recorded as
+// failures:
+//   - the transaction accounts of an account other than its primary one,
+//     since an account is created with a single transaction account.
+//   - attachments whose files weren't downloaded into the archive.
+//
+// The user settings in the archive are never restored; they are only kept
+// for reference.
+func Restore(
+	ctx context.Context,
+	api RestoreAPI,
+	archive *Archive,
+	userID int,
+	options *RestoreOptions,
+) (result *RestoreResult, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Restore")
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to restore: %v", err))
+			span.RecordError(err)
+		}
+	}()
+
+	o := RestoreOptions{}
+	if options != nil {
+		o = *options
+	}
+	if archive.Version < 1 || archive.Version > Version {
+		return nil, ErrArchiveUnsupportedVersion{archive.Version}
+	}
+	r := &restorer{
+		api:       api,
+		userID:    userID,
+		resumable: o.Resumable,
+		result: &RestoreResult{IDs: IDs{
+			Institutions:        make(map[int]int),
+			Accounts:            make(map[int]int),
+			TransactionAccounts: make(map[int]int),
+			Categories:          make(map[int32]int32),
+			Transactions:        make(map[int32]int32),
+			Attachments:         make(map[int]int),
+		}},
+	}
+
+	// restore.
+	if err := r.institutions(newCtx, archive); err != nil {
+		return nil, err
+	}
+	if err := r.accounts(newCtx, archive); err != nil {
+		return nil, err
+	}
+	if err := r.categories(newCtx, archive); err != nil {
+		return nil, err
+	}
+	r.transactions(newCtx, archive)
+	if err := r.attachments(newCtx, archive); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("transactions", len(r.result.IDs.Transactions)),
+		attribute.Int("failures", len(r.result.Failures)),
+	)
+	if n := len(r.result.Failures); n > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to restore %v entities", n))
+	}
+	return r.result, nil
+}
+
+// restorer holds the state of a restore.
+type restorer struct {
+	api       RestoreAPI
+	userID    int
+	resumable bool
+	result    *RestoreResult
+}
+
+// fail records a failure to restore the given entity.
+func (r *restorer) fail(entity string, id int64, err error) {
+	r.result.Failures = append(r.result.Failures, Failure{entity, id, err})
+}
+
+// institutions restores the institutions in the archive, reusing those that
+// already exist with the same title and currency.
+func (r *restorer) institutions(ctx context.Context, archive *Archive) error {
+	existing, err := r.api.ListInstitutionsForUser(
+		ctx,
+		&pocketsmith.ListInstitutionsForUser{UserID: r.userID},
+	)
+	if err != nil {
+		return ErrRestoreFailedList{"institutions", err}
+	}
+	key := func(i pocketsmith.Institution) string {
+		return strings.ToLower(i.Title) + "\n" + strings.ToLower(i.CurrencyCode)
+	}
+	byKey := make(map[string]int, len(existing))
+	for _, i := range existing {
+		byKey[key(i)] = i.ID
+	}
+	for _, i := range archive.Institutions {
+		if id, ok := byKey[key(i)]; ok {
+			r.result.IDs.Institutions[i.ID] = id
+			continue
+		}
+		created, err := r.api.CreateInstitutionForUser(ctx, &pocketsmith.CreateInstitutionOptionsForUser{
+			UserID: r.userID,
+			CreateInstitutionOptions: pocketsmith.CreateInstitutionOptions{
+				Title:        i.Title,
+				CurrencyCode: i.CurrencyCode,
+			},
+		})
+		if err != nil {
+			r.fail("institution", int64(i.ID), err)
+			continue
+		}
+		r.result.IDs.Institutions[i.ID] = created.ID
+		byKey[key(i)] = created.ID
+	}
+	return nil
+}
+
+// accounts restores the accounts in the archive, mapping their primary
+// transaction accounts to those of the created accounts, and setting their
+// starting balances. When resumable, accounts that already exist with the
+// same title, currency and institution are reused.
+func (r *restorer) accounts(ctx context.Context, archive *Archive) error {
+	key := func(title, currency string, institutionID int) string {
+		return fmt.Sprintf("%s\n%s\n%v", strings.ToLower(title), strings.ToLower(currency), institutionID)
+	}
+	byKey := make(map[string]*pocketsmith.Account)
+	if r.resumable {
+		existing, err := r.api.ListAccountsForUser(
+			ctx,
+			&pocketsmith.ListAccountsForUserOptions{UserID: r.userID},
+		)
+		if err != nil {
+			return ErrRestoreFailedList{"accounts", err}
+		}
+		for i, a := range existing {
+			byKey[key(a.Title, a.CurrencyCode, a.PrimaryTransactionAccount.Institution.ID)] = &existing[i]
+		}
+	}
+	for _, a := range archive.Accounts {
+		institutionID := a.PrimaryTransactionAccount.Institution.ID
+		if len(a.TransactionAccounts) > 0 && institutionID == 0 {
+			institutionID = a.TransactionAccounts[0].Institution.ID
+		}
+		newInstitutionID, ok := r.result.IDs.Institutions[institutionID]
+		if !ok {
+			r.fail("account", int64(a.ID), ErrRestoreUnmapped{"institution", int64(institutionID)})
+			continue
+		}
+		created, ok := byKey[key(a.Title, a.CurrencyCode, newInstitutionID)]
+		if !ok {
+			var err error
+			created, err = r.api.CreateAccountForUser(ctx, &pocketsmith.CreateAccountForUserOptions{
+				UserID: r.userID,
+				CreateAccountOptions: pocketsmith.CreateAccountOptions{
+					InstitutionID: newInstitutionID,
+					Title:         a.Title,
+					CurrencyCode:  a.CurrencyCode,
+					Type:          a.Type,
+				},
+			})
+			if err != nil {
+				r.fail("account", int64(a.ID), err)
+				continue
+			}
+		}
+		r.result.IDs.Accounts[a.ID] = created.ID
+		primary := a.PrimaryTransactionAccount.ID
+		if primary != 0 && created.PrimaryTransactionAccount.ID != 0 {
+			r.result.IDs.TransactionAccounts[primary] = created.PrimaryTransactionAccount.ID
+			r.startingBalance(ctx, a.PrimaryTransactionAccount, created.PrimaryTransactionAccount.ID)
+		}
+		for _, ta := range a.TransactionAccounts {
+			if ta.ID != primary {
+				r.fail("transaction account", int64(ta.ID), ErrRestoreUnsupported{
+					"only the primary transaction account of an account can be restored",
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// startingBalance sets the starting balance of the restored transaction
+// account with the given id to that of the given archived transaction
+// account.
+func (r *restorer) startingBalance(ctx context.Context, ta pocketsmith.TransactionAccount, id int) {
+	if ta.StartingBalance == 0 && ta.StartingBalanceDate == "" {
+		return
+	}
+	balance := ta.StartingBalance
+	_, err := r.api.UpdateTransactionAccount(ctx, &pocketsmith.UpdateTransactionAccountOptions{
+		TransactionAccountID: id,
+		StartingBalance:      &balance,
+		StartingBalanceDate:  ta.StartingBalanceDate,
+	})
+	if err != nil {
+		r.fail("transaction account", int64(ta.ID), ErrRestoreFailedStartingBalance{err})
+	}
+}
+
+// categories restores the category tree in the archive, parents first,
+// reusing the categories that already exist with the same path.
+func (r *restorer) categories(ctx context.Context, archive *Archive) error {
+	existing, err := r.api.ListCategoriesForUser(
+		ctx,
+		&pocketsmith.ListCategoriesForUserOptions{UserID: r.userID},
+	)
+	if err != nil {
+		return ErrRestoreFailedList{"categories", err}
+	}
+	byPath := make(map[string]int32)
+	for _, c := range pocketsmith.NewCategoryTree(existing).Flatten() {
+		byPath[strings.ToLower(c.Path)] = c.ID
+	}
+	tree := pocketsmith.NewCategoryTree(archive.Categories)
+	tree.Walk(func(c *pocketsmith.Category, _ int) bool {
+		path := tree.Path(c.ID)
+		if id, ok := byPath[strings.ToLower(path)]; ok {
+			r.result.IDs.Categories[c.ID] = id
+			return true
+		}
+		options := &pocketsmith.CreateCategoryForUserOptions{
+			UserID: r.userID,
+			CreateCategoryOptions: pocketsmith.CreateCategoryOptions{
+				Title:           c.Title,
+				Colour:          c.Colour,
+				IsTransfer:      c.IsTransfer,
+				IsBill:          c.IsBill,
+				RollUp:          c.RollUp,
+				RefundBehaviour: c.RefundBehaviour,
+			},
+		}
+		if parent, ok := tree.Parent(c.ID); ok {
+			options.ParentID = strconv.Itoa(int(r.result.IDs.Categories[parent.ID]))
+		}
+		created, err := r.api.CreateCategoryForUser(ctx, options)
+		if err != nil {
+			r.fail("category", int64(c.ID), err)
+			return false // the children can't be created without their parent.
+		}
+		r.result.IDs.Categories[c.ID] = created.ID
+		byPath[strings.ToLower(path)] = created.ID
+		return true
+	})
+	return nil
+}
+
+// transactions restores the transactions in the archive, oldest first.
+func (r *restorer) transactions(ctx context.Context, archive *Archive) {
+	transactions := append(pocketsmith.Transactions(nil), archive.Transactions...)
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date < transactions[j].Date
+	})
+	for _, t := range transactions {
+		transactionAccountID, ok := r.result.IDs.TransactionAccounts[t.TransactionAccount.ID]
+		if !ok {
+			r.fail("transaction", int64(t.ID), ErrRestoreUnmapped{
+				"transaction account",
+				int64(t.TransactionAccount.ID),
+			})
+			continue
+		}
+		options := &pocketsmith.CreateTransactionAccountTransactionOptions{
+			TransactionAccountID: transactionAccountID,
+			Payee:                t.Payee,
+			Amount:               t.Amount,
+			Date:                 t.Date,
+			IsTransfer:           t.IsTransfer,
+			Labels:               strings.Join(t.Labels, ","),
+			Note:                 t.Note,
+			Memo:                 t.Memo,
+			ChequeNumber:         t.ChequeNumber,
+			NeedsReview:          t.NeedsReview,
+		}
+		if r.resumable {
+			options.Idempotent = true
+			options.IdempotencyKey = fmt.Sprintf("archive:%v", t.ID)
+		}
+		if t.Category.ID != 0 {
+			categoryID, ok := r.result.IDs.Categories[t.Category.ID]
+			if !ok {
+				r.fail("transaction", int64(t.ID), ErrRestoreUnmapped{"category", int64(t.Category.ID)})
+				continue
+			}
+			options.CategoryID = categoryID
+		}
+		created, err := r.api.CreateTransactionAccountTransaction(ctx, options)
+		if err != nil {
+			r.fail("transaction", int64(t.ID), err)
+			continue
+		}
+		r.result.IDs.Transactions[t.ID] = created.ID
+	}
+}
+
+// attachments restores the attachments in the archive whose files were
+// downloaded, assigning them to the restored transactions. When resumable,
+// attachments that already exist with the same title and file name are
+// reused.
+func (r *restorer) attachments(ctx context.Context, archive *Archive) error {
+	key := func(title, fileName string) string {
+		return title + "\n" + fileName
+	}
+	byKey := make(map[string]int)
+	if r.resumable {
+		existing, err := r.api.ListAttachmentsForUser(
+			ctx,
+			&pocketsmith.ListAttachmentsForUserOptions{UserID: r.userID},
+		)
+		if err != nil {
+			return ErrRestoreFailedList{"attachments", err}
+		}
+		for _, a := range existing {
+			byKey[key(a.Title, a.FileName)] = a.ID
+		}
+	}
+	for _, a := range archive.Attachments {
+		if a.File == "" {
+			r.fail("attachment", int64(a.ID), ErrRestoreUnsupported{
+				"the attachment file wasn't downloaded into the archive",
+			})
+			continue
+		}
+		name := fileName(a.Attachment)
+		attachmentID, ok := byKey[key(a.Title, name)]
+		if !ok {
+			created, err := r.api.CreateAttachmentForUser(ctx, &pocketsmith.CreateAttachmentForUserOptions{
+				UserID: r.userID,
+				CreateAttachmentOptions: pocketsmith.CreateAttachmentOptions{
+					Title:    a.Title,
+					FileName: name,
+					FileData: base64.StdEncoding.EncodeToString(a.data),
+				},
+			})
+			if err != nil {
+				r.fail("attachment", int64(a.ID), err)
+				continue
+			}
+			attachmentID = created.ID
+		}
+		r.result.IDs.Attachments[a.ID] = attachmentID
+		for _, id := range a.TransactionIDs {
+			transactionID, ok := r.result.IDs.Transactions[id]
+			if !ok {
+				r.fail("attachment", int64(a.ID), ErrRestoreUnmapped{"transaction", int64(id)})
+				continue
+			}
+			_, err := r.api.AssignAttachmentToTransaction(ctx, &pocketsmith.AssignAttachmentToTransactionOptions{
+				TransactionID: transactionID,
+				AttachmentID:  attachmentID,
+			})
+			if err != nil {
+				r.fail("attachment", int64(a.ID), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package pocketsmith
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// BulkOptions defines the options for running many requests at once.
+type BulkOptions struct {
+
+	// Workers is the number of requests sent at the same time. Defaults to 4.
+	Workers int
+
+	// Interval is the minimum time between starting requests, shared by every
+	// worker, to stay under the API rate limit. Defaults to 0 (no limit).
+	Interval time.Duration
+
+	// MaxRetries is the number of times a request is retried when the API
+	// responds with 429 Too Many Requests. Every worker pauses while waiting,
+	// doubling the wait after each retry. Defaults to 3.
+	MaxRetries int
+
+	// RetryWait is the time waited before the first retry. Defaults to 1s.
+	RetryWait time.Duration
+}
+
+// BulkResult defines the outcome of a single request in a bulk operation.
+type BulkResult struct {
+	Index       int          // The index of the options the request was sent with.
+	Transaction *Transaction // The transaction returned from the API, if successful.
+	Attempts    int          // The number of times the request was sent.
+	Err         error        // The error returned from the API, if any.
+}
+
+// BulkResults defines the outcomes of every request in a bulk operation, in
+// the same order as the options.
+type BulkResults []BulkResult
+
+// Succeeded returns the results of the requests that succeeded.
+func (r BulkResults) Succeeded() (out BulkResults) {
+	for _, result := range r {
+		if result.Err == nil {
+			out = append(out, result)
+		}
+	}
+	return out
+}
+
+// Failed returns the results of the requests that failed.
+func (r BulkResults) Failed() (out BulkResults) {
+	for _, result := range r {
+		if result.Err != nil {
+			out = append(out, result)
+		}
+	}
+	return out
+}
+
+// Err returns an ErrBulkFailed if any of the requests failed, or nil.
+func (r BulkResults) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return ErrBulkFailed{failed: len(failed), total: len(r), first: failed[0].Err}
+}
+
+// BulkUpdateTransactionsOptions defines the options for updating many
+// transactions in Pocketsmith.
+type BulkUpdateTransactionsOptions struct {
+	Transactions []*UpdateTransactionOptions `validator:"required"`
+	BulkOptions
+}
+
+// BulkUpdateTransactions updates many transactions in Pocketsmith, using a
+// pool of workers. A request that fails doesn't stop the others; its error is
+// recorded in its result. Nil options update nothing.
+func (c *Client) BulkUpdateTransactions(
+	ctx context.Context,
+	options *BulkUpdateTransactionsOptions,
+) BulkResults {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "BulkUpdateTransactions")
+	defer span.End()
+	if options == nil {
+		options = &BulkUpdateTransactionsOptions{}
+	}
+
+	// update transactions.
+	return c.bulk(newCtx, len(options.Transactions), &options.BulkOptions,
+		func(ctx context.Context, i int) (*Transaction, error) {
+			return c.UpdateTransaction(ctx, options.Transactions[i])
+		})
+}
+
+// BulkCreateTransactionsOptions defines the options for creating many
+// transactions in Pocketsmith.
+type BulkCreateTransactionsOptions struct {
+	Transactions []*CreateTransactionAccountTransactionOptions `validator:"required"`
+	BulkOptions
+}
+
+// BulkCreateTransactions creates many transactions in Pocketsmith, using a
+// pool of workers. A request that fails doesn't stop the others; its error is
+// recorded in its result. Nil options create nothing.
+//
+// NOTE: only requests the API rejected with 429 Too Many Requests are
+// retried, since retrying other failures could create a transaction twice.
+func (c *Client) BulkCreateTransactions(
+	ctx context.Context,
+	options *BulkCreateTransactionsOptions,
+) BulkResults {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "BulkCreateTransactions")
+	defer span.End()
+	if options == nil {
+		options = &BulkCreateTransactionsOptions{}
+	}
+
+	// create transactions.
+	return c.bulk(newCtx, len(options.Transactions), &options.BulkOptions,
+		func(ctx context.Context, i int) (*Transaction, error) {
+			return c.CreateTransactionAccountTransaction(ctx, options.Transactions[i])
+		})
+}
+
+// bulk runs the given function for each of the n items, using a pool of
+// workers, and returns the results in order.
+func (c *Client) bulk(
+	ctx context.Context,
+	n int,
+	options *BulkOptions,
+	fn func(ctx context.Context, i int) (*Transaction, error),
+) BulkResults {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "bulk")
+	defer span.End()
+
+	// default options.
+	o := *options
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 3
+	}
+	if o.RetryWait <= 0 {
+		o.RetryWait = time.Second
+	}
+
+	// setup the throttle shared by every worker; it spaces out requests, and
+	// pauses every worker when the API says there are too many requests.
+	t := &throttle{interval: o.Interval}
+
+	// run workers.
+	results := make(BulkResults, n)
+	items := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(o.Workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range items {
+				results[i] = BulkResult{Index: i}
+				wait := o.RetryWait
+				for {
+					if err := t.wait(newCtx); err != nil {
+						results[i].Err = ErrBulkCancelled{err}
+						break
+					}
+					results[i].Attempts++
+					results[i].Transaction, results[i].Err = fn(newCtx, i)
+					if !isTooManyRequests(results[i].Err) || results[i].Attempts > o.MaxRetries {
+						break
+					}
+					t.pause(wait)
+					wait *= 2
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		items <- i
+	}
+	close(items)
+	wg.Wait()
+
+	// record outcome.
+	failed := len(results.Failed())
+	span.SetAttributes(
+		attribute.Int("total", n),
+		attribute.Int("failed", failed),
+	)
+	if failed > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("%v of %v requests failed", failed, n))
+	}
+	return results
+}
+
+// throttle spaces out requests shared between many workers.
+type throttle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // When the next request can be sent.
+}
+
+// wait blocks until the next request can be sent, or the context is done.
+func (t *throttle) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	now := time.Now()
+	at := t.next
+	if at.Before(now) {
+		at = now
+	}
+	t.next = at.Add(t.interval)
+	t.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pause stops every worker from sending requests for the given duration.
+func (t *throttle) pause(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(d); until.After(t.next) {
+		t.next = until
+	}
+}
+
+// isTooManyRequests returns if the given error is the API responding with
+// 429 Too Many Requests.
+func isTooManyRequests(err error) bool {
+	var e ErrSenderInvalidResponse
+	return errors.As(err, &e) && e.StatusCode() == http.StatusTooManyRequests
+}
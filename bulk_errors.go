@@ -0,0 +1,31 @@
+package pocketsmith
+
+import (
+	"fmt"
+)
+
+// ErrBulkFailed is returned when one or more requests in a bulk operation
+// failed.
+type ErrBulkFailed struct {
+	failed int
+	total  int
+	first  error
+}
+
+func (e ErrBulkFailed) Error() string {
+	return fmt.Sprintf("%v of %v requests failed; first error: %v", e.failed, e.total, e.first)
+}
+
+// ErrBulkCancelled is returned for a request in a bulk operation that wasn't
+// sent, because the context was done.
+type ErrBulkCancelled struct {
+	err error
+}
+
+func (e ErrBulkCancelled) Error() string {
+	return fmt.Sprintf("request not sent: %v", e.err)
+}
+
+func (e ErrBulkCancelled) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,136 @@
+package pocketsmith
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_BulkUpdateTransactions(t *testing.T) {
+
+	// setup mock; transaction 2 is rate limited once, and transaction 3
+	// always fails.
+	var mu sync.Mutex
+	limited := false
+	c := newMockClient(func(req *http.Request) *http.Response {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/2") && !limited:
+			limited = true
+			return mockResponse(http.StatusTooManyRequests, `{"error":"slow down"}`)
+		case strings.HasSuffix(req.URL.Path, "/3"):
+			return mockResponse(http.StatusNotFound, `{"error":"not found"}`)
+		}
+		id := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+		return mockResponse(http.StatusOK, fmt.Sprintf(`{"id":%s}`, id))
+	})
+
+	// run tests.
+	options := &BulkUpdateTransactionsOptions{
+		BulkOptions: BulkOptions{Workers: 2, RetryWait: time.Millisecond},
+	}
+	for i := int32(1); i <= 5; i++ {
+		options.Transactions = append(options.Transactions, &UpdateTransactionOptions{
+			TransactionID: i,
+			Note:          "bulk",
+		})
+	}
+	results := c.BulkUpdateTransactions(context.Background(), options)
+	if len(results) != 5 || len(results.Succeeded()) != 4 || len(results.Failed()) != 1 {
+		t.Fatalf("BulkUpdateTransactions() returned unexpected results; got=%+v", results)
+	}
+	for i, r := range results {
+		if r.Index != i {
+			t.Errorf("BulkUpdateTransactions() returned results out of order; got=%+v", results)
+		}
+		if r.Err == nil && r.Transaction.ID != int32(i+1) {
+			t.Errorf("BulkUpdateTransactions() returned an unexpected transaction; got=%+v", r)
+		}
+	}
+	if results[1].Attempts != 2 {
+		t.Errorf("BulkUpdateTransactions() didn't retry a rate limited request; got=%+v", results[1])
+	}
+	var e ErrSenderInvalidResponse
+	if !errors.As(results[2].Err, &e) || e.StatusCode() != http.StatusNotFound || results[2].Attempts != 1 {
+		t.Errorf("BulkUpdateTransactions() returned an unexpected error; got=%+v", results[2])
+	}
+	if err := results.Err(); err == nil || !strings.Contains(err.Error(), "1 of 5 requests failed") {
+		t.Errorf("Err() returned an unexpected error; got=%v", err)
+	}
+
+	// nil options.
+	if results := c.BulkUpdateTransactions(context.Background(), nil); len(results) != 0 {
+		t.Errorf("BulkUpdateTransactions() returned unexpected results for nil options; got=%+v", results)
+	}
+
+	// cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = c.BulkUpdateTransactions(ctx, options)
+	if len(results.Failed()) != 5 || !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("BulkUpdateTransactions() sent requests after being cancelled; got=%+v", results)
+	}
+}
+
+func Test_BulkCreateTransactions(t *testing.T) {
+
+	// setup mock; "limited" is rate limited once, and "timeout" times out
+	// after the transaction may have been created.
+	var mu sync.Mutex
+	limited := false
+	attempts := make(map[string]int)
+	c := newMockClient(func(req *http.Request) *http.Response {
+		mu.Lock()
+		defer mu.Unlock()
+		var options CreateTransactionAccountTransactionOptions
+		json.NewDecoder(req.Body).Decode(&options)
+		attempts[options.Payee]++
+		switch {
+		case options.Payee == "limited" && !limited:
+			limited = true
+			return mockResponse(http.StatusTooManyRequests, `{"error":"slow down"}`)
+		case options.Payee == "timeout":
+			return mockResponse(http.StatusGatewayTimeout, `{"error":"timeout"}`)
+		}
+		return mockResponse(http.StatusOK, Transaction{ID: int32(attempts[options.Payee]), Payee: options.Payee})
+	})
+
+	// run tests.
+	options := &BulkCreateTransactionsOptions{
+		BulkOptions: BulkOptions{Workers: 2, RetryWait: time.Millisecond},
+	}
+	for _, payee := range []string{"ok", "limited", "timeout"} {
+		options.Transactions = append(options.Transactions, &CreateTransactionAccountTransactionOptions{
+			TransactionAccountID: 1,
+			Payee:                payee,
+			Amount:               -10,
+			Date:                 "2024-01-01",
+		})
+	}
+	results := c.BulkCreateTransactions(context.Background(), options)
+	if len(results) != 3 || len(results.Succeeded()) != 2 {
+		t.Fatalf("BulkCreateTransactions() returned unexpected results; got=%+v", results)
+	}
+	if results[1].Attempts != 2 || results[1].Transaction.Payee != "limited" {
+		t.Errorf("BulkCreateTransactions() didn't retry a rate limited request; got=%+v", results[1])
+	}
+	var e ErrSenderInvalidResponse
+	if !errors.As(results[2].Err, &e) || e.StatusCode() != http.StatusGatewayTimeout {
+		t.Errorf("BulkCreateTransactions() returned an unexpected error; got=%+v", results[2])
+	}
+	if results[2].Attempts != 1 || attempts["timeout"] != 1 {
+		t.Errorf("BulkCreateTransactions() retried a request that may have created a transaction; got=%+v", results[2])
+	}
+
+	// nil options.
+	if results := c.BulkCreateTransactions(context.Background(), nil); len(results) != 0 {
+		t.Errorf("BulkCreateTransactions() returned unexpected results for nil options; got=%+v", results)
+	}
+}
@@ -2,8 +2,10 @@ package pocketsmith
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -35,7 +37,7 @@ type CreateCategoryForUserOptions struct {
 func (c *Client) CreateCategoryForUser(
 	ctx context.Context,
 	options *CreateCategoryForUserOptions,
-) error {
+) (category *Category, err error) {
 
 	// setup tracing.
 	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "CreateCategoryForUser")
@@ -45,21 +47,21 @@ func (c *Client) CreateCategoryForUser(
 	if err := c.validator.StructCtx(newCtx, options); err != nil {
 		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
 		span.RecordError(err)
-		return err
+		return nil, err
 	}
 
 	// create category.
-	_, err := c.sender(newCtx, senderRequest{
+	_, err = c.sender(newCtx, senderRequest{
 		method: http.MethodPost,
 		path:   fmt.Sprintf("/users/%v/categories", options.UserID),
 		body:   options,
-	}, nil)
+	}, &category)
 	if err != nil {
 		span.SetStatus(codes.Error, fmt.Sprintf("failed to create category: %v", err))
 		span.RecordError(err)
-		return err
+		return nil, err
 	}
-	return nil
+	return category, nil
 }
 
 // CreateCategoryForAuthedUser, using the token attached to a client, creates a
@@ -67,7 +69,7 @@ func (c *Client) CreateCategoryForUser(
 func (c *Client) CreateCategory(
 	ctx context.Context,
 	options *CreateCategoryOptions,
-) error {
+) (*Category, error) {
 
 	// setup tracing.
 	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "CreateCategory")
@@ -163,7 +165,7 @@ func (c *Client) ListCategories(ctx context.Context) (Categories, error) {
 
 // GetCategoryByTitle ...
 type GetCategoryByTitleOptions struct {
-	Category string `validator:"required"`
+	Category string `validator:"required"` // The title of the category, or its path, eg. "Food/Groceries" or "/Food".
 }
 
 // GetCategoryByTitleOptions ...
@@ -174,7 +176,10 @@ type GetCategoryByTitleForUserOptions struct {
 }
 
 // GetCategoryByTitleForUser, using the given user id and category, returns the found
-// category for a user.
+// category for a user. The category is found at any depth in the tree, by its
+// title, or by its path (eg. "Food/Groceries"); when more than one category
+// has the title, an ErrCategoryAmbiguousTitle is returned, and a path picks
+// one. A top level category is picked with a leading "/", eg. "/Food".
 func (c *Client) GetCategoryByTitleForUser(
 	ctx context.Context,
 	options *GetCategoryByTitleForUserOptions,
@@ -201,19 +206,35 @@ func (c *Client) GetCategoryByTitleForUser(
 		span.RecordError(err)
 		return nil, err
 	}
-	for _, c := range categories {
-		if c.Title == options.Category {
-			return &c, nil
+	category, err := findCategory(NewCategoryTree(categories), options.Category, options.UserID)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to find category by title: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return category, nil
+}
+
+// findCategory returns the category in the given tree with the given path,
+// when it contains a "/", or the only category with the given title.
+func findCategory(tree *CategoryTree, title string, userID int) (*Category, error) {
+	if strings.Contains(title, "/") {
+		if c, ok := tree.ByPath(title); ok {
+			return c, nil
 		}
 	}
-	err = fmt.Errorf(
-		"category with title %q for user %v doesn't exist",
-		options.Category,
-		options.UserID,
-	)
-	span.SetStatus(codes.Error, fmt.Sprintf("failed to find category by title: %v", err))
-	span.RecordError(err)
-	return nil, err
+	found := tree.ByTitle(title)
+	switch len(found) {
+	case 0:
+		return nil, ErrCategoryNotFound{title, userID}
+	case 1:
+		return found[0], nil
+	}
+	paths := make([]string, len(found))
+	for i, c := range found {
+		paths[i] = tree.Path(c.ID)
+	}
+	return nil, ErrCategoryAmbiguousTitle{title, userID, paths}
 }
 
 // GetCategoryByTitle, using the token attached to the client,
@@ -236,3 +257,100 @@ func (c *Client) GetCategoryByTitle(
 		},
 	)
 }
+
+// GetCategoryOptions defines the options for getting a category.
+type GetCategoryOptions struct {
+	CategoryID int32 `json:"-" validator:"required"`
+}
+
+// GetCategory gets a category from Pocketsmith, by the category id.
+// https://developers.pocketsmith.com/reference/get_categories-id-1.
+func (c *Client) GetCategory(
+	ctx context.Context,
+	options *GetCategoryOptions,
+) (category *Category, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "GetCategory")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// get category.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/categories/%v", options.CategoryID),
+	}, &category)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to get category: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategoryOptions defines the options for updating a category. Fields
+// left empty are unchanged.
+type UpdateCategoryOptions struct {
+	CategoryID      int32  `json:"-"                          validator:"required"`
+	Title           string `json:"title,omitempty"`
+	Colour          string `json:"colour,omitempty"`
+	ParentID        *int32 `json:"parent_id,omitempty"`
+	IsTransfer      *bool  `json:"is_transfer,omitempty"`
+	IsBill          *bool  `json:"is_bill,omitempty"`
+	RollUp          *bool  `json:"roll_up,omitempty"`
+	RefundBehaviour string `json:"refund_behaviour,omitempty"`
+
+	// TopLevel moves the category to the top level, removing its parent.
+	TopLevel bool `json:"-"`
+}
+
+// MarshalJSON marshals the options, sending a null parent when the category
+// is moved to the top level.
+func (o UpdateCategoryOptions) MarshalJSON() ([]byte, error) {
+	type alias UpdateCategoryOptions
+	if !o.TopLevel {
+		return json.Marshal(alias(o))
+	}
+	return json.Marshal(struct {
+		alias
+		ParentID *int32 `json:"parent_id"`
+	}{alias: alias(o)})
+}
+
+// UpdateCategory updates a category in Pocketsmith, by the category id.
+// https://developers.pocketsmith.com/reference/put_categories-id-1.
+func (c *Client) UpdateCategory(
+	ctx context.Context,
+	options *UpdateCategoryOptions,
+) (category *Category, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "UpdateCategory")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// update category.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/categories/%v", options.CategoryID),
+		body:   options,
+	}, &category)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to update category: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return category, nil
+}
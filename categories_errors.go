@@ -0,0 +1,35 @@
+package pocketsmith
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrCategoryNotFound is returned when no category has the given title, or
+// path.
+type ErrCategoryNotFound struct {
+	title  string
+	userID int
+}
+
+func (e ErrCategoryNotFound) Error() string {
+	return fmt.Sprintf("category with title %q for user %v doesn't exist", e.title, e.userID)
+}
+
+// ErrCategoryAmbiguousTitle is returned when more than one category has the
+// given title, under different parents.
+type ErrCategoryAmbiguousTitle struct {
+	title  string
+	userID int
+	paths  []string
+}
+
+func (e ErrCategoryAmbiguousTitle) Error() string {
+	return fmt.Sprintf(
+		"%v categories with title %q for user %v exist (%s); use a path to pick one",
+		len(e.paths),
+		e.title,
+		e.userID,
+		strings.Join(e.paths, ", "),
+	)
+}
@@ -0,0 +1,13 @@
+package pocketsmith
+
+import "time"
+
+// CategoryRule defines a PocketSmith category rule; transactions with a payee
+// matching the rule are put into its category.
+type CategoryRule struct {
+	ID           int32     `json:"id"`
+	Category     Category  `json:"category"`
+	PayeeMatches string    `json:"payee_matches"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,93 @@
+package pocketsmith
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// CategoryRules represents a slice of CategoryRule.
+type CategoryRules []CategoryRule
+
+// ListCategoryRulesForUserOptions defines the options for listing the
+// category rules for a user, by the user id.
+type ListCategoryRulesForUserOptions struct {
+	UserID int `json:"-" validator:"required"`
+}
+
+// ListCategoryRulesForUser, using the given user id, lists the category rules
+// for a user.
+// https://developers.pocketsmith.com/reference/get_users-id-category-rules-1.
+func (c *Client) ListCategoryRulesForUser(
+	ctx context.Context,
+	options *ListCategoryRulesForUserOptions,
+) (rules CategoryRules, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListCategoryRulesForUser")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// list category rules.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/users/%v/category_rules", options.UserID),
+	}, &rules)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to list category rules: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+// CreateCategoryRuleOptions defines the options for creating a category rule
+// for a category, by the category id.
+type CreateCategoryRuleOptions struct {
+	CategoryID           int32  `json:"-"                                validator:"required"`
+	PayeeMatches         string `json:"payee_matches"                    validator:"required"`
+	ApplyToUncategorised bool   `json:"apply_to_uncategorised,omitempty"` // Also categorise existing uncategorised transactions.
+	ApplyToAll           bool   `json:"apply_to_all,omitempty"`           // Also recategorise every existing matching transaction.
+}
+
+// CreateCategoryRule creates a category rule for a category, by the category
+// id.
+// https://developers.pocketsmith.com/reference/post_categories-id-category-rules-1.
+func (c *Client) CreateCategoryRule(
+	ctx context.Context,
+	options *CreateCategoryRuleOptions,
+) (rule *CategoryRule, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "CreateCategoryRule")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// create category rule.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodPost,
+		path:   fmt.Sprintf("/categories/%v/category_rules", options.CategoryID),
+		body:   options,
+	}, &rule)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to create category rule: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return rule, nil
+}
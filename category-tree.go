@@ -0,0 +1,210 @@
+package pocketsmith
+
+import "strings"
+
+// CategoryTree indexes a tree of categories, as returned from
+// ListCategoriesForUser, so that categories can be looked up by id, title or
+// path (eg. "Food/Groceries"), and their ancestors and descendants found,
+// without walking the tree each time.
+//
+// The categories can be nested, as returned from the API, or flat, where each
+// category lists its parent by ParentID; a category whose ParentID isn't in
+// the given categories is treated as top level.
+//
+// Categories are walked once, when the tree is built; a category that
+// appears more than once (eg. a malformed tree where a category is its own
+// descendant) is only indexed the first time it's seen, so a tree can never
+// loop forever. The categories returned point into the given Categories.
+type CategoryTree struct {
+	roots    []*Category
+	order    []*Category // Every category, parents before their children.
+	byID     map[int32]*Category
+	byTitle  map[string][]*Category
+	byPath   map[string]*Category
+	parents  map[int32]*Category // The parent of each category, by id; nil for the top level.
+	paths    map[int32]string
+	depths   map[int32]int
+	children map[int32][]*Category // The children indexed for each category, by id.
+}
+
+// FlatCategory defines a category flattened from a CategoryTree, with its
+// path and depth; top level categories have a depth of 0.
+type FlatCategory struct {
+	*Category
+	Path  string
+	Depth int
+}
+
+// NewCategoryTree builds a CategoryTree from the given categories.
+func NewCategoryTree(categories Categories) *CategoryTree {
+	t := &CategoryTree{
+		byID:     make(map[int32]*Category),
+		byTitle:  make(map[string][]*Category),
+		byPath:   make(map[string]*Category),
+		parents:  make(map[int32]*Category),
+		paths:    make(map[int32]string),
+		depths:   make(map[int32]int),
+		children: make(map[int32][]*Category),
+	}
+
+	// place the categories listed with a ParentID under their parent.
+	listed := make(map[int32]*Category, len(categories))
+	for i := range categories {
+		if _, ok := listed[categories[i].ID]; !ok {
+			listed[categories[i].ID] = &categories[i]
+		}
+	}
+	adopted := make(map[int32][]*Category)
+	var roots []*Category
+	for i := range categories {
+		c := &categories[i]
+		if c.ParentID != nil {
+			if p, ok := listed[int32(*c.ParentID)]; ok && p != c {
+				adopted[p.ID] = append(adopted[p.ID], c)
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+
+	var visit func(c, parent *Category, path string, depth int) bool
+	visit = func(c, parent *Category, path string, depth int) bool {
+		if _, ok := t.byID[c.ID]; ok {
+			return false
+		}
+		if path != "" {
+			path += "/"
+		}
+		path += c.Title
+		t.order = append(t.order, c)
+		t.byID[c.ID] = c
+		t.byTitle[c.Title] = append(t.byTitle[c.Title], c)
+		if _, ok := t.byPath[path]; !ok {
+			t.byPath[path] = c
+		}
+		t.parents[c.ID] = parent
+		t.paths[c.ID] = path
+		t.depths[c.ID] = depth
+		for _, children := range [][]*Category{c.Children, adopted[c.ID]} {
+			for _, child := range children {
+				if child != nil && visit(child, c, path, depth+1) {
+					t.children[c.ID] = append(t.children[c.ID], child)
+				}
+			}
+		}
+		return true
+	}
+	for _, c := range roots {
+		if visit(c, nil, "", 0) {
+			t.roots = append(t.roots, c)
+		}
+	}
+
+	// categories whose parents list each other are never reached from the top
+	// level, so they're indexed as top level instead.
+	for i := range categories {
+		if _, ok := t.byID[categories[i].ID]; !ok && visit(&categories[i], nil, "", 0) {
+			t.roots = append(t.roots, &categories[i])
+		}
+	}
+	return t
+}
+
+// Len returns the number of categories in the tree.
+func (t *CategoryTree) Len() int {
+	return len(t.order)
+}
+
+// Roots returns the top level categories in the tree.
+func (t *CategoryTree) Roots() []*Category {
+	return t.roots
+}
+
+// ByID returns the category with the given id.
+func (t *CategoryTree) ByID(id int32) (*Category, bool) {
+	c, ok := t.byID[id]
+	return c, ok
+}
+
+// ByTitle returns the categories with the given title, at any depth, parents
+// before their children. Titles are only unique among siblings, so more than
+// one category can be returned.
+func (t *CategoryTree) ByTitle(title string) []*Category {
+	return t.byTitle[title]
+}
+
+// ByPath returns the category at the given path, eg. "Food/Groceries".
+func (t *CategoryTree) ByPath(path string) (*Category, bool) {
+	c, ok := t.byPath[strings.Trim(path, "/")]
+	return c, ok
+}
+
+// Path returns the path of the category with the given id, eg.
+// "Food/Groceries", or an empty string if it isn't in the tree.
+func (t *CategoryTree) Path(id int32) string {
+	return t.paths[id]
+}
+
+// Depth returns the depth of the category with the given id; top level
+// categories have a depth of 0.
+func (t *CategoryTree) Depth(id int32) int {
+	return t.depths[id]
+}
+
+// Parent returns the parent of the category with the given id, or false if
+// it's a top level category, or isn't in the tree.
+func (t *CategoryTree) Parent(id int32) (*Category, bool) {
+	p := t.parents[id]
+	return p, p != nil
+}
+
+// Ancestors returns the ancestors of the category with the given id, nearest
+// first; its parent, then its grandparent, and so on.
+func (t *CategoryTree) Ancestors(id int32) (ancestors []*Category) {
+	for p := t.parents[id]; p != nil; p = t.parents[p.ID] {
+		ancestors = append(ancestors, p)
+	}
+	return ancestors
+}
+
+// Descendants returns the descendants of the category with the given id,
+// parents before their children.
+func (t *CategoryTree) Descendants(id int32) (descendants []*Category) {
+	var visit func(id int32)
+	visit = func(id int32) {
+		for _, c := range t.children[id] {
+			descendants = append(descendants, c)
+			visit(c.ID)
+		}
+	}
+	visit(id)
+	return descendants
+}
+
+// Walk calls fn with each category in the tree, and its depth, parents before
+// their children. If fn returns false, the children of that category are
+// skipped.
+func (t *CategoryTree) Walk(fn func(c *Category, depth int) bool) {
+	var visit func(c *Category)
+	visit = func(c *Category) {
+		if !fn(c, t.depths[c.ID]) {
+			return
+		}
+		for _, child := range t.children[c.ID] {
+			visit(child)
+		}
+	}
+	for _, c := range t.roots {
+		visit(c)
+	}
+}
+
+// Flatten returns every category in the tree, with its path and depth,
+// parents before their children.
+func (t *CategoryTree) Flatten() []FlatCategory {
+	out := make([]FlatCategory, len(t.order))
+	for i, c := range t.order {
+		out[i] = FlatCategory{Category: c, Path: t.paths[c.ID], Depth: t.depths[c.ID]}
+	}
+	return out
+}
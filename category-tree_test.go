@@ -0,0 +1,177 @@
+package pocketsmith
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testCategories returns a category tree of Food/Groceries, Food/Takeaway,
+// Gifts/Food and Misc, where Misc is malformed and lists itself as a child.
+func testCategories() Categories {
+	misc := &Category{ID: 6, Title: "Misc"}
+	misc.Children = []*Category{misc}
+	return Categories{
+		{ID: 1, Title: "Food", Children: []*Category{
+			{ID: 2, Title: "Groceries"},
+			{ID: 3, Title: "Takeaway"},
+		}},
+		{ID: 4, Title: "Gifts", Children: []*Category{
+			{ID: 5, Title: "Food"},
+		}},
+		*misc,
+	}
+}
+
+// ids returns the ids of the given categories.
+func ids(categories []*Category) (out []int32) {
+	for _, c := range categories {
+		out = append(out, c.ID)
+	}
+	return out
+}
+
+func Test_CategoryTree(t *testing.T) {
+	tree := NewCategoryTree(testCategories())
+
+	// run tests.
+	if got := tree.Len(); got != 6 {
+		t.Errorf("Len() returned %v, want 6", got)
+	}
+	if c, ok := tree.ByID(3); !ok || c.Title != "Takeaway" {
+		t.Errorf("ByID(3) returned %v, %v", c, ok)
+	}
+	if c, ok := tree.ByPath("Gifts/Food"); !ok || c.ID != 5 {
+		t.Errorf("ByPath(Gifts/Food) returned %v, %v", c, ok)
+	}
+	if _, ok := tree.ByPath("Gifts/Groceries"); ok {
+		t.Errorf("ByPath(Gifts/Groceries) found a category")
+	}
+	if got := ids(tree.ByTitle("Food")); !reflect.DeepEqual(got, []int32{1, 5}) {
+		t.Errorf("ByTitle(Food) returned %v", got)
+	}
+	if got := tree.Path(2); got != "Food/Groceries" {
+		t.Errorf("Path(2) returned %q", got)
+	}
+	if p, ok := tree.Parent(5); !ok || p.ID != 4 {
+		t.Errorf("Parent(5) returned %v, %v", p, ok)
+	}
+	if _, ok := tree.Parent(4); ok {
+		t.Errorf("Parent(4) returned a parent for a top level category")
+	}
+	if got := ids(tree.Ancestors(2)); !reflect.DeepEqual(got, []int32{1}) {
+		t.Errorf("Ancestors(2) returned %v", got)
+	}
+	if got := ids(tree.Descendants(1)); !reflect.DeepEqual(got, []int32{2, 3}) {
+		t.Errorf("Descendants(1) returned %v", got)
+	}
+	if got := ids(tree.Descendants(6)); got != nil {
+		t.Errorf("Descendants(6) returned %v for a category listing itself", got)
+	}
+
+	// walk, skipping the children of Food.
+	var walked []int32
+	tree.Walk(func(c *Category, _ int) bool {
+		walked = append(walked, c.ID)
+		return c.ID != 1
+	})
+	if want := []int32{1, 4, 5, 6}; !reflect.DeepEqual(walked, want) {
+		t.Errorf("Walk() walked %v, want %v", walked, want)
+	}
+
+	// flatten.
+	var got []string
+	for _, f := range tree.Flatten() {
+		got = append(got, fmt.Sprintf("%s:%v", f.Path, f.Depth))
+	}
+	want := []string{"Food:0", "Food/Groceries:1", "Food/Takeaway:1", "Gifts:0", "Gifts/Food:1", "Misc:0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten() returned %q, want %q", got, want)
+	}
+}
+
+func Test_CategoryTree_flat(t *testing.T) {
+	parent := func(id int) *int { return &id }
+	tree := NewCategoryTree(Categories{
+		{ID: 2, Title: "Groceries", ParentID: parent(1)},
+		{ID: 1, Title: "Food"},
+		{ID: 3, Title: "Orphan", ParentID: parent(9)},
+		{ID: 4, Title: "Loop A", ParentID: parent(5)},
+		{ID: 5, Title: "Loop B", ParentID: parent(4)},
+	})
+
+	// run tests.
+	var got []string
+	for _, f := range tree.Flatten() {
+		got = append(got, fmt.Sprintf("%s:%v", f.Path, f.Depth))
+	}
+	want := []string{"Food:0", "Food/Groceries:1", "Orphan:0", "Loop A:0", "Loop A/Loop B:1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten() returned %q, want %q", got, want)
+	}
+	if p, ok := tree.Parent(2); !ok || p.ID != 1 {
+		t.Errorf("Parent(2) returned %v, %v", p, ok)
+	}
+}
+
+func Test_findCategory(t *testing.T) {
+	tests := map[string]struct {
+		title string
+		want  int32
+		err   string
+	}{
+		"nested title": {
+			title: "Groceries",
+			want:  2,
+		},
+		"path": {
+			title: "Gifts/Food",
+			want:  5,
+		},
+		"top level path": {
+			title: "/Food",
+			want:  1,
+		},
+		"top level title shared with a nested category": {
+			title: "Food",
+			err:   `2 categories with title "Food" for user 1 exist (Food, Gifts/Food); use a path to pick one`,
+		},
+		"path not found": {
+			title: "Gifts/Groceries",
+			err:   `category with title "Gifts/Groceries" for user 1 doesn't exist`,
+		},
+		"not found": {
+			title: "Fuel",
+			err:   `category with title "Fuel" for user 1 doesn't exist`,
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := findCategory(NewCategoryTree(testCategories()), tt.title, 1)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("findCategory() returned unexpected error; got: %v, want: %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findCategory() returned an error: %v", err)
+			}
+			if got.ID != tt.want {
+				t.Errorf("findCategory() returned category %v, want %v", got.ID, tt.want)
+			}
+		})
+	}
+
+	// a title shared by nested categories is ambiguous.
+	categories := testCategories()
+	categories[0].Children = append(categories[0].Children, &Category{ID: 7, Title: "Cafe"})
+	categories[1].Children = append(categories[1].Children, &Category{ID: 8, Title: "Cafe"})
+	_, err := findCategory(NewCategoryTree(categories), "Cafe", 1)
+	want := `2 categories with title "Cafe" for user 1 exist (Food/Cafe, Gifts/Cafe); use a path to pick one`
+	if err == nil || err.Error() != want {
+		t.Errorf("findCategory() returned unexpected error; got: %v, want: %v", err, want)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"go.opentelemetry.io/otel"
+	"golang.org/x/sync/singleflight"
 )
 
 // An iHttpClient is an interface over http.Client.
@@ -22,9 +23,11 @@ type Client struct {
 	tracerName string // The name of the tracer output in the traces.
 
 	// config.
-	endpoint   string      // The endpoint to query against.
-	httpClient iHttpClient // The http client used when sending / receiving data from the endpoint.
-	headers    http.Header // The headers passed to the http client when sending / receiving data from the endpoint.
+	endpoint   string             // The endpoint to query against.
+	httpClient iHttpClient        // The http client used when sending / receiving data from the endpoint.
+	headers    http.Header        // The headers passed to the http client when sending / receiving data from the endpoint.
+	inflight   singleflight.Group // The in-flight GET requests, used to coalesce identical concurrent requests.
+	ledger     IdempotencyLedger  // The ledger of idempotency keys; if nil, keys are stored in transaction notes.
 
 	// misc.
 	logLevel  slog.Level          // The log level of the default logger.
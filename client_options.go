@@ -28,3 +28,13 @@ func WithHttpClient(httpClient iHttpClient) Option {
 		return nil
 	}
 }
+
+// WithIdempotencyLedger stores the idempotency keys of transactions created
+// with CreateTransactionAccountTransactionOptions.Idempotent in the given
+// ledger, rather than in the note of each transaction.
+func WithIdempotencyLedger(ledger IdempotencyLedger) Option {
+	return func(c *Client) error {
+		c.ledger = ledger
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// accountColumns are the columns written for accounts.
+var accountColumns = []column[pocketsmith.Account]{
+	{"id", func(a pocketsmith.Account) string { return formatID(a.ID) }},
+	{"title", func(a pocketsmith.Account) string { return a.Title }},
+	{"type", func(a pocketsmith.Account) string { return string(a.Type) }},
+	{"currency", func(a pocketsmith.Account) string { return a.CurrencyCode }},
+	{"balance", func(a pocketsmith.Account) string { return formatAmount(a.CurrentBalance) }},
+	{"net_worth", func(a pocketsmith.Account) string { return strconv.FormatBool(a.IsNetWorth) }},
+}
+
+// runAccounts runs the accounts command.
+func runAccounts(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("accounts " + verb)
+	title := fs.String("title", "", "the title of the account")
+	currency := fs.String("currency", "", "the currency code of the account, eg. aud")
+	accountType := fs.String("type", "", "the type of the account, eg. bank")
+	institutionID := fs.Int("institution", 0, "the id of the institution the account belongs to")
+	isNetWorth := fs.Bool("net-worth", false, "if the account is included in net worth")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if *accountType != "" && !pocketsmith.AccountType(*accountType).Valid() {
+		return ErrInvalidAccountType{*accountType}
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch verb {
+	case "list":
+		var accounts pocketsmith.Accounts
+		if *institutionID != 0 {
+			accounts, err = c.ListInstitutionAccounts(
+				ctx,
+				&pocketsmith.ListInstitutionAccountsOptions{InstitutionID: *institutionID},
+			)
+		} else {
+			accounts, err = c.ListAccounts(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		return writeList(h.stdout, h.output, accounts, accountColumns)
+
+	case "get":
+		accountID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		a, err := c.GetAccount(ctx, &pocketsmith.GetAccountOptions{AccountID: accountID})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *a, accountColumns)
+
+	case "create":
+		if err := required(fs, "title", "currency", "type", "institution"); err != nil {
+			return err
+		}
+		a, err := c.CreateAccount(ctx, &pocketsmith.CreateAccountOptions{
+			InstitutionID: *institutionID,
+			Title:         *title,
+			CurrencyCode:  *currency,
+			Type:          pocketsmith.AccountType(*accountType),
+		})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *a, accountColumns)
+
+	case "update":
+		accountID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		options := &pocketsmith.UpdateAccountOptions{
+			AccountID:    accountID,
+			Title:        *title,
+			CurrencyCode: *currency,
+			Type:         pocketsmith.AccountType(*accountType),
+		}
+		if isSet(fs, "net-worth") {
+			options.IsNetWorth = isNetWorth
+		}
+		a, err := c.UpdateAccount(ctx, options)
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *a, accountColumns)
+
+	case "reorder":
+		if len(positional) == 0 {
+			return ErrMissingID{}
+		}
+		var ids []int
+		for _, p := range positional {
+			accountID, err := id([]string{p})
+			if err != nil {
+				return err
+			}
+			ids = append(ids, accountID)
+		}
+		accounts, err := c.UpdateAccountsDisplayOrder(
+			ctx,
+			&pocketsmith.UpdateAccountsDisplayOrderOptions{AccountIDs: ids},
+		)
+		if err != nil {
+			return err
+		}
+		return writeList(h.stdout, h.output, accounts, accountColumns)
+
+	case "delete":
+		accountID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		return c.DeleteAccount(ctx, &pocketsmith.DeleteAccountOptions{AccountID: accountID})
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// attachmentColumns are the columns written for attachments.
+var attachmentColumns = []column[pocketsmith.Attachment]{
+	{"id", func(a pocketsmith.Attachment) string { return formatID(a.ID) }},
+	{"title", func(a pocketsmith.Attachment) string { return a.Title }},
+	{"file_name", func(a pocketsmith.Attachment) string { return a.FileName }},
+	{"content_type", func(a pocketsmith.Attachment) string { return a.ContentType }},
+	{"created_at", func(a pocketsmith.Attachment) string { return a.CreatedAt.Format("2006-01-02") }},
+}
+
+// runAttachments runs the attachments command.
+func runAttachments(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("attachments " + verb)
+	title := fs.String("title", "", "the title of the attachment")
+	file := fs.String("file", "", "when creating, the path to the file to upload")
+	transactionID := fs.Int("transaction", 0, "when listing, only attachments assigned to the transaction, or when creating, the transaction to assign the attachment to")
+	unassigned := fs.Bool("unassigned", false, "when listing, only attachments not assigned to a transaction")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch verb {
+	case "list":
+		var attachments pocketsmith.Attachments
+		if *transactionID != 0 {
+			attachments, err = c.ListTransactionAttachments(ctx, &pocketsmith.ListTransactionAttachmentsOptions{
+				TransactionID: int32(*transactionID),
+			})
+		} else {
+			options := &pocketsmith.ListAttachmentsOptions{}
+			if *unassigned {
+				options.Unassigned = 1
+			}
+			attachments, err = c.ListAttachments(ctx, options)
+		}
+		if err != nil {
+			return err
+		}
+		return writeList(h.stdout, h.output, attachments, attachmentColumns)
+
+	case "get":
+		attachmentID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		a, err := c.GetAttachment(ctx, &pocketsmith.GetAttachmentOptions{AttachmentID: attachmentID})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *a, attachmentColumns)
+
+	case "create":
+		if err := required(fs, "file"); err != nil {
+			return err
+		}
+		b, err := os.ReadFile(*file)
+		if err != nil {
+			return err
+		}
+		if *title == "" {
+			*title = filepath.Base(*file)
+		}
+		a, err := c.CreateAttachment(ctx, &pocketsmith.CreateAttachmentOptions{
+			Title:    *title,
+			FileName: filepath.Base(*file),
+			FileData: base64.StdEncoding.EncodeToString(b),
+		})
+		if err != nil {
+			return err
+		}
+		if *transactionID != 0 {
+			a, err = c.AssignAttachmentToTransaction(ctx, &pocketsmith.AssignAttachmentToTransactionOptions{
+				TransactionID: int32(*transactionID),
+				AttachmentID:  a.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return writeOne(h.stdout, h.output, *a, attachmentColumns)
+
+	case "update":
+		attachmentID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		a, err := c.UpdateAttachment(ctx, &pocketsmith.UpdateAttachmentOptions{
+			AttachmentID: attachmentID,
+			Title:        *title,
+		})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *a, attachmentColumns)
+
+	case "delete":
+		attachmentID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		return c.DeleteAttachment(ctx, &pocketsmith.DeleteAttachmentOptions{AttachmentID: attachmentID})
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jmpa-io/pocketsmith-go/backup"
+)
+
+// runBackup runs the backup command.
+func runBackup(ctx context.Context, h *handler, _ string, args []string) error {
+	fs := h.flagSet("backup")
+	file := fs.String("file", "", "the path to write the archive to, eg. pocketsmith.tar.gz")
+	userID := fs.Int("user", 0, "the id of the user to backup (default the authed user)")
+	attachments := fs.Bool("attachments", false, "include the attachment metadata; one request per transaction")
+	files := fs.Bool("files", false, "include the attachment files; implies --attachments")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := required(fs, "file"); err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+	if *userID == 0 {
+		u, err := c.GetAuthedUser(ctx)
+		if err != nil {
+			return err
+		}
+		*userID = u.ID
+	}
+
+	// backup, then write the archive.
+	archive, err := backup.Backup(ctx, c, *userID, &backup.Options{
+		Attachments: *attachments,
+		Files:       *files,
+	})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(*file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := backup.WriteArchive(f, archive); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(
+		h.stdout,
+		"backed up %v accounts, %v categories, %v transactions and %v attachments to %s\n",
+		len(archive.Accounts),
+		len(flattenCategories(archive.Categories)),
+		len(archive.Transactions),
+		len(archive.Attachments),
+		*file,
+	)
+	return err
+}
+
+// runRestore runs the restore command.
+func runRestore(ctx context.Context, h *handler, _ string, args []string) error {
+	fs := h.flagSet("restore")
+	file := fs.String("file", "", "the path to the archive to restore")
+	userID := fs.Int("user", 0, "the id of the user to restore into (default the authed user)")
+	resumable := fs.Bool("resumable", false, "reuse restored accounts & attachments, and create transactions idempotently, so an interrupted restore can be run again")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := required(fs, "file"); err != nil {
+		return err
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	archive, err := backup.ReadArchive(f)
+	if err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+	if *userID == 0 {
+		u, err := c.GetAuthedUser(ctx)
+		if err != nil {
+			return err
+		}
+		*userID = u.ID
+	}
+
+	// restore, reporting anything that couldn't be restored.
+	result, err := backup.Restore(ctx, c, archive, *userID, &backup.RestoreOptions{Resumable: *resumable})
+	if err != nil {
+		return err
+	}
+	for _, f := range result.Failures {
+		fmt.Fprintf(h.stderr, "failed to restore %s\n", f)
+	}
+	_, err = fmt.Fprintf(
+		h.stdout,
+		"restored %v accounts, %v categories, %v transactions and %v attachments; %v failed\n",
+		len(result.IDs.Accounts),
+		len(result.IDs.Categories),
+		len(result.IDs.Transactions),
+		len(result.IDs.Attachments),
+		len(result.Failures),
+	)
+	return err
+}
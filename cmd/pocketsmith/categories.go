@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/internal/atomicfile"
+)
+
+// categoryRow defines a category, flattened from the category tree.
+type categoryRow struct {
+	pocketsmith.Category
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+}
+
+// categoryColumns are the columns written for categories.
+var categoryColumns = []column[categoryRow]{
+	{"id", func(c categoryRow) string { return formatID(c.ID) }},
+	{"title", func(c categoryRow) string { return strings.Repeat("  ", c.Depth) + c.Title }},
+	{"path", func(c categoryRow) string { return c.Path }},
+	{"colour", func(c categoryRow) string { return c.Colour }},
+	{"transfer", func(c categoryRow) string { return strconv.FormatBool(c.IsTransfer) }},
+}
+
+// flattenCategories returns the given category tree as rows, depth first.
+func flattenCategories(categories pocketsmith.Categories) []categoryRow {
+	flat := pocketsmith.NewCategoryTree(categories).Flatten()
+	rows := make([]categoryRow, len(flat))
+	for i, f := range flat {
+		c := *f.Category
+		c.Children = nil
+		rows[i] = categoryRow{Category: c, Path: f.Path, Depth: f.Depth}
+	}
+	return rows
+}
+
+// runCategories runs the categories command.
+func runCategories(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("categories " + verb)
+	title := fs.String("title", "", "the title of the category")
+	colour := fs.String("colour", "", "the colour of the category, eg. #ff0000")
+	parentID := fs.Int("parent", 0, "the id of the parent category")
+	isTransfer := fs.Bool("transfer", false, "if the category is for transfers")
+	isBill := fs.Bool("bill", false, "if the category is for bills")
+	rollUp := fs.Bool("roll-up", false, "if the category rolls up into its parent")
+	targetID := fs.Int("target", 0, "when merging, the id of the category to merge into")
+	progress := fs.String("progress", "", "when merging, the path to save progress to, and resume from, eg. merge.json")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch verb {
+	case "list":
+		categories, err := c.ListCategories(ctx)
+		if err != nil {
+			return err
+		}
+		return writeList(h.stdout, h.output, flattenCategories(categories), categoryColumns)
+
+	case "get":
+		categoryID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		category, err := c.GetCategory(ctx, &pocketsmith.GetCategoryOptions{CategoryID: int32(categoryID)})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, categoryRow{Category: *category, Path: category.Title}, categoryColumns)
+
+	case "create":
+		if err := required(fs, "title"); err != nil {
+			return err
+		}
+		options := &pocketsmith.CreateCategoryOptions{
+			Title:      *title,
+			Colour:     *colour,
+			IsTransfer: *isTransfer,
+			IsBill:     *isBill,
+			RollUp:     *rollUp,
+		}
+		if *parentID != 0 {
+			options.ParentID = strconv.Itoa(*parentID)
+		}
+		category, err := c.CreateCategory(ctx, options)
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, categoryRow{Category: *category, Path: category.Title}, categoryColumns)
+
+	case "update":
+		categoryID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		options := &pocketsmith.UpdateCategoryOptions{
+			CategoryID: int32(categoryID),
+			Title:      *title,
+			Colour:     *colour,
+		}
+		if isSet(fs, "parent") {
+			if *parentID == 0 {
+				options.TopLevel = true
+			} else {
+				parent := int32(*parentID)
+				options.ParentID = &parent
+			}
+		}
+		if isSet(fs, "transfer") {
+			options.IsTransfer = isTransfer
+		}
+		if isSet(fs, "bill") {
+			options.IsBill = isBill
+		}
+		if isSet(fs, "roll-up") {
+			options.RollUp = rollUp
+		}
+		category, err := c.UpdateCategory(ctx, options)
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, categoryRow{Category: *category, Path: category.Title}, categoryColumns)
+
+	case "delete":
+		categoryID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		return c.DeleteCategory(ctx, &pocketsmith.DeleteCategoryOptions{CategoryID: int32(categoryID)})
+
+	case "merge":
+		categoryID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		if err := required(fs, "target"); err != nil {
+			return err
+		}
+		u, err := c.GetAuthedUser(ctx)
+		if err != nil {
+			return err
+		}
+		options := &pocketsmith.MergeCategoriesOptions{
+			UserID:   u.ID,
+			SourceID: int32(categoryID),
+			TargetID: int32(*targetID),
+		}
+		if *progress != "" {
+			if options.Resume, err = loadMergeReport(*progress); err != nil {
+				return err
+			}
+			options.Progress = func(r *pocketsmith.MergeCategoriesReport) {
+				if err := saveMergeReport(*progress, r); err != nil {
+					fmt.Fprintf(h.stderr, "failed to save progress: %v\n", err)
+				}
+			}
+		}
+		report, err := c.MergeCategories(ctx, options)
+		if report != nil {
+			fmt.Fprintf(
+				h.stdout,
+				"moved %v transactions, %v children and %v rules; %v transactions failed; stage %s\n",
+				len(report.Transactions),
+				len(report.Children),
+				len(report.Rules),
+				len(report.Failed),
+				report.Stage,
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// loadMergeReport reads the merge report saved at the given path, or returns
+// nil if there isn't one yet.
+func loadMergeReport(path string) (*pocketsmith.MergeCategoriesReport, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report pocketsmith.MergeCategoriesReport
+	if err := json.Unmarshal(b, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// saveMergeReport writes the given merge report to the given path, only
+// readable by its owner. It's written atomically, so that a merge interrupted
+// while saving doesn't leave a partial report.
+func saveMergeReport(path string, report *pocketsmith.MergeCategoriesReport) error {
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, b, 0o600)
+}
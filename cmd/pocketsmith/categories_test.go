@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_saveMergeReport(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "merge.json")
+	want := &pocketsmith.MergeCategoriesReport{
+		SourceID:     1,
+		TargetID:     2,
+		Stage:        pocketsmith.MergeStageTransactions,
+		Transactions: []int32{10, 11},
+	}
+
+	// run tests.
+	for i := 0; i < 2; i++ {
+		if err := saveMergeReport(path, want); err != nil {
+			t.Fatalf("saveMergeReport() returned an error: %v", err)
+		}
+	}
+	got, err := loadMergeReport(path)
+	if err != nil {
+		t.Fatalf("loadMergeReport() returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadMergeReport() returned an unexpected report;\nwant=%+v\ngot=%+v", want, got)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat report: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("saveMergeReport() wrote the report with mode %v; want -rw-------", mode)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("saveMergeReport() left %v files behind; want 1", len(entries))
+	}
+}
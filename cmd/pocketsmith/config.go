@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// config defines the config file, eg.
+//
+//	default = "personal"
+//
+//	[profiles.personal]
+//	token_file = "~/.config/pocketsmith/personal.token"
+//
+//	[profiles.household]
+//	token_command = "pass show pocketsmith/household"
+type config struct {
+	Default  string             `toml:"default"`
+	Profiles map[string]profile `toml:"profiles"`
+
+	// Token is used when there are no profiles.
+	Token string `toml:"token"`
+}
+
+// profile defines where to find the token for a single user. Only one of the
+// fields should be set.
+type profile struct {
+	Token        string `toml:"token"`         // The token itself; the config file must be private.
+	TokenFile    string `toml:"token_file"`    // The path to a private file holding the token.
+	TokenCommand string `toml:"token_command"` // A command that prints the token, eg. from a password manager.
+}
+
+// source returns a description of where the token of the profile comes from,
+// without revealing the token.
+func (p profile) source() string {
+	switch {
+	case p.TokenCommand != "":
+		return "command: " + p.TokenCommand
+	case p.TokenFile != "":
+		return "file: " + p.TokenFile
+	case p.Token != "":
+		return "config"
+	}
+	return "none"
+}
+
+// defaultConfigPath returns the default path to the config file.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join("~", ".config", "pocketsmith", "config.toml")
+	}
+	return filepath.Join(dir, "pocketsmith", "config.toml")
+}
+
+// configFile returns the path to the config file, with any "~" expanded, so
+// the same file is both loaded and checked.
+func (h *handler) configFile() string {
+	path := h.configPath
+	if path == "" {
+		path = defaultConfigPath()
+	}
+	return expandHome(path)
+}
+
+// loadConfig loads the config file at the given path. A missing file returns
+// an empty config.
+func loadConfig(path string) (*config, error) {
+	var c config
+	if _, err := toml.DecodeFile(path, &c); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &c, nil
+		}
+		return nil, ErrConfigFailedRead{path, err}
+	}
+	return &c, nil
+}
+
+// token returns the token to use. The token is taken from, in order:
+//
+//  1. the profile given by --profile, or POCKETSMITH_PROFILE.
+//  2. the POCKETSMITH_TOKEN environment variable.
+//  3. the default profile in the config file.
+//  4. the token in the config file.
+func (h *handler) token(ctx context.Context) (string, error) {
+	path := h.configFile()
+	c, err := loadConfig(path)
+	if err != nil {
+		return "", err
+	}
+
+	// determine the profile to use.
+	name := h.profile
+	if name == "" {
+		name = os.Getenv("POCKETSMITH_PROFILE")
+	}
+	if name == "" {
+		if token := os.Getenv("POCKETSMITH_TOKEN"); token != "" {
+			return token, nil
+		}
+		name = c.Default
+	}
+	if name == "" {
+		if c.Token == "" {
+			return "", ErrMissingToken{path}
+		}
+		if err := checkPrivate(path); err != nil {
+			return "", err
+		}
+		return c.Token, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return "", ErrUnknownProfile{name, path}
+	}
+
+	// retrieve the token of the profile.
+	var token string
+	switch {
+	case p.TokenCommand != "":
+
+		// the command is run as the user, so whoever else can write the config
+		// file could run anything through it.
+		if err := checkPrivate(path); err != nil {
+			return "", err
+		}
+		if token, err = runTokenCommand(ctx, p.TokenCommand); err != nil {
+			return "", ErrProfileFailedToken{name, err}
+		}
+	case p.TokenFile != "":
+		file := expandHome(p.TokenFile)
+		if err := checkPrivate(file); err != nil {
+			return "", err
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", ErrProfileFailedToken{name, err}
+		}
+		token = strings.TrimSpace(string(b))
+	default:
+		if err := checkPrivate(path); err != nil {
+			return "", err
+		}
+		token = p.Token
+	}
+	if token == "" {
+		return "", ErrProfileFailedToken{name, errors.New("empty token")}
+	}
+	return token, nil
+}
+
+// runTokenCommand runs the given command through the shell, returning what it
+// prints as the token.
+func runTokenCommand(ctx context.Context, command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(err.Error() + ": " + msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// checkPrivate returns an ErrInsecurePermissions if the file at the given path
+// can be read or written by anyone other than its owner. Windows isn't
+// checked, since it doesn't use unix permissions.
+func checkPrivate(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if mode := info.Mode().Perm(); mode&0o077 != 0 {
+		return ErrInsecurePermissions{path, mode}
+	}
+	return nil
+}
+
+// expandHome expands a leading "~" in the given path to the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// profileRow defines a profile, as written by the profiles command.
+type profileRow struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+	Source  string `json:"source"`
+}
+
+// profileColumns are the columns written for profiles.
+var profileColumns = []column[profileRow]{
+	{"name", func(p profileRow) string { return p.Name }},
+	{"default", func(p profileRow) string {
+		if p.Default {
+			return "*"
+		}
+		return ""
+	}},
+	{"source", func(p profileRow) string { return p.Source }},
+}
+
+// runProfiles runs the profiles command.
+func runProfiles(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("profiles " + verb)
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	path := h.configFile()
+	c, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	var rows []profileRow
+	for name, p := range c.Profiles {
+		rows = append(rows, profileRow{Name: name, Default: name == c.Default, Source: p.source()})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return writeList(h.stdout, h.output, rows, profileColumns)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_handler_token(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, perm os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), perm); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	private := write("private.token", "file-token\n", 0o600)
+	public := write("public.token", "file-token\n", 0o644)
+	config := write("config.toml", `
+default = "file"
+
+[profiles.file]
+token_file = "`+private+`"
+
+[profiles.public]
+token_file = "`+public+`"
+
+[profiles.command]
+token_command = "echo command-token"
+
+[profiles.failing]
+token_command = "echo oops >&2; exit 1"
+
+[profiles.inline]
+token = "inline-token"
+`, 0o600)
+	shared := write("shared.toml", `
+[profiles.inline]
+token = "inline-token"
+
+[profiles.command]
+token_command = "echo command-token"
+`, 0o644)
+	legacy := write("legacy.toml", `token = "legacy-token"`, 0o600)
+
+	tests := map[string]struct {
+		config  string
+		profile string
+		env     map[string]string
+		want    string
+		wantErr error
+	}{
+		"default profile": {
+			config: config,
+			want:   "file-token",
+		},
+		"profile from flag": {
+			config:  config,
+			profile: "command",
+			want:    "command-token",
+		},
+		"profile from env": {
+			config: config,
+			env:    map[string]string{"POCKETSMITH_PROFILE": "inline"},
+			want:   "inline-token",
+		},
+		"flag overrides env token": {
+			config:  config,
+			profile: "inline",
+			env:     map[string]string{"POCKETSMITH_TOKEN": "env-token"},
+			want:    "inline-token",
+		},
+		"env token overrides default profile": {
+			config: config,
+			env:    map[string]string{"POCKETSMITH_TOKEN": "env-token"},
+			want:   "env-token",
+		},
+		"legacy token": {
+			config: legacy,
+			want:   "legacy-token",
+		},
+		"unknown profile": {
+			config:  config,
+			profile: "missing",
+			wantErr: ErrUnknownProfile{"missing", config},
+		},
+		"insecure token file": {
+			config:  config,
+			profile: "public",
+			wantErr: ErrInsecurePermissions{public, 0o644},
+		},
+		"insecure config": {
+			config:  shared,
+			profile: "inline",
+			wantErr: ErrInsecurePermissions{shared, 0o644},
+		},
+		"insecure config running a command": {
+			config:  shared,
+			profile: "command",
+			wantErr: ErrInsecurePermissions{shared, 0o644},
+		},
+		"failing command": {
+			config:  config,
+			profile: "failing",
+			wantErr: ErrProfileFailedToken{"failing", errors.New("exit status 1: oops")},
+		},
+		"config under home": {
+			config: "~/legacy.toml",
+			env:    map[string]string{"HOME": dir},
+			want:   "legacy-token",
+		},
+		"missing token": {
+			config:  filepath.Join(dir, "missing.toml"),
+			wantErr: ErrMissingToken{filepath.Join(dir, "missing.toml")},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("POCKETSMITH_PROFILE", tt.env["POCKETSMITH_PROFILE"])
+			t.Setenv("POCKETSMITH_TOKEN", tt.env["POCKETSMITH_TOKEN"])
+			if home, ok := tt.env["HOME"]; ok {
+				t.Setenv("HOME", home)
+			}
+			h := &handler{configPath: tt.config, profile: tt.profile}
+			got, err := h.token(context.Background())
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("token() returned unexpected error; want=%v, got=%v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("token() returned an error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("token() returned unexpected token; want=%q, got=%q", tt.want, got)
+			}
+		})
+	}
+}
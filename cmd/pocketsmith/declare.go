@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go/declare"
+)
+
+// stepColumns are the columns written for the steps in a declare report.
+var stepColumns = []column[*declare.Step]{
+	{"action", func(s *declare.Step) string { return string(s.Action) }},
+	{"kind", func(s *declare.Step) string { return string(s.Kind) }},
+	{"name", func(s *declare.Step) string { return s.Name }},
+	{"id", func(s *declare.Step) string { return formatID(s.ID) }},
+	{"fields", func(s *declare.Step) string {
+		fields := make([]string, len(s.Fields))
+		for i, f := range s.Fields {
+			fields[i] = fmt.Sprintf("%s: %s→%s", f.Name, f.From, f.To)
+		}
+		return strings.Join(fields, "; ")
+	}},
+	{"status", func(s *declare.Step) string { return string(s.Status) }},
+	{"error", func(s *declare.Step) string { return s.Error }},
+	{"warning", func(s *declare.Step) string { return s.Warning }},
+}
+
+// runDeclare runs the declare command.
+func runDeclare(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("declare " + verb)
+	file := fs.String("file", "", "the path to the YAML file declaring the categories & institutions")
+	userID := fs.Int("user", 0, "the id of the user to converge (default the authed user)")
+	allowDeletes := fs.Bool("allow-deletes", false, "when applying, delete the categories & institutions that aren't declared; a plan with deletes isn't applied without it")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := required(fs, "file"); err != nil {
+		return err
+	}
+	config, err := declare.Load(*file)
+	if err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+	if *userID == 0 {
+		u, err := c.GetAuthedUser(ctx)
+		if err != nil {
+			return err
+		}
+		*userID = u.ID
+	}
+
+	// plan, then apply if asked; a plan with deletes is only applied when
+	// they're allowed, so a category missing from the file isn't deleted, with
+	// its transactions left uncategorised, by mistake.
+	report, err := declare.Plan(ctx, c, *userID, config)
+	if err != nil {
+		return err
+	}
+	deletes := report.Count(declare.ActionDelete)
+	refused := verb == "apply" && deletes > 0 && !*allowDeletes
+	if verb == "apply" && !refused {
+		if report, err = declare.Apply(ctx, c, report); err != nil {
+			return err
+		}
+	}
+	if h.output == string(outputTable) {
+		_, err = report.WriteTo(h.stdout)
+	} else {
+		err = writeList(h.stdout, h.output, report.Steps, stepColumns)
+	}
+	if err != nil {
+		return err
+	}
+	if refused {
+		return ErrDeletesNotAllowed{deletes}
+	}
+	failed := 0
+	for _, s := range report.Steps {
+		if s.Status == declare.StatusFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return ErrStepsFailed{failed}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// ErrMissingCommand is returned when no resource is given.
+type ErrMissingCommand struct{}
+
+func (e ErrMissingCommand) Error() string {
+	return "missing resource"
+}
+
+// ErrUnknownCommand is returned when a resource isn't known.
+type ErrUnknownCommand struct {
+	name string
+}
+
+func (e ErrUnknownCommand) Error() string {
+	return fmt.Sprintf("unknown resource %q", e.name)
+}
+
+// ErrMissingVerb is returned when no verb is given for a resource.
+type ErrMissingVerb struct {
+	command string
+	verbs   []string
+}
+
+func (e ErrMissingVerb) Error() string {
+	return fmt.Sprintf("missing verb for %s; expected one of: %s", e.command, strings.Join(e.verbs, ", "))
+}
+
+// ErrUnknownVerb is returned when a verb isn't supported by a resource.
+type ErrUnknownVerb struct {
+	command string
+	verb    string
+	verbs   []string
+}
+
+func (e ErrUnknownVerb) Error() string {
+	return fmt.Sprintf(
+		"unknown verb %q for %s; expected one of: %s",
+		e.verb,
+		e.command,
+		strings.Join(e.verbs, ", "),
+	)
+}
+
+// ErrMissingID is returned when a verb needs a single id, but none was given.
+type ErrMissingID struct{}
+
+func (e ErrMissingID) Error() string {
+	return "expected a single id"
+}
+
+// ErrInvalidID is returned when an id isn't a positive number.
+type ErrInvalidID struct {
+	id string
+}
+
+func (e ErrInvalidID) Error() string {
+	return fmt.Sprintf("invalid id %q", e.id)
+}
+
+// ErrMissingFlag is returned when a required flag isn't given.
+type ErrMissingFlag struct {
+	name string
+}
+
+func (e ErrMissingFlag) Error() string {
+	return fmt.Sprintf("missing required flag --%s", e.name)
+}
+
+// ErrUnknownOutput is returned when an output format isn't known.
+type ErrUnknownOutput struct {
+	output string
+}
+
+func (e ErrUnknownOutput) Error() string {
+	return fmt.Sprintf("unknown output format %q; expected one of: table, json, csv, yaml", e.output)
+}
+
+// ErrMissingToken is returned when no token can be found.
+type ErrMissingToken struct {
+	path string
+}
+
+func (e ErrMissingToken) Error() string {
+	return fmt.Sprintf("no token found; set POCKETSMITH_TOKEN, or add a profile to %s", e.path)
+}
+
+// ErrConfigFailedRead is returned when the config file can't be read.
+type ErrConfigFailedRead struct {
+	path string
+	err  error
+}
+
+func (e ErrConfigFailedRead) Error() string {
+	return fmt.Sprintf("failed to read config %s: %v", e.path, e.err)
+}
+
+// ErrUnknownProfile is returned when a profile isn't in the config file.
+type ErrUnknownProfile struct {
+	name string
+	path string
+}
+
+func (e ErrUnknownProfile) Error() string {
+	return fmt.Sprintf("unknown profile %q in %s", e.name, e.path)
+}
+
+// ErrProfileFailedToken is returned when the token of a profile can't be
+// retrieved.
+type ErrProfileFailedToken struct {
+	name string
+	err  error
+}
+
+func (e ErrProfileFailedToken) Error() string {
+	return fmt.Sprintf("failed to get token for profile %q: %v", e.name, e.err)
+}
+
+// ErrInsecurePermissions is returned when a file holding a token can be read
+// by anyone other than its owner.
+type ErrInsecurePermissions struct {
+	path string
+	mode os.FileMode
+}
+
+func (e ErrInsecurePermissions) Error() string {
+	return fmt.Sprintf(
+		"%s holds a token, but has permissions %v; it must only be accessible by its owner (eg. chmod 600 %s)",
+		e.path,
+		e.mode,
+		e.path,
+	)
+}
+
+// ErrMissingSnapshots is returned when the diff command isn't given the
+// snapshots to compare.
+type ErrMissingSnapshots struct{}
+
+func (e ErrMissingSnapshots) Error() string {
+	return "expected the path to an older snapshot, and optionally a newer one (default now)"
+}
+
+// ErrStepsFailed is returned when the declare command fails to apply some of
+// the steps needed to converge.
+type ErrStepsFailed struct {
+	failed int
+}
+
+func (e ErrStepsFailed) Error() string {
+	return fmt.Sprintf("failed to apply %v steps; run apply again to retry them", e.failed)
+}
+
+// ErrDeletesNotAllowed is returned when the declare command is asked to apply
+// a plan with deletes, without them being allowed.
+type ErrDeletesNotAllowed struct {
+	deletes int
+}
+
+func (e ErrDeletesNotAllowed) Error() string {
+	return fmt.Sprintf("the plan deletes %v categories & institutions, so it wasn't applied; review it, then apply with --allow-deletes", e.deletes)
+}
+
+// ErrInvalidAccountType is returned when an account type isn't one the API
+// accepts.
+type ErrInvalidAccountType struct {
+	value string
+}
+
+func (e ErrInvalidAccountType) Error() string {
+	types := make([]string, len(pocketsmith.AccountTypes))
+	for i, t := range pocketsmith.AccountTypes {
+		types[i] = string(t)
+	}
+	return fmt.Sprintf("invalid account type %q; expected one of %s", e.value, strings.Join(types, ", "))
+}
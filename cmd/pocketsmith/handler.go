@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// command defines a resource command, eg. "accounts".
+type command struct {
+	verbs []string // The verbs supported by the command, shown in the usage.
+	about string   // Shown in the usage instead of the verbs, for commands without verbs.
+	run   func(ctx context.Context, h *handler, verb string, args []string) error
+}
+
+// commands are the resource commands supported by this binary.
+var commands = map[string]command{
+	"users":                {verbs: []string{"get"}, run: runUsers},
+	"institutions":         {verbs: []string{"list", "get", "create", "update", "delete"}, run: runInstitutions},
+	"accounts":             {verbs: []string{"list", "get", "create", "update", "reorder", "delete"}, run: runAccounts},
+	"transaction-accounts": {verbs: []string{"list", "get", "update", "rebalance"}, run: runTransactionAccounts},
+	"transactions":         {verbs: []string{"list", "get", "create", "update", "delete"}, run: runTransactions},
+	"categories":           {verbs: []string{"list", "get", "create", "update", "delete", "merge"}, run: runCategories},
+	"attachments":          {verbs: []string{"list", "get", "create", "update", "delete"}, run: runAttachments},
+	"profiles":             {verbs: []string{"list"}, run: runProfiles},
+	"review":               {about: "interactively review the transactions that need review", run: runReview},
+	"backup":               {about: "write a full copy of a user to an archive", run: runBackup},
+	"restore":              {about: "restore an archive into a user", run: runRestore},
+	"snapshot":             {about: "write a snapshot of a user, to compare with diff", run: runSnapshot},
+	"diff":                 {about: "compare two snapshots, or a snapshot with now", run: runDiff},
+	"declare":              {verbs: []string{"plan", "apply"}, run: runDeclare},
+}
+
+type handler struct {
+
+	// config.
+	name       string
+	version    string
+	output     string // The output format.
+	configPath string // The path to the config file.
+	profile    string // The profile in the config file to use.
+	logLevel   string
+
+	// clients.
+	pocketsmithsvc *pocketsmith.Client
+
+	// misc.
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// run is like main but after the handler is configured.
+func (h *handler) run(ctx context.Context, args []string) error {
+
+	// parse global flags.
+	fs := h.flagSet(h.name)
+	fs.Usage = h.usage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		h.usage()
+		return ErrMissingCommand{}
+	}
+
+	// run command.
+	switch args[0] {
+	case "version":
+		_, err := fmt.Fprintln(h.stdout, h.version)
+		return err
+	case "help":
+		h.usage()
+		return nil
+	}
+	c, ok := commands[args[0]]
+	if !ok {
+		return ErrUnknownCommand{args[0]}
+	}
+	if len(c.verbs) == 0 {
+		return c.run(ctx, h, "", args[1:])
+	}
+	if len(args) < 2 {
+		return ErrMissingVerb{args[0], c.verbs}
+	}
+	verb := args[1]
+	for _, v := range c.verbs {
+		if v == verb {
+			return c.run(ctx, h, verb, args[2:])
+		}
+	}
+	return ErrUnknownVerb{args[0], verb, c.verbs}
+}
+
+// usage writes the usage of this binary.
+func (h *handler) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(h.stderr, "usage: %s [flags] <resource> <verb> [flags] [id]\n\nresources:\n", h.name)
+	for _, name := range names {
+		c := commands[name]
+		about := c.about
+		if len(c.verbs) > 0 {
+			about = strings.Join(c.verbs, ", ")
+		}
+		fmt.Fprintf(h.stderr, "  %-22s %s\n", name, about)
+	}
+	fmt.Fprintf(h.stderr, "  %-22s %s\n", "version", "prints the version of this binary")
+	fmt.Fprintf(h.stderr, "\nflags:\n")
+	h.flagSet(h.name).PrintDefaults()
+}
+
+// flagSet returns a new flag set with the global flags registered, so that
+// they can be given before or after the resource and verb.
+func (h *handler) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(h.stderr)
+	if h.output == "" {
+		h.output = string(outputTable)
+	}
+
+	// the output format is checked as it's parsed, so that an unknown format
+	// fails before a command has changed anything.
+	setOutput := func(s string) error {
+		if !output(s).valid() {
+			return ErrUnknownOutput{s}
+		}
+		h.output = s
+		return nil
+	}
+	fs.Func("output", "the output format; table, json, csv or yaml (default table)", setOutput)
+	fs.Func("o", "shorthand for --output", setOutput)
+	fs.StringVar(&h.configPath, "config", h.configPath, "the path to the config file (default "+defaultConfigPath()+")")
+	fs.StringVar(&h.profile, "profile", h.profile, "the profile in the config file to use (default $POCKETSMITH_PROFILE)")
+	fs.StringVar(&h.logLevel, "log-level", h.logLevel, "the log level of the client; debug, info, warn or error")
+	return fs
+}
+
+// parse parses the given args, allowing flags to be given after positional
+// args, and returns the positional args.
+func parse(fs *flag.FlagSet, args []string) (positional []string, err error) {
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// client returns the client, setting it up on first use.
+func (h *handler) client(ctx context.Context) (*pocketsmith.Client, error) {
+	if h.pocketsmithsvc != nil {
+		return h.pocketsmithsvc, nil
+	}
+	token, err := h.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	level := slog.LevelWarn
+	switch strings.ToLower(h.logLevel) {
+	case "debug":
+		level = slog.LevelDebug
+	case "info":
+		level = slog.LevelInfo
+	case "error":
+		level = slog.LevelError
+	}
+	logger := slog.New(slog.NewTextHandler(h.stderr, &slog.HandlerOptions{Level: level}))
+	h.pocketsmithsvc, err = pocketsmith.New(ctx, token, pocketsmith.WithLogger(logger))
+	if err != nil {
+		return nil, err
+	}
+	return h.pocketsmithsvc, nil
+}
+
+// id returns the single positional id in the given args.
+func id(positional []string) (int, error) {
+	if len(positional) != 1 {
+		return 0, ErrMissingID{}
+	}
+	n, err := strconv.Atoi(positional[0])
+	if err != nil || n <= 0 {
+		return 0, ErrInvalidID{positional[0]}
+	}
+	return n, nil
+}
+
+// isSet returns if the flag with the given name was set.
+func isSet(fs *flag.FlagSet, name string) (set bool) {
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// required returns an ErrMissingFlag for the first of the given flags that
+// wasn't set.
+func required(fs *flag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if !isSet(fs, name) {
+			return ErrMissingFlag{name}
+		}
+	}
+	return nil
+}
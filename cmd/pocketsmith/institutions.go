@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// institutionColumns are the columns written for institutions.
+var institutionColumns = []column[pocketsmith.Institution]{
+	{"id", func(i pocketsmith.Institution) string { return formatID(i.ID) }},
+	{"title", func(i pocketsmith.Institution) string { return i.Title }},
+	{"currency", func(i pocketsmith.Institution) string { return i.CurrencyCode }},
+}
+
+// runInstitutions runs the institutions command.
+func runInstitutions(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("institutions " + verb)
+	title := fs.String("title", "", "the title of the institution")
+	currency := fs.String("currency", "", "the currency code of the institution, eg. aud")
+	mergeInto := fs.Int("merge-into", 0, "when deleting, the id of the institution to merge accounts into")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch verb {
+	case "list":
+		institutions, err := c.ListInstitutions(ctx)
+		if err != nil {
+			return err
+		}
+		return writeList(h.stdout, h.output, institutions, institutionColumns)
+
+	case "get":
+		institutionID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		i, err := c.GetInstitution(ctx, &pocketsmith.GetInstitutionOptions{InstitutionID: institutionID})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *i, institutionColumns)
+
+	case "create":
+		if err := required(fs, "title", "currency"); err != nil {
+			return err
+		}
+		i, err := c.CreateInstitution(ctx, &pocketsmith.CreateInstitutionOptions{
+			Title:        *title,
+			CurrencyCode: *currency,
+		})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *i, institutionColumns)
+
+	case "update":
+		institutionID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		i, err := c.UpdateInstitution(ctx, &pocketsmith.UpdateInstitutionOptions{
+			InstitutionID: institutionID,
+			Title:         *title,
+			CurrencyCode:  *currency,
+		})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *i, institutionColumns)
+
+	case "delete":
+		institutionID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		return c.DeleteInstitution(ctx, &pocketsmith.DeleteInstitutionOptions{
+			InstitutionID:          institutionID,
+			MergeIntoInstitutionID: *mergeInto,
+		})
+	}
+	return nil
+}
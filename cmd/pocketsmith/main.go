@@ -0,0 +1,61 @@
+// Command pocketsmith is a scriptable command line interface to the
+// PocketSmith API.
+//
+//	pocketsmith [--output table|json|csv|yaml] <resource> <verb> [flags] [id]
+//
+// The review command steps through the transactions that need review, one at
+// a time, driven by single key presses; categories are picked with a live
+// fuzzy search, and payees, labels and notes are edited in place (press ? for
+// the keys):
+//
+//	pocketsmith review [--start 2024-01-01] [--search woolworths]
+//
+// The diff command reports what changed between two snapshots (written by the
+// snapshot or backup commands), or between a snapshot and now:
+//
+//	pocketsmith snapshot --file before.json
+//	pocketsmith diff before.json [after.json]
+//
+// The declare command converges the categories and institutions of a user on
+// those declared in a YAML file; plan reports the changes needed, and apply
+// makes them. Deleting what isn't declared must be allowed with
+// --allow-deletes:
+//
+//	pocketsmith declare plan --file categories.yaml
+//	pocketsmith declare apply --file categories.yaml
+//
+// The token is read from a profile in the config file (see --config and
+// --profile), or from the POCKETSMITH_TOKEN environment variable. Each profile
+// holds the token in the config file, in a separate file, or runs a command
+// that prints it, eg. from a password manager. Files holding tokens, or
+// commands that print them, must only be accessible by their owner.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+var (
+
+	// the name of this binary.
+	Name = "pocketsmith"
+
+	// the version of this binary.
+	Version = "HEAD"
+)
+
+func main() {
+	h := &handler{
+		name:    Name,
+		version: Version,
+		stdin:   os.Stdin,
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+	}
+	if err := h.run(context.Background(), os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", Name, err)
+		os.Exit(1)
+	}
+}
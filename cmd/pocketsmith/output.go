@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// output defines the format results are written in.
+type output string
+
+// The output formats supported by this binary.
+const (
+	outputTable output = "table"
+	outputJSON  output = "json"
+	outputCSV   output = "csv"
+	outputYAML  output = "yaml"
+)
+
+// valid returns if the output format is supported.
+func (o output) valid() bool {
+	switch o {
+	case outputTable, outputJSON, outputCSV, outputYAML:
+		return true
+	}
+	return false
+}
+
+// column defines a column written in the table and csv output formats.
+type column[T any] struct {
+	name  string
+	value func(T) string
+}
+
+// writeList writes the given items to the given writer, in the given format.
+func writeList[T any](w io.Writer, format string, items []T, columns []column[T]) error {
+	if items == nil {
+		items = []T{} // so json and yaml write an empty list, not null.
+	}
+	return write(w, format, items, items, columns)
+}
+
+// writeOne writes the given item to the given writer, in the given format.
+func writeOne[T any](w io.Writer, format string, item T, columns []column[T]) error {
+	return write(w, format, item, []T{item}, columns)
+}
+
+// write writes v as json or yaml, or rows as a table or csv.
+func write[T any](w io.Writer, format string, v interface{}, rows []T, columns []column[T]) error {
+	switch output(format) {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+
+	case outputYAML:
+
+		// round-trip through json, so that the json field names are used.
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		if err := json.Unmarshal(b, &generic); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return err
+		}
+		return enc.Close()
+
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header(columns)); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := cw.Write(cells(row, columns)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		names := header(columns)
+		for i := range names {
+			names[i] = strings.ToUpper(names[i])
+		}
+		fmt.Fprintln(tw, strings.Join(names, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(cells(row, columns), "\t"))
+		}
+		return tw.Flush()
+	}
+	return ErrUnknownOutput{format}
+}
+
+// header returns the names of the given columns.
+func header[T any](columns []column[T]) []string {
+	out := make([]string, len(columns))
+	for i, c := range columns {
+		out[i] = c.name
+	}
+	return out
+}
+
+// cells returns the values of the given columns for the given row.
+func cells[T any](row T, columns []column[T]) []string {
+	out := make([]string, len(columns))
+	for i, c := range columns {
+		out[i] = c.value(row)
+	}
+	return out
+}
+
+// formatAmount formats the given amount with two decimal places.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// formatID formats the given id.
+func formatID[T ~int | ~int32](id T) string {
+	return strconv.Itoa(int(id))
+}
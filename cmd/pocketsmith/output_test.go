@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_writeList(t *testing.T) {
+	institutions := pocketsmith.Institutions{
+		{ID: 1, Title: "Bank, Inc", CurrencyCode: "aud"},
+		{ID: 22, Title: "Broker", CurrencyCode: "usd"},
+	}
+	tests := map[string]struct {
+		output string
+		want   string
+	}{
+		"table": {
+			output: "table",
+			want:   "ID  TITLE      CURRENCY\n1   Bank, Inc  aud\n22  Broker     usd\n",
+		},
+		"csv": {
+			output: "csv",
+			want:   "id,title,currency\n1,\"Bank, Inc\",aud\n22,Broker,usd\n",
+		},
+		"yaml": {
+			output: "yaml",
+			want: "- created_at: \"0001-01-01T00:00:00Z\"\n  currency_code: aud\n  id: 1\n  title: Bank, Inc\n" +
+				"  updated_at: \"0001-01-01T00:00:00Z\"\n- created_at: \"0001-01-01T00:00:00Z\"\n  currency_code: usd\n" +
+				"  id: 22\n  title: Broker\n  updated_at: \"0001-01-01T00:00:00Z\"\n",
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeList(&buf, tt.output, institutions, institutionColumns); err != nil {
+				t.Fatalf("writeList() returned an error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeList() returned unexpected output;\nwant=%q\ngot=%q", tt.want, buf.String())
+			}
+		})
+	}
+	if err := writeList(io.Discard, "xml", institutions, institutionColumns); err == nil {
+		t.Errorf("writeList() didn't return an error for an unknown output")
+	}
+}
+
+func Test_parse(t *testing.T) {
+	h := &handler{stderr: io.Discard}
+	fs := h.flagSet("test")
+	title := fs.String("title", "", "")
+	positional, err := parse(fs, []string{"123", "--output", "json", "--title", "x"})
+	if err != nil {
+		t.Fatalf("parse() returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(positional, []string{"123"}) || h.output != "json" || *title != "x" {
+		t.Errorf("parse() returned unexpected results; positional=%v, output=%v, title=%v", positional, h.output, *title)
+	}
+	if err := required(fs, "title", "missing"); err == nil || err.Error() != "missing required flag --missing" {
+		t.Errorf("required() returned an unexpected error; got=%v", err)
+	}
+}
+
+func Test_run_unknownOutput(t *testing.T) {
+
+	// the output is rejected before the command looks for a token, or sends
+	// anything; before or after the resource and verb.
+	for _, args := range [][]string{
+		{"-o", "xml", "institutions", "create", "--title", "x"},
+		{"institutions", "create", "--title", "x", "--output", "xml"},
+	} {
+		h := &handler{name: "test", stderr: io.Discard, configPath: "/does/not/exist"}
+		err := h.run(context.Background(), args)
+		if err == nil || !strings.Contains(err.Error(), ErrUnknownOutput{"xml"}.Error()) {
+			t.Errorf("run(%q) returned an unexpected error; got=%v", args, err)
+		}
+	}
+}
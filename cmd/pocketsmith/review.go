@@ -0,0 +1,627 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// reviewHelp is the help shown by the review command.
+const reviewHelp = `keys:
+  enter, r       save the changes and mark the transaction reviewed
+  c              pick a category, fuzzy searching the category paths as you type
+  p              edit the payee
+  l              edit the comma separated labels
+  n              edit the note
+  x              discard the unsaved changes
+  →, s           skip to the next transaction
+  ←, b           go back to the previous transaction
+  u              undo the last saved change
+  q, ctrl+c      quit
+  ?              show or hide this help
+
+when editing, enter accepts, esc cancels and ctrl+u clears. when picking a
+category, ↑ and ↓ (or ctrl+p and ctrl+n) move between the matches.
+`
+
+// reviewHint is the line of keys shown under the transaction being reviewed.
+const reviewHint = "enter save · c category · p payee · l labels · n note · x discard · ←/→ move · u undo · q quit · ? help"
+
+// key defines a key pressed while reviewing; either a single printable
+// character, or one of the named keys below.
+type key string
+
+// The named keys.
+const (
+	keyEnter     key = "enter"
+	keyEscape    key = "esc"
+	keyBackspace key = "backspace"
+	keyUp        key = "up"
+	keyDown      key = "down"
+	keyLeft      key = "left"
+	keyRight     key = "right"
+	keyClear     key = "ctrl+u"
+	keyInterrupt key = "ctrl+c"
+)
+
+// printable returns if the key is a single printable character.
+func (k key) printable() bool {
+	return utf8.RuneCountInString(string(k)) == 1
+}
+
+// reviewMode defines what the keys pressed while reviewing do.
+type reviewMode int
+
+const (
+	reviewModeBrowse   reviewMode = iota // Keys act on the transaction being reviewed.
+	reviewModeEdit                       // Keys edit the value of a field.
+	reviewModeCategory                   // Keys search for, and pick, a category.
+)
+
+// reviewAPI defines the parts of the *pocketsmith.Client used when reviewing.
+type reviewAPI interface {
+	UpdateTransaction(
+		ctx context.Context,
+		options *pocketsmith.UpdateTransactionOptions,
+	) (*pocketsmith.Transaction, error)
+}
+
+// reviewChange defines a change saved while reviewing, so that it can be
+// undone.
+type reviewChange struct {
+	index  int                     // The index of the transaction in the queue.
+	before pocketsmith.Transaction // The transaction before the change.
+}
+
+// reviewer steps through a queue of transactions that need review, driven by
+// single key presses.
+type reviewer struct {
+	api        reviewAPI
+	queue      pocketsmith.Transactions
+	categories []categoryRow
+	in         *bufio.Reader
+	out        io.Writer
+	tty        bool // If the output is a terminal, so the screen is redrawn.
+
+	index   int                                   // The index of the transaction being reviewed.
+	pending *pocketsmith.UpdateTransactionOptions // The unsaved changes to the transaction being reviewed.
+	history []reviewChange                        // The saved changes, most recent last.
+
+	mode     reviewMode
+	field    string        // The field being edited.
+	input    []rune        // The value being edited, or the category search.
+	matches  []categoryRow // The categories matching the search.
+	selected int           // The index of the selected match.
+	message  string        // Shown once, under the transaction.
+	help     bool          // If the help is shown.
+}
+
+// runReview runs the review command.
+func runReview(ctx context.Context, h *handler, _ string, args []string) error {
+	fs := h.flagSet("review")
+	userID := fs.Int("user", 0, "the id of the user to review (default the authed user)")
+	start := fs.String("start", "", "the earliest date, eg. 2024-01-01")
+	end := fs.String("end", "", "the latest date, eg. 2024-01-31")
+	search := fs.String("search", "", "only transactions matching the search")
+	uncategorised := fs.Bool("uncategorised", false, "only uncategorised transactions")
+	transactionType := fs.String("type", "", "only debit or credit transactions")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	// determine the user.
+	if *userID == 0 {
+		u, err := c.GetAuthedUser(ctx)
+		if err != nil {
+			return err
+		}
+		*userID = u.ID
+	}
+
+	// list the queue & the categories to pick from.
+	queue, err := c.ListTransactionsForUser(ctx, &pocketsmith.ListTransactionsForUserOptions{
+		UserID: *userID,
+		ListTransactionsOptions: pocketsmith.ListTransactionsOptions{
+			StartDate:     *start,
+			EndDate:       *end,
+			Search:        *search,
+			Uncategorised: *uncategorised,
+			Type:          *transactionType,
+			NeedsReview:   true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	categories, err := c.ListCategoriesForUser(ctx, &pocketsmith.ListCategoriesForUserOptions{
+		UserID: *userID,
+	})
+	if err != nil {
+		return err
+	}
+	r := &reviewer{
+		api:        c,
+		queue:      queue,
+		categories: flattenCategories(categories),
+		in:         bufio.NewReader(h.stdin),
+		out:        h.stdout,
+	}
+
+	// read keys as they're pressed; when stdin isn't a terminal (eg. keys
+	// piped in from a script), they're read as they come.
+	if f, ok := h.stdin.(*os.File); ok {
+		if restore, err := makeRaw(int(f.Fd())); err == nil {
+			defer restore()
+			r.tty = true
+		}
+	}
+	return r.run(ctx)
+}
+
+// run reviews the queue, reading keys until the queue is empty, the input
+// ends, or the user quits.
+func (r *reviewer) run(ctx context.Context) error {
+	if len(r.queue) == 0 {
+		fmt.Fprintln(r.out, "nothing to review")
+		return nil
+	}
+	r.message = fmt.Sprintf("%v transactions to review", len(r.queue))
+	for r.index < len(r.queue) {
+		r.render()
+		k, err := r.readKey()
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(r.out)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var quit bool
+		switch r.mode {
+		case reviewModeEdit:
+			r.edit(k)
+		case reviewModeCategory:
+			r.pick(k)
+		default:
+			quit = r.browse(ctx, k)
+		}
+		if quit {
+			fmt.Fprintln(r.out)
+			return nil
+		}
+	}
+	fmt.Fprintln(r.out, "\nreached the end of the review queue")
+	return nil
+}
+
+// browse handles the given key while browsing the queue, returning if the
+// user quit.
+func (r *reviewer) browse(ctx context.Context, k key) (quit bool) {
+	payee, labels, note := r.values()
+	switch k {
+	case keyEnter, "r":
+		if err := r.save(ctx); err != nil {
+			r.message = fmt.Sprintf("failed to save: %v", err)
+		}
+	case "c":
+		r.mode, r.input = reviewModeCategory, nil
+		r.match()
+	case "p":
+		r.mode, r.field, r.input = reviewModeEdit, "payee", []rune(payee)
+	case "l":
+		r.mode, r.field, r.input = reviewModeEdit, "labels", []rune(labels)
+	case "n":
+		r.mode, r.field, r.input = reviewModeEdit, "note", []rune(note)
+	case "x":
+		r.pending = nil
+	case keyRight, "s":
+		r.move(1)
+	case keyLeft, "b":
+		r.move(-1)
+	case "u":
+		if err := r.undo(ctx); err != nil {
+			r.message = fmt.Sprintf("failed to undo: %v", err)
+		}
+	case "q", keyInterrupt:
+		return true
+	case "?":
+		r.help = !r.help
+	case "":
+	default:
+		r.message = fmt.Sprintf("unknown key %q; ? for help", k)
+	}
+	return false
+}
+
+// edit handles the given key while editing a field.
+func (r *reviewer) edit(k key) {
+	switch {
+	case k == keyEnter:
+		r.mode = reviewModeBrowse
+		r.set(r.field, strings.TrimSpace(string(r.input)))
+	case k == keyEscape || k == keyInterrupt:
+		r.mode = reviewModeBrowse
+	case k == keyBackspace && len(r.input) > 0:
+		r.input = r.input[:len(r.input)-1]
+	case k == keyClear:
+		r.input = nil
+	case k.printable():
+		r.input = append(r.input, []rune(string(k))...)
+	}
+}
+
+// pick handles the given key while searching for a category.
+func (r *reviewer) pick(k key) {
+	switch {
+	case k == keyEnter:
+		r.mode = reviewModeBrowse
+		if len(r.matches) == 0 {
+			r.message = fmt.Sprintf("no categories match %q", string(r.input))
+			return
+		}
+		c := r.matches[r.selected]
+		r.set("category", c.Path)
+	case k == keyEscape || k == keyInterrupt:
+		r.mode = reviewModeBrowse
+	case k == keyUp:
+		r.selected = max(r.selected-1, 0)
+	case k == keyDown:
+		r.selected = min(r.selected+1, max(len(r.matches)-1, 0))
+	case k == keyBackspace && len(r.input) > 0:
+		r.input = r.input[:len(r.input)-1]
+		r.match()
+	case k == keyClear:
+		r.input = nil
+		r.match()
+	case k.printable():
+		r.input = append(r.input, []rune(string(k))...)
+		r.match()
+	}
+}
+
+// match updates the categories matching the category search.
+func (r *reviewer) match() {
+	r.matches, r.selected = fuzzyFind(string(r.input), r.categories, 9), 0
+}
+
+// readKey reads the next key pressed. Keys that aren't used are returned as
+// an empty key.
+func (r *reviewer) readKey() (key, error) {
+	c, _, err := r.in.ReadRune()
+	if err != nil {
+		return "", err
+	}
+	switch c {
+	case '\r', '\n':
+		return keyEnter, nil
+	case '\b', 127:
+		return keyBackspace, nil
+	case 3:
+		return keyInterrupt, nil
+	case 14:
+		return keyDown, nil
+	case 16:
+		return keyUp, nil
+	case 21:
+		return keyClear, nil
+	case 27:
+		return r.readEscape()
+	}
+	if !unicode.IsPrint(c) {
+		return "", nil
+	}
+	return key(c), nil
+}
+
+// readEscape reads the rest of an escape sequence, such as an arrow key. An
+// escape that isn't followed by a sequence straight away is the escape key.
+func (r *reviewer) readEscape() (key, error) {
+	if r.in.Buffered() == 0 {
+		return keyEscape, nil
+	}
+	if b, _ := r.in.Peek(1); b[0] != '[' && b[0] != 'O' {
+		return keyEscape, nil
+	}
+	r.in.ReadByte()
+
+	// read up to the final byte of the sequence, eg. "A" in "\x1b[A".
+	for {
+		b, err := r.in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if b < 0x40 || b > 0x7e {
+			continue
+		}
+		switch b {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		}
+		return "", nil
+	}
+}
+
+// render writes the transaction being reviewed, along with its unsaved
+// changes, then what the keys do in the current mode.
+func (r *reviewer) render() {
+	var b strings.Builder
+	if r.tty {
+		b.WriteString("\x1b[H\x1b[2J") // clear the screen.
+	} else {
+		b.WriteString("\n")
+	}
+	t := r.queue[r.index]
+	payee, labels, note := r.values()
+	category := ""
+	if t.Category.ID != 0 {
+		category = r.categoryPath(t.Category.ID)
+	}
+	if p := r.pending; p != nil {
+		if p.Payee != "" {
+			payee += " *"
+		}
+		if p.Labels != "" {
+			labels += " *"
+		}
+		if p.Note != "" {
+			note += " *"
+		}
+		if p.CategoryID != 0 {
+			category = r.categoryPath(p.CategoryID) + " *"
+		}
+	}
+	status := "needs review"
+	if !t.NeedsReview {
+		status = "reviewed"
+	}
+	fmt.Fprintf(
+		&b,
+		"[%v/%v] %s %s %s %s (%s, %s)\n",
+		r.index+1,
+		len(r.queue),
+		formatID(t.ID),
+		t.Date,
+		formatAmount(t.Amount),
+		t.TransactionAccount.CurrencyCode,
+		t.TransactionAccount.Name,
+		status,
+	)
+	if t.OriginalPayee != "" && t.OriginalPayee != t.Payee {
+		fmt.Fprintf(&b, "  original: %s\n", t.OriginalPayee)
+	}
+	fmt.Fprintf(&b, "  payee:    %s\n", payee)
+	fmt.Fprintf(&b, "  category: %s\n", category)
+	fmt.Fprintf(&b, "  labels:   %s\n", labels)
+	fmt.Fprintf(&b, "  note:     %s\n\n", note)
+
+	// the keys; prompts are written last, so the cursor is left after them.
+	switch r.mode {
+	case reviewModeEdit:
+		fmt.Fprintf(&b, "%s: %s", r.field, string(r.input))
+	case reviewModeCategory:
+		for i, c := range r.matches {
+			marker := "  "
+			if i == r.selected {
+				marker = "> "
+			}
+			fmt.Fprintf(&b, "%s%s\n", marker, c.Path)
+		}
+		fmt.Fprintf(&b, "category: %s", string(r.input))
+	default:
+		if r.help {
+			b.WriteString(reviewHelp)
+		} else {
+			b.WriteString(reviewHint + "\n")
+		}
+		if r.message != "" {
+			b.WriteString(r.message + "\n")
+		}
+	}
+	r.message = ""
+	io.WriteString(r.out, b.String())
+}
+
+// values returns the payee, labels and note of the transaction being
+// reviewed, with the unsaved changes applied.
+func (r *reviewer) values() (payee, labels, note string) {
+	t := r.queue[r.index]
+	payee, labels, note = t.Payee, strings.Join(t.Labels, ","), t.Note
+	if p := r.pending; p != nil {
+		if p.Payee != "" {
+			payee = p.Payee
+		}
+		if p.Labels != "" {
+			labels = p.Labels
+		}
+		if p.Note != "" {
+			note = p.Note
+		}
+	}
+	return payee, labels, note
+}
+
+// set sets the given field to the given value, as an unsaved change, as long
+// as the value isn't empty; the API can't set empty values.
+func (r *reviewer) set(field, value string) {
+	if value == "" {
+		r.message = fmt.Sprintf("the %s can't be cleared", field)
+		return
+	}
+	if r.pending == nil {
+		r.pending = &pocketsmith.UpdateTransactionOptions{TransactionID: r.queue[r.index].ID}
+	}
+	switch field {
+	case "payee":
+		r.pending.Payee = value
+	case "labels":
+		r.pending.Labels = value
+	case "note":
+		r.pending.Note = value
+	case "category":
+		r.pending.CategoryID = r.matches[r.selected].ID
+	}
+}
+
+// save saves the unsaved changes to the transaction being reviewed, marks it
+// reviewed, then moves to the next transaction.
+func (r *reviewer) save(ctx context.Context) error {
+	before := r.queue[r.index]
+	options := r.pending
+	if options == nil {
+		options = &pocketsmith.UpdateTransactionOptions{TransactionID: before.ID}
+	}
+	reviewed := false
+	options.NeedsReview = &reviewed
+	t, err := r.api.UpdateTransaction(ctx, options)
+	if err != nil {
+		return err
+	}
+	r.queue[r.index] = *t
+	r.history = append(r.history, reviewChange{index: r.index, before: before})
+	r.pending = nil
+
+	// saving the last transaction ends the review.
+	if r.index == len(r.queue)-1 {
+		r.index = len(r.queue)
+		return nil
+	}
+	r.move(1)
+	return nil
+}
+
+// undo reverts the last saved change, and moves back to that transaction.
+func (r *reviewer) undo(ctx context.Context) error {
+	if len(r.history) == 0 {
+		r.message = "nothing to undo"
+		return nil
+	}
+	last := r.history[len(r.history)-1]
+	after, before := r.queue[last.index], last.before
+	t, err := r.api.UpdateTransaction(ctx, &pocketsmith.UpdateTransactionOptions{
+		TransactionID: before.ID,
+		Payee:         before.Payee,
+		CategoryID:    before.Category.ID,
+		Labels:        strings.Join(before.Labels, ","),
+		Note:          before.Note,
+		NeedsReview:   &before.NeedsReview,
+	})
+	if err != nil {
+		return err
+	}
+
+	// NOTE: the API ignores empty values, so values that were added can't be
+	// removed again.
+	var kept []string
+	if before.Category.ID == 0 && after.Category.ID != 0 {
+		kept = append(kept, "category")
+	}
+	if len(before.Labels) == 0 && len(after.Labels) > 0 {
+		kept = append(kept, "labels")
+	}
+	if before.Note == "" && after.Note != "" {
+		kept = append(kept, "note")
+	}
+	if len(kept) > 0 {
+		r.message = fmt.Sprintf("undone, but the API can't clear the %s", strings.Join(kept, ", "))
+	}
+	r.queue[last.index] = *t
+	r.history = r.history[:len(r.history)-1]
+	r.index, r.pending = last.index, nil
+	return nil
+}
+
+// move moves by the given number of transactions in the queue, discarding any
+// unsaved changes. Moving back from the first transaction, or on from the
+// last, stays there.
+func (r *reviewer) move(n int) {
+	r.index = min(max(r.index+n, 0), len(r.queue)-1)
+	r.pending = nil
+}
+
+// categoryPath returns the path of the category with the given id, or its id
+// if it isn't known.
+func (r *reviewer) categoryPath(id int32) string {
+	for _, c := range r.categories {
+		if c.ID == id {
+			return c.Path
+		}
+	}
+	return formatID(id)
+}
+
+// fuzzyFind returns up to limit categories whose path fuzzy matches the given
+// query, best match first.
+func fuzzyFind(query string, categories []categoryRow, limit int) []categoryRow {
+	type match struct {
+		category categoryRow
+		score    int
+	}
+	var matches []match
+	for _, c := range categories {
+		if score, ok := fuzzyScore(query, c.Path); ok {
+			matches = append(matches, match{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].category.Path < matches[j].category.Path
+	})
+	out := make([]categoryRow, 0, min(len(matches), limit))
+	for _, m := range matches[:min(len(matches), limit)] {
+		out = append(out, m.category)
+	}
+	return out
+}
+
+// fuzzyScore returns how well the given query matches the given value, and if
+// it matches at all. Every character of the query must appear in the value,
+// in order, ignoring case and spaces. Consecutive characters and characters
+// that start a word score higher, and gaps score lower.
+func fuzzyScore(query, value string) (score int, ok bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	v := []rune(strings.ToLower(value))
+	last := -1
+	for i, j := 0, 0; i < len(q); i++ {
+		for j < len(v) && v[j] != q[i] {
+			j++
+		}
+		if j == len(v) {
+			return 0, false
+		}
+		switch {
+		case j == last+1:
+			score += 3
+		case last >= 0:
+			score -= min(j-last-1, 3)
+		}
+		if j == 0 || !unicode.IsLetter(v[j-1]) {
+			score += 2
+		}
+		last = j
+		j++
+	}
+
+	// prefer the shortest path, so that parents rank above their children.
+	return score*100 - len(v), true
+}
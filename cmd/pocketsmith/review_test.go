@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// mockReviewAPI records the updates sent while reviewing.
+type mockReviewAPI struct {
+	updates []pocketsmith.UpdateTransactionOptions
+}
+
+func (m *mockReviewAPI) UpdateTransaction(
+	ctx context.Context,
+	options *pocketsmith.UpdateTransactionOptions,
+) (*pocketsmith.Transaction, error) {
+	m.updates = append(m.updates, *options)
+	t := pocketsmith.Transaction{ID: options.TransactionID, Payee: options.Payee, Note: options.Note}
+	t.Category.ID = options.CategoryID
+	if options.NeedsReview != nil {
+		t.NeedsReview = *options.NeedsReview
+	}
+	return &t, nil
+}
+
+func Test_reviewer_run(t *testing.T) {
+	categories := flattenCategories(pocketsmith.Categories{
+		{ID: 1, Title: "Food", Children: []*pocketsmith.Category{
+			{ID: 2, Title: "Groceries"},
+			{ID: 3, Title: "Restaurants"},
+		}},
+		{ID: 4, Title: "Transport"},
+	})
+	reviewed, needsReview := false, true
+	tests := map[string]struct {
+		input string
+		want  []pocketsmith.UpdateTransactionOptions
+	}{
+		"mark reviewed": {
+			input: "\r",
+			want: []pocketsmith.UpdateTransactionOptions{
+				{TransactionID: 10, NeedsReview: &reviewed},
+			},
+		},
+		"edit and mark reviewed": {
+			input: "cgroc\rp\x15Woolworths\rlfood,weekly\rnbig shop\rr",
+			want: []pocketsmith.UpdateTransactionOptions{
+				{
+					TransactionID: 10,
+					CategoryID:    2,
+					Payee:         "Woolworths",
+					Labels:        "food,weekly",
+					Note:          "big shop",
+					NeedsReview:   &reviewed,
+				},
+			},
+		},
+		"pick from several categories": {
+			input: "cfd\x1b[B\rr",
+			want: []pocketsmith.UpdateTransactionOptions{
+				{TransactionID: 10, CategoryID: 2, NeedsReview: &reviewed},
+			},
+		},
+		"discard and skip": {
+			input: "p\x15Woolworths\rx\x1b[C\r",
+			want: []pocketsmith.UpdateTransactionOptions{
+				{TransactionID: 11, NeedsReview: &reviewed},
+			},
+		},
+		"cancel an edit": {
+			input: "pabc\x1b\r",
+			want: []pocketsmith.UpdateTransactionOptions{
+				{TransactionID: 10, NeedsReview: &reviewed},
+			},
+		},
+		"edit with backspace": {
+			input: "p\x15Colez\x7fs\r\r",
+			want: []pocketsmith.UpdateTransactionOptions{
+				{TransactionID: 10, Payee: "Coles", NeedsReview: &reviewed},
+			},
+		},
+		"skip past the last, then mark it reviewed": {
+			input: "ss\r",
+			want: []pocketsmith.UpdateTransactionOptions{
+				{TransactionID: 11, NeedsReview: &reviewed},
+			},
+		},
+		"undo": {
+			input: "p\x15Coles\r\ru\x03",
+			want: []pocketsmith.UpdateTransactionOptions{
+				{TransactionID: 10, Payee: "Coles", NeedsReview: &reviewed},
+				{TransactionID: 10, Payee: "WOOLWORTHS 1234", NeedsReview: &needsReview},
+			},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			api := &mockReviewAPI{}
+			r := &reviewer{
+				api: api,
+				queue: pocketsmith.Transactions{
+					{ID: 10, Payee: "WOOLWORTHS 1234", NeedsReview: true},
+					{ID: 11, Payee: "SHELL", NeedsReview: true},
+				},
+				categories: categories,
+				in:         bufio.NewReader(strings.NewReader(tt.input)),
+				out:        &bytes.Buffer{},
+			}
+			if err := r.run(context.Background()); err != nil {
+				t.Fatalf("run() returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(api.updates, tt.want) {
+				t.Errorf("run() sent unexpected updates;\nwant=%+v\ngot=%+v", tt.want, api.updates)
+			}
+		})
+	}
+}
+
+func Test_fuzzyFind(t *testing.T) {
+	categories := flattenCategories(pocketsmith.Categories{
+		{ID: 1, Title: "Food", Children: []*pocketsmith.Category{
+			{ID: 2, Title: "Groceries"},
+			{ID: 3, Title: "Restaurants"},
+		}},
+		{ID: 4, Title: "Transport"},
+		{ID: 5, Title: "Gifts"},
+	})
+	tests := map[string]struct {
+		query string
+		want  []string
+	}{
+		"prefix": {
+			query: "tra",
+			want:  []string{"Transport", "Food/Restaurants"},
+		},
+		"subsequence": {
+			query: "fgroc",
+			want:  []string{"Food/Groceries"},
+		},
+		"parent first": {
+			query: "food",
+			want:  []string{"Food", "Food/Groceries", "Food/Restaurants"},
+		},
+		"no match": {
+			query: "xyz",
+			want:  []string{},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			got := []string{}
+			for _, c := range fuzzyFind(tt.query, categories, 9) {
+				got = append(got, c.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fuzzyFind() returned unexpected matches; want=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go/snapshot"
+)
+
+// changeColumns are the columns written for the changes in a diff.
+var changeColumns = []column[snapshot.Change]{
+	{"entity", func(c snapshot.Change) string { return string(c.Entity) }},
+	{"id", func(c snapshot.Change) string { return strconv.FormatInt(c.ID, 10) }},
+	{"kind", func(c snapshot.Change) string { return string(c.Kind) }},
+	{"summary", func(c snapshot.Change) string { return c.Summary }},
+	{"fields", func(c snapshot.Change) string {
+		fields := make([]string, len(c.Fields))
+		for i, f := range c.Fields {
+			fields[i] = fmt.Sprintf("%s: %s→%s", f.Name, f.From, f.To)
+		}
+		return strings.Join(fields, "; ")
+	}},
+}
+
+// runSnapshot runs the snapshot command.
+func runSnapshot(ctx context.Context, h *handler, _ string, args []string) error {
+	fs := h.flagSet("snapshot")
+	file := fs.String("file", "", "the path to write the snapshot to, eg. snapshot.json")
+	userID := fs.Int("user", 0, "the id of the user to snapshot (default the authed user)")
+	if _, err := parse(fs, args); err != nil {
+		return err
+	}
+	if err := required(fs, "file"); err != nil {
+		return err
+	}
+	s, err := h.snapshot(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	if err := snapshot.Save(*file, s); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(h.stdout, "saved %v transactions to %s\n", len(s.Transactions), *file)
+	return err
+}
+
+// runDiff runs the diff command.
+func runDiff(ctx context.Context, h *handler, _ string, args []string) error {
+	fs := h.flagSet("diff")
+	userID := fs.Int("user", 0, "when comparing against now, the id of the user (default the authed user)")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		return ErrMissingSnapshots{}
+	}
+
+	// load the older snapshot, and the newer; or take it now.
+	older, err := snapshot.Load(positional[0])
+	if err != nil {
+		return err
+	}
+	var newer *snapshot.Snapshot
+	if len(positional) == 2 {
+		newer, err = snapshot.Load(positional[1])
+	} else {
+		newer, err = h.snapshot(ctx, *userID)
+	}
+	if err != nil {
+		return err
+	}
+
+	// write the changes.
+	diff := snapshot.Compare(older, newer)
+	if h.output == string(outputTable) {
+		_, err := diff.WriteTo(h.stdout)
+		return err
+	}
+	return writeList(h.stdout, h.output, diff, changeColumns)
+}
+
+// snapshot takes a snapshot of the given user, or the authed user if 0.
+func (h *handler) snapshot(ctx context.Context, userID int) (*snapshot.Snapshot, error) {
+	c, err := h.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if userID == 0 {
+		u, err := c.GetAuthedUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		userID = u.ID
+	}
+	return snapshot.Take(ctx, c, userID)
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package main
+
+import (
+	"errors"
+	"runtime"
+)
+
+// makeRaw returns an error, as raw mode isn't supported on this platform; keys
+// are then read a line at a time, as the terminal sends them.
+func makeRaw(fd int) (restore func() error, err error) {
+	return nil, errors.New("raw mode isn't supported on " + runtime.GOOS)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// transactionAccountColumns are the columns written for transaction accounts.
+var transactionAccountColumns = []column[pocketsmith.TransactionAccount]{
+	{"id", func(ta pocketsmith.TransactionAccount) string { return formatID(ta.ID) }},
+	{"name", func(ta pocketsmith.TransactionAccount) string { return ta.Name }},
+	{"number", func(ta pocketsmith.TransactionAccount) string { return ta.Number }},
+	{"type", func(ta pocketsmith.TransactionAccount) string { return ta.Type }},
+	{"currency", func(ta pocketsmith.TransactionAccount) string { return ta.CurrencyCode }},
+	{"balance", func(ta pocketsmith.TransactionAccount) string { return formatAmount(ta.CurrentBalance) }},
+	{"institution", func(ta pocketsmith.TransactionAccount) string { return ta.Institution.Title }},
+	{"starting_balance", func(ta pocketsmith.TransactionAccount) string { return formatAmount(ta.StartingBalance) }},
+	{"starting_date", func(ta pocketsmith.TransactionAccount) string { return ta.StartingBalanceDate }},
+}
+
+// runTransactionAccounts runs the transaction-accounts command.
+func runTransactionAccounts(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("transaction-accounts " + verb)
+	institutionID := fs.Int("institution", 0, "the id of the institution to move the transaction account to")
+	startingBalance := fs.Float64("starting-balance", 0, "the starting balance of the transaction account")
+	startingDate := fs.String("starting-date", "", "the date of the starting balance, eg. 2024-01-31")
+	balance := fs.Float64("balance", 0, "when rebalancing, the balance on a statement, at the end of --date")
+	date := fs.String("date", "", "when rebalancing, the date of the statement balance, eg. 2024-01-31")
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch verb {
+	case "list":
+		transactionAccounts, err := c.ListTransactionAccounts(ctx)
+		if err != nil {
+			return err
+		}
+		return writeList(h.stdout, h.output, transactionAccounts, transactionAccountColumns)
+
+	case "get":
+		transactionAccountID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		ta, err := c.GetTransactionAccount(
+			ctx,
+			&pocketsmith.GetTransactionAccountOptions{TransactionAccountID: transactionAccountID},
+		)
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *ta, transactionAccountColumns)
+
+	case "update":
+		transactionAccountID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		options := &pocketsmith.UpdateTransactionAccountOptions{
+			TransactionAccountID: transactionAccountID,
+			InstitutionID:        *institutionID,
+			StartingBalanceDate:  *startingDate,
+		}
+		if isSet(fs, "starting-balance") {
+			options.StartingBalance = startingBalance
+		}
+		ta, err := c.UpdateTransactionAccount(ctx, options)
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *ta, transactionAccountColumns)
+
+	case "rebalance":
+		transactionAccountID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		if err := required(fs, "balance", "date"); err != nil {
+			return err
+		}
+		ta, err := c.UpdateStartingBalanceFromStatement(
+			ctx,
+			&pocketsmith.UpdateStartingBalanceFromStatementOptions{
+				TransactionAccountID: transactionAccountID,
+				StatementDate:        *date,
+				StatementBalance:     *balance,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *ta, transactionAccountColumns)
+	}
+	return nil
+}
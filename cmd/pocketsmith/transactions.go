@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// transactionColumns are the columns written for transactions.
+var transactionColumns = []column[pocketsmith.Transaction]{
+	{"id", func(t pocketsmith.Transaction) string { return formatID(t.ID) }},
+	{"date", func(t pocketsmith.Transaction) string { return t.Date }},
+	{"payee", func(t pocketsmith.Transaction) string { return t.Payee }},
+	{"amount", func(t pocketsmith.Transaction) string { return formatAmount(t.Amount) }},
+	{"currency", func(t pocketsmith.Transaction) string { return t.TransactionAccount.CurrencyCode }},
+	{"category", func(t pocketsmith.Transaction) string { return t.Category.Title }},
+	{"labels", func(t pocketsmith.Transaction) string { return strings.Join(t.Labels, ",") }},
+	{"transaction_account", func(t pocketsmith.Transaction) string { return t.TransactionAccount.Name }},
+	{"needs_review", func(t pocketsmith.Transaction) string { return strconv.FormatBool(t.NeedsReview) }},
+}
+
+// runTransactions runs the transactions command.
+func runTransactions(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("transactions " + verb)
+
+	// list flags.
+	start := fs.String("start", "", "when listing, the earliest date, eg. 2024-01-01")
+	end := fs.String("end", "", "when listing, the latest date, eg. 2024-01-31")
+	search := fs.String("search", "", "when listing, only transactions matching the search")
+	uncategorised := fs.Bool("uncategorised", false, "when listing, only uncategorised transactions")
+	transactionType := fs.String("type", "", "when listing, only debit or credit transactions")
+
+	// create and update flags.
+	transactionAccountID := fs.Int("transaction-account", 0, "the id of the transaction account")
+	payee := fs.String("payee", "", "the payee of the transaction")
+	amount := fs.Float64("amount", 0, "the amount of the transaction")
+	date := fs.String("date", "", "the date of the transaction, eg. 2024-01-01")
+	categoryID := fs.Int("category", 0, "the id of the category")
+	labels := fs.String("labels", "", "the comma separated labels of the transaction")
+	note := fs.String("note", "", "the note of the transaction")
+	memo := fs.String("memo", "", "the memo of the transaction")
+	idempotent := fs.Bool("idempotent", false, "when creating, don't create the same transaction twice")
+
+	// shared flags.
+	needsReview := fs.Bool("needs-review", false, "only transactions that need review, or when updating, if the transaction needs review")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch verb {
+	case "list":
+		transactions, err := c.ListTransactions(ctx, &pocketsmith.ListTransactionsOptions{
+			StartDate:     *start,
+			EndDate:       *end,
+			Search:        *search,
+			Uncategorised: *uncategorised,
+			Type:          *transactionType,
+			NeedsReview:   *needsReview,
+		})
+		if err != nil {
+			return err
+		}
+		return writeList(h.stdout, h.output, transactions, transactionColumns)
+
+	case "get":
+		transactionID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		t, err := c.GetTransaction(ctx, &pocketsmith.GetTransactionOptions{
+			TransactionID: int32(transactionID),
+		})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *t, transactionColumns)
+
+	case "create":
+		if err := required(fs, "transaction-account", "payee", "amount", "date"); err != nil {
+			return err
+		}
+		t, err := c.CreateTransactionAccountTransaction(ctx, &pocketsmith.CreateTransactionAccountTransactionOptions{
+			TransactionAccountID: *transactionAccountID,
+			Payee:                *payee,
+			Amount:               *amount,
+			Date:                 *date,
+			CategoryID:           int32(*categoryID),
+			Labels:               *labels,
+			Note:                 *note,
+			Memo:                 *memo,
+			NeedsReview:          *needsReview,
+			Idempotent:           *idempotent,
+		})
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *t, transactionColumns)
+
+	case "update":
+		transactionID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		options := &pocketsmith.UpdateTransactionOptions{
+			TransactionID: int32(transactionID),
+			Payee:         *payee,
+			Amount:        *amount,
+			Date:          *date,
+			CategoryID:    int32(*categoryID),
+			Labels:        *labels,
+			Note:          *note,
+			Memo:          *memo,
+		}
+		if isSet(fs, "needs-review") {
+			options.NeedsReview = needsReview
+		}
+		t, err := c.UpdateTransaction(ctx, options)
+		if err != nil {
+			return err
+		}
+		return writeOne(h.stdout, h.output, *t, transactionColumns)
+
+	case "delete":
+		transactionID, err := id(positional)
+		if err != nil {
+			return err
+		}
+		return c.DeleteTransaction(ctx, &pocketsmith.DeleteTransactionOptions{
+			TransactionID: int32(transactionID),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// userColumns are the columns written for users.
+var userColumns = []column[pocketsmith.User]{
+	{"id", func(u pocketsmith.User) string { return formatID(u.ID) }},
+	{"login", func(u pocketsmith.User) string { return u.Login }},
+	{"name", func(u pocketsmith.User) string { return u.Name }},
+	{"email", func(u pocketsmith.User) string { return u.Email }},
+	{"time_zone", func(u pocketsmith.User) string { return u.TimeZone }},
+	{"base_currency", func(u pocketsmith.User) string { return u.BaseCurrencyCode }},
+}
+
+// runUsers runs the users command.
+func runUsers(ctx context.Context, h *handler, verb string, args []string) error {
+	fs := h.flagSet("users " + verb)
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	c, err := h.client(ctx)
+	if err != nil {
+		return err
+	}
+
+	// get the authed user, or the user with the given id.
+	var u *pocketsmith.User
+	if len(positional) == 0 {
+		u, err = c.GetAuthedUser(ctx)
+	} else {
+		var userID int
+		if userID, err = id(positional); err != nil {
+			return err
+		}
+		u, err = c.GetUser(ctx, &pocketsmith.GetUserOptions{UserID: userID})
+	}
+	if err != nil {
+		return err
+	}
+	return writeOne(h.stdout, h.output, *u, userColumns)
+}
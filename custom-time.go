@@ -12,6 +12,10 @@ type customTime struct {
 
 func (ct *customTime) UnmarshalJSON(b []byte) error {
 	str := string(b)
+	if str == "null" || str == `""` {
+		ct.Time = time.Time{}
+		return nil
+	}
 	str = str[1 : len(str)-1] // remove quotes around the date string.
 	parsedTime, err := time.Parse(customTimeFormat, str)
 	if err != nil {
@@ -20,3 +24,9 @@ func (ct *customTime) UnmarshalJSON(b []byte) error {
 	ct.Time = parsedTime
 	return nil
 }
+
+// MarshalJSON marshals the date in the same format it is unmarshalled from, so
+// that it can be round-tripped.
+func (ct customTime) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + ct.Time.Format(customTimeFormat) + `"`), nil
+}
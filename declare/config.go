@@ -0,0 +1,148 @@
+package declare
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config defines the desired categories & institutions of a user, eg.
+//
+//	delete_unlisted: true
+//	institutions:
+//	  - title: Bank
+//	    currency: aud
+//	categories:
+//	  - title: Food
+//	    colour: "#4caf50"
+//	    children:
+//	      - title: Groceries
+//	        renamed_from: [Food/Supermarket]
+//	        roll_up: true
+//	  - title: Utilities
+//	    is_bill: true
+type Config struct {
+
+	// DeleteUnlisted deletes the categories and institutions that aren't
+	// listed. Defaults to false, where they are left alone.
+	DeleteUnlisted bool `yaml:"delete_unlisted"`
+
+	Institutions []Institution `yaml:"institutions"`
+	Categories   []Category    `yaml:"categories"`
+}
+
+// Institution defines a desired institution.
+type Institution struct {
+	Title    string `yaml:"title"`
+	Currency string `yaml:"currency"`
+
+	// RenamedFrom are the previous titles of the institution, so that it is
+	// renamed, rather than another created.
+	RenamedFrom []string `yaml:"renamed_from"`
+}
+
+// Category defines a desired category. Fields that aren't set are left
+// unchanged.
+type Category struct {
+	Title           string `yaml:"title"`
+	Colour          string `yaml:"colour"`
+	IsTransfer      *bool  `yaml:"is_transfer"`
+	IsBill          *bool  `yaml:"is_bill"`
+	RollUp          *bool  `yaml:"roll_up"`
+	RefundBehaviour string `yaml:"refund_behaviour"`
+
+	// RenamedFrom are the previous paths of the category, eg.
+	// "Food/Supermarket", so that it is renamed (or moved), keeping its
+	// transactions, rather than another created.
+	RenamedFrom []string `yaml:"renamed_from"`
+
+	Children []Category `yaml:"children"`
+}
+
+// Load reads the config in the YAML file at the given path.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, ErrConfigFailedRead{path, err}
+	}
+	defer f.Close()
+	c, err := Parse(f)
+	if err != nil {
+		return nil, ErrConfigFailedRead{path, err}
+	}
+	return c, nil
+}
+
+// Parse reads a YAML config from r, and validates it. Unknown fields are
+// rejected, so that typos aren't silently ignored.
+func Parse(r io.Reader) (*Config, error) {
+	var c Config
+	d := yaml.NewDecoder(r)
+	d.KnownFields(true)
+	if err := d.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// validate returns an ErrConfigInvalid if the config has missing titles, or
+// lists the same institution or category path twice.
+func (c *Config) validate() error {
+	institutions := make(map[string]bool)
+	for _, i := range c.Institutions {
+		if strings.TrimSpace(i.Title) == "" {
+			return ErrConfigInvalid{"an institution is missing a title"}
+		}
+		if key := strings.ToLower(i.Title); institutions[key] {
+			return ErrConfigInvalid{"institution " + i.Title + " is listed twice"}
+		} else {
+			institutions[key] = true
+		}
+	}
+	paths := make(map[string]bool)
+	var check func(categories []Category, parent string) error
+	check = func(categories []Category, parent string) error {
+		for _, cat := range categories {
+			if strings.TrimSpace(cat.Title) == "" {
+				return ErrConfigInvalid{"a category under " + quoteParent(parent) + " is missing a title"}
+			}
+			if strings.Contains(cat.Title, "/") {
+				return ErrConfigInvalid{"category " + cat.Title + " can't contain a /"}
+			}
+			path := join(parent, cat.Title)
+			if key := strings.ToLower(path); paths[key] {
+				return ErrConfigInvalid{"category " + path + " is listed twice"}
+			} else {
+				paths[key] = true
+			}
+			if err := check(cat.Children, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(c.Categories, "")
+}
+
+// quoteParent returns the given parent path for an error message.
+func quoteParent(parent string) string {
+	if parent == "" {
+		return "the top level"
+	}
+	return parent
+}
+
+// join returns the path of a category with the given title, under the given
+// parent path.
+func join(parent, title string) string {
+	if parent == "" {
+		return title
+	}
+	return parent + "/" + title
+}
@@ -0,0 +1,624 @@
+// Package declare converges the categories and institutions of a PocketSmith
+// user on those declared in a YAML file, so that they can be kept in version
+// control, and kept the same across users.
+//
+// Converging is done in two steps; Plan compares the Config against what the
+// user has and returns a Report of the steps needed to converge (a dry-run),
+// then Apply runs those steps. Categories are matched by their path (eg.
+// "Food/Groceries"), then by any path they were renamed from, so that renamed
+// and moved categories are updated in place, keeping their transactions,
+// rather than deleted and created again. A category with the same title
+// elsewhere in the tree isn't matched; moving it needs a renamed_from.
+//
+// Deleting is destructive; a deleted category is removed from its
+// transactions, leaving them uncategorised, and a deleted institution takes
+// its accounts and their transactions with it. Delete steps carry a Warning
+// saying so.
+package declare
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// The name of the tracer output in the traces.
+const tracerName = "pocketsmith-go/declare"
+
+// API defines the parts of the *pocketsmith.Client used by Plan & Apply.
+type API interface {
+	ListInstitutionsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListInstitutionsForUser,
+	) (pocketsmith.Institutions, error)
+	ListCategoriesForUser(
+		ctx context.Context,
+		options *pocketsmith.ListCategoriesForUserOptions,
+	) (pocketsmith.Categories, error)
+	CreateInstitutionForUser(
+		ctx context.Context,
+		options *pocketsmith.CreateInstitutionOptionsForUser,
+	) (*pocketsmith.Institution, error)
+	UpdateInstitution(
+		ctx context.Context,
+		options *pocketsmith.UpdateInstitutionOptions,
+	) (*pocketsmith.Institution, error)
+	DeleteInstitution(ctx context.Context, options *pocketsmith.DeleteInstitutionOptions) error
+	CreateCategoryForUser(
+		ctx context.Context,
+		options *pocketsmith.CreateCategoryForUserOptions,
+	) (*pocketsmith.Category, error)
+	UpdateCategory(
+		ctx context.Context,
+		options *pocketsmith.UpdateCategoryOptions,
+	) (*pocketsmith.Category, error)
+	DeleteCategory(ctx context.Context, options *pocketsmith.DeleteCategoryOptions) error
+}
+
+// Action defines what a Step does.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Kind defines the kind of entity a Step changes.
+type Kind string
+
+const (
+	KindInstitution Kind = "institution"
+	KindCategory    Kind = "category"
+)
+
+// Status defines the status of a Step.
+type Status string
+
+const (
+	StatusPlanned Status = "planned" // The step will be applied.
+	StatusApplied Status = "applied" // The step was applied.
+	StatusFailed  Status = "failed"  // The step failed to be applied.
+)
+
+// Field defines a field changed by a Step.
+type Field struct {
+	Name string `json:"name" yaml:"name"`
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to"   yaml:"to"`
+}
+
+// Step defines a single change needed to converge.
+type Step struct {
+	Action Action  `json:"action"           yaml:"action"`
+	Kind   Kind    `json:"kind"             yaml:"kind"`
+	Name   string  `json:"name"             yaml:"name"`             // The title of the institution, or path of the category; the existing one when deleting.
+	ID     int     `json:"id"               yaml:"id"`               // The id of the entity; set once created.
+	Fields []Field `json:"fields,omitempty" yaml:"fields,omitempty"` // The fields changed by an update, or set by a create.
+	Status Status  `json:"status"           yaml:"status"`
+	Err    error   `json:"-"                yaml:"-"`               // The error returned when applying the step, if any.
+	Error  string  `json:"error,omitempty"  yaml:"error,omitempty"` // The message of Err, for output.
+
+	// Warning describes what else a step changes, eg. the transactions left
+	// uncategorised when deleting a category.
+	Warning string `json:"warning,omitempty" yaml:"warning,omitempty"`
+
+	// apply.
+	parent      *Step // The step creating the parent category, if any.
+	parentID    int32 // The id of the existing parent category, if any.
+	institution *pocketsmith.CreateInstitutionOptions
+	category    *pocketsmith.CreateCategoryOptions
+	update      *pocketsmith.UpdateCategoryOptions
+	updateInst  *pocketsmith.UpdateInstitutionOptions
+}
+
+// String returns the step as a single line.
+func (s *Step) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s", s.Action, s.Kind, s.Name)
+	if s.ID != 0 {
+		fmt.Fprintf(&b, " (%v)", s.ID)
+	}
+	fields := make([]string, 0, len(s.Fields))
+	for _, f := range s.Fields {
+		if s.Action == ActionCreate {
+			fields = append(fields, fmt.Sprintf("%s=%s", f.Name, f.To))
+			continue
+		}
+		fields = append(fields, fmt.Sprintf("%s %s→%s", f.Name, f.From, f.To))
+	}
+	if len(fields) > 0 {
+		fmt.Fprintf(&b, ": %s", strings.Join(fields, ", "))
+	}
+	return b.String()
+}
+
+// Report defines the steps needed to converge a user on a Config, in the
+// order they are applied, and the outcome of applying them.
+type Report struct {
+	UserID int
+	Steps  []*Step
+}
+
+// Count returns the number of steps in the report with the given action.
+func (p *Report) Count(action Action) (n int) {
+	for _, s := range p.Steps {
+		if s.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteTo writes a human readable summary of the report to w.
+func (p *Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, s := range p.Steps {
+		status := ""
+		if s.Status != StatusPlanned {
+			status = fmt.Sprintf("%-8s ", s.Status)
+		}
+		fmt.Fprintf(&b, "%s%s", status, s)
+		if s.Err != nil {
+			fmt.Fprintf(&b, " (%v)", s.Err)
+		}
+		b.WriteString("\n")
+		if s.Warning != "" {
+			fmt.Fprintf(&b, "  warning: %s\n", s.Warning)
+		}
+	}
+	fmt.Fprintf(
+		&b,
+		"%v to create, %v to update, %v to delete\n",
+		p.Count(ActionCreate),
+		p.Count(ActionUpdate),
+		p.Count(ActionDelete),
+	)
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// existing defines an existing category, flattened from the category tree.
+type existing struct {
+	category pocketsmith.Category
+	path     string
+	parentID int32
+	depth    int
+}
+
+// Plan compares the given config against the categories & institutions of
+// the given user, and returns a report of the steps needed to converge on it.
+// Nothing is changed; this is a dry-run.
+func Plan(
+	ctx context.Context,
+	api API,
+	userID int,
+	config *Config,
+) (report *Report, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Plan")
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to plan: %v", err))
+			span.RecordError(err)
+		}
+	}()
+
+	institutions, err := api.ListInstitutionsForUser(
+		newCtx,
+		&pocketsmith.ListInstitutionsForUser{UserID: userID},
+	)
+	if err != nil {
+		return nil, ErrPlanFailedList{"institutions", err}
+	}
+	categories, err := api.ListCategoriesForUser(
+		newCtx,
+		&pocketsmith.ListCategoriesForUserOptions{UserID: userID},
+	)
+	if err != nil {
+		return nil, ErrPlanFailedList{"categories", err}
+	}
+	report = &Report{UserID: userID}
+	deletes := planInstitutions(report, config, institutions)
+	deletes = append(planCategories(report, config, categories), deletes...)
+	report.Steps = append(report.Steps, deletes...)
+	span.SetAttributes(attribute.Int("steps", len(report.Steps)))
+	return report, nil
+}
+
+// planInstitutions adds the steps to create & update institutions to the
+// given report, returning the steps to delete them.
+func planInstitutions(
+	report *Report,
+	config *Config,
+	institutions pocketsmith.Institutions,
+) (deletes []*Step) {
+	byTitle := make(map[string]pocketsmith.Institution, len(institutions))
+	for _, i := range institutions {
+		byTitle[strings.ToLower(i.Title)] = i
+	}
+	matched := make(map[int]bool)
+	match := func(d Institution) (pocketsmith.Institution, bool) {
+		for _, title := range append([]string{d.Title}, d.RenamedFrom...) {
+			if i, ok := byTitle[strings.ToLower(title)]; ok && !matched[i.ID] {
+				return i, true
+			}
+		}
+		return pocketsmith.Institution{}, false
+	}
+	for _, d := range config.Institutions {
+		i, ok := match(d)
+		if !ok {
+			report.Steps = append(report.Steps, &Step{
+				Action: ActionCreate,
+				Kind:   KindInstitution,
+				Name:   d.Title,
+				Fields: []Field{{Name: "currency", To: d.Currency}},
+				Status: StatusPlanned,
+				institution: &pocketsmith.CreateInstitutionOptions{
+					Title:        d.Title,
+					CurrencyCode: d.Currency,
+				},
+			})
+			continue
+		}
+		matched[i.ID] = true
+		update := &pocketsmith.UpdateInstitutionOptions{InstitutionID: i.ID}
+		var fields []Field
+		if i.Title != d.Title {
+			fields = append(fields, Field{"title", i.Title, d.Title})
+			update.Title = d.Title
+		}
+		if d.Currency != "" && !strings.EqualFold(i.CurrencyCode, d.Currency) {
+			fields = append(fields, Field{"currency", i.CurrencyCode, d.Currency})
+			update.CurrencyCode = d.Currency
+		}
+		if len(fields) > 0 {
+			report.Steps = append(report.Steps, &Step{
+				Action:     ActionUpdate,
+				Kind:       KindInstitution,
+				Name:       d.Title,
+				ID:         i.ID,
+				Fields:     fields,
+				Status:     StatusPlanned,
+				updateInst: update,
+			})
+		}
+	}
+	if !config.DeleteUnlisted {
+		return nil
+	}
+	for _, i := range institutions {
+		if !matched[i.ID] {
+			deletes = append(deletes, &Step{
+				Action:  ActionDelete,
+				Kind:    KindInstitution,
+				Name:    i.Title,
+				ID:      i.ID,
+				Status:  StatusPlanned,
+				Warning: "deletes its accounts, and their transactions",
+			})
+		}
+	}
+	return deletes
+}
+
+// desired defines a desired category, flattened from the config.
+type desired struct {
+	category *Category
+	path     string
+	parent   *desired
+	match    *existing // The existing category it converges, if any.
+	step     *Step     // The step creating it, if any.
+}
+
+// planCategories adds the steps to create & update categories to the given
+// report, returning the steps to delete them.
+func planCategories(
+	report *Report,
+	config *Config,
+	categories pocketsmith.Categories,
+) (deletes []*Step) {
+
+	// flatten the existing & desired trees.
+	var have []*existing
+	tree := pocketsmith.NewCategoryTree(categories)
+	for _, c := range tree.Flatten() {
+		e := &existing{category: *c.Category, path: c.Path, depth: c.Depth}
+		if parent, ok := tree.Parent(c.ID); ok {
+			e.parentID = parent.ID
+		}
+		have = append(have, e)
+	}
+	var want []*desired
+	var flatten func(categories []Category, parent *desired)
+	flatten = func(categories []Category, parent *desired) {
+		for i := range categories {
+			d := &desired{category: &categories[i], parent: parent}
+			if parent != nil {
+				d.path = join(parent.path, categories[i].Title)
+			} else {
+				d.path = categories[i].Title
+			}
+			want = append(want, d)
+			flatten(categories[i].Children, d)
+		}
+	}
+	flatten(config.Categories, nil)
+
+	// match desired categories to existing ones; by path, then by the paths
+	// they were renamed from, or their path under their renamed parent.
+	//
+	// NOTE: categories aren't matched by title alone, since the same title can
+	// mean something different under another parent, eg. "Food/Other" and
+	// "Transport/Other".
+	matched := make(map[int32]bool)
+	byPath := make(map[string]*existing, len(have))
+	for _, e := range have {
+		byPath[strings.ToLower(e.path)] = e
+	}
+	assign := func(d *desired, e *existing) bool {
+		if e == nil || matched[e.category.ID] || d.match != nil {
+			return false
+		}
+		d.match, matched[e.category.ID] = e, true
+		return true
+	}
+	for _, d := range want {
+		assign(d, byPath[strings.ToLower(d.path)])
+	}
+	for _, d := range want {
+		for _, path := range d.category.RenamedFrom {
+			if assign(d, byPath[strings.ToLower(path)]) {
+				break
+			}
+		}
+		if d.parent != nil && d.parent.match != nil {
+			assign(d, byPath[strings.ToLower(join(d.parent.match.path, d.category.Title))])
+		}
+	}
+
+	// create or update each desired category, parents first.
+	for _, d := range want {
+		if d.match == nil {
+			d.step = createCategory(d)
+		} else {
+			d.step = updateCategory(d)
+		}
+		if d.step != nil {
+			report.Steps = append(report.Steps, d.step)
+		}
+	}
+
+	// delete the unmatched categories, children first.
+	if !config.DeleteUnlisted {
+		return nil
+	}
+	sort.SliceStable(have, func(i, j int) bool { return have[i].depth > have[j].depth })
+	for _, e := range have {
+		if !matched[e.category.ID] {
+			deletes = append(deletes, &Step{
+				Action:  ActionDelete,
+				Kind:    KindCategory,
+				Name:    e.path,
+				ID:      int(e.category.ID),
+				Status:  StatusPlanned,
+				Warning: "leaves its transactions uncategorised",
+			})
+		}
+	}
+	return deletes
+}
+
+// createCategory returns the step creating the given desired category.
+func createCategory(d *desired) *Step {
+	c := d.category
+	s := &Step{
+		Action: ActionCreate,
+		Kind:   KindCategory,
+		Name:   d.path,
+		Status: StatusPlanned,
+		category: &pocketsmith.CreateCategoryOptions{
+			Title:           c.Title,
+			Colour:          c.Colour,
+			RefundBehaviour: c.RefundBehaviour,
+		},
+	}
+	if d.parent != nil {
+		if d.parent.match != nil {
+			s.parentID = d.parent.match.category.ID
+		} else {
+			s.parent = d.parent.step
+		}
+	}
+	add := func(name, value string) {
+		if value != "" {
+			s.Fields = append(s.Fields, Field{Name: name, To: value})
+		}
+	}
+	add("colour", c.Colour)
+	if c.IsTransfer != nil {
+		s.category.IsTransfer = *c.IsTransfer
+		add("is_transfer", strconv.FormatBool(*c.IsTransfer))
+	}
+	if c.IsBill != nil {
+		s.category.IsBill = *c.IsBill
+		add("is_bill", strconv.FormatBool(*c.IsBill))
+	}
+	if c.RollUp != nil {
+		s.category.RollUp = *c.RollUp
+		add("roll_up", strconv.FormatBool(*c.RollUp))
+	}
+	add("refund_behaviour", c.RefundBehaviour)
+	return s
+}
+
+// updateCategory returns the step updating the existing category matched to
+// the given desired category, or nil if nothing needs to change.
+func updateCategory(d *desired) *Step {
+	c, e := d.category, d.match
+	update := &pocketsmith.UpdateCategoryOptions{CategoryID: e.category.ID}
+	s := &Step{
+		Action: ActionUpdate,
+		Kind:   KindCategory,
+		Name:   d.path,
+		ID:     int(e.category.ID),
+		Status: StatusPlanned,
+		update: update,
+	}
+	if e.category.Title != c.Title {
+		s.Fields = append(s.Fields, Field{"title", e.category.Title, c.Title})
+		update.Title = c.Title
+	}
+
+	// move to another parent.
+	from := parentPath(e.path)
+	to := ""
+	if d.parent != nil {
+		to = d.parent.path
+	}
+	switch {
+	case d.parent == nil && e.parentID != 0:
+		update.TopLevel = true
+	case d.parent != nil && d.parent.match == nil:
+		s.parent = d.parent.step
+	case d.parent != nil && d.parent.match.category.ID != e.parentID:
+		id := d.parent.match.category.ID
+		update.ParentID = &id
+	}
+	if update.TopLevel || update.ParentID != nil || s.parent != nil {
+		s.Fields = append(s.Fields, Field{"parent", display(from), display(to)})
+	}
+
+	if c.Colour != "" && !strings.EqualFold(c.Colour, e.category.Colour) {
+		s.Fields = append(s.Fields, Field{"colour", e.category.Colour, c.Colour})
+		update.Colour = c.Colour
+	}
+	flag := func(name string, want *bool, have bool, set **bool) {
+		if want != nil && *want != have {
+			s.Fields = append(s.Fields, Field{name, strconv.FormatBool(have), strconv.FormatBool(*want)})
+			*set = want
+		}
+	}
+	flag("is_transfer", c.IsTransfer, e.category.IsTransfer, &update.IsTransfer)
+	flag("is_bill", c.IsBill, e.category.IsBill, &update.IsBill)
+	flag("roll_up", c.RollUp, e.category.RollUp, &update.RollUp)
+	if c.RefundBehaviour != "" && c.RefundBehaviour != e.category.RefundBehaviour {
+		s.Fields = append(s.Fields, Field{"refund_behaviour", e.category.RefundBehaviour, c.RefundBehaviour})
+		update.RefundBehaviour = c.RefundBehaviour
+	}
+	if len(s.Fields) == 0 {
+		return nil
+	}
+	return s
+}
+
+// Apply runs the planned (or failed) steps in the given report, in order,
+// updating the status of each step. Steps that fail don't stop the others;
+// their error is recorded against the step, and any categories created under
+// a category that failed to be created fail too. Applying a report again only
+// retries the steps that failed.
+func Apply(ctx context.Context, api API, report *Report) (*Report, error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Apply")
+	defer span.End()
+
+	failed := 0
+	for _, s := range report.Steps {
+		if s.Status == StatusApplied {
+			continue
+		}
+		if err := apply(newCtx, api, report.UserID, s); err != nil {
+			s.Status, s.Err, s.Error = StatusFailed, err, err.Error()
+			failed++
+			continue
+		}
+		s.Status, s.Err, s.Error = StatusApplied, nil, ""
+	}
+	span.SetAttributes(attribute.Int("steps", len(report.Steps)), attribute.Int("failed", failed))
+	if failed > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to apply %v steps", failed))
+	}
+	return report, nil
+}
+
+// apply runs the given step.
+func apply(ctx context.Context, api API, userID int, s *Step) error {
+
+	// resolve the parent being created.
+	parentID := s.parentID
+	if s.parent != nil {
+		if s.parent.Status != StatusApplied {
+			return ErrApplyParentFailed{s.parent.Name}
+		}
+		parentID = int32(s.parent.ID)
+	}
+
+	switch {
+	case s.Kind == KindInstitution && s.Action == ActionCreate:
+		i, err := api.CreateInstitutionForUser(ctx, &pocketsmith.CreateInstitutionOptionsForUser{
+			UserID:                   userID,
+			CreateInstitutionOptions: *s.institution,
+		})
+		if err != nil {
+			return err
+		}
+		s.ID = i.ID
+	case s.Kind == KindInstitution && s.Action == ActionUpdate:
+		_, err := api.UpdateInstitution(ctx, s.updateInst)
+		return err
+	case s.Kind == KindInstitution && s.Action == ActionDelete:
+		return api.DeleteInstitution(ctx, &pocketsmith.DeleteInstitutionOptions{InstitutionID: s.ID})
+	case s.Kind == KindCategory && s.Action == ActionCreate:
+		options := *s.category
+		if parentID != 0 {
+			options.ParentID = strconv.Itoa(int(parentID))
+		}
+		c, err := api.CreateCategoryForUser(ctx, &pocketsmith.CreateCategoryForUserOptions{
+			UserID:                userID,
+			CreateCategoryOptions: options,
+		})
+		if err != nil {
+			return err
+		}
+		s.ID = int(c.ID)
+	case s.Kind == KindCategory && s.Action == ActionUpdate:
+		options := *s.update
+		if s.parent != nil {
+			options.ParentID = &parentID
+		}
+		_, err := api.UpdateCategory(ctx, &options)
+		return err
+	case s.Kind == KindCategory && s.Action == ActionDelete:
+		return api.DeleteCategory(ctx, &pocketsmith.DeleteCategoryOptions{CategoryID: int32(s.ID)})
+	}
+	return nil
+}
+
+// parentPath returns the path of the parent in the given category path.
+func parentPath(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// display returns the given parent path for display.
+func display(path string) string {
+	if path == "" {
+		return "(top level)"
+	}
+	return path
+}
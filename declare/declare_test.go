@@ -0,0 +1,402 @@
+package declare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// mockAPI is a mock implementation of the API interface. It returns the
+// entities it holds, and records the calls made to change them.
+type mockAPI struct {
+	institutions pocketsmith.Institutions
+	categories   pocketsmith.Categories
+	fail         map[string]bool // The titles of the categories that fail to be created.
+
+	// calls.
+	nextID int
+	calls  []string
+}
+
+func (m *mockAPI) ListInstitutionsForUser(
+	context.Context,
+	*pocketsmith.ListInstitutionsForUser,
+) (pocketsmith.Institutions, error) {
+	return m.institutions, nil
+}
+
+func (m *mockAPI) ListCategoriesForUser(
+	context.Context,
+	*pocketsmith.ListCategoriesForUserOptions,
+) (pocketsmith.Categories, error) {
+	return m.categories, nil
+}
+
+func (m *mockAPI) CreateInstitutionForUser(
+	_ context.Context,
+	options *pocketsmith.CreateInstitutionOptionsForUser,
+) (*pocketsmith.Institution, error) {
+	m.nextID++
+	m.calls = append(m.calls, fmt.Sprintf("create institution %s", options.Title))
+	return &pocketsmith.Institution{ID: 100 + m.nextID, Title: options.Title}, nil
+}
+
+func (m *mockAPI) UpdateInstitution(
+	_ context.Context,
+	options *pocketsmith.UpdateInstitutionOptions,
+) (*pocketsmith.Institution, error) {
+	m.calls = append(m.calls, fmt.Sprintf("update institution %v title=%s", options.InstitutionID, options.Title))
+	return &pocketsmith.Institution{ID: options.InstitutionID}, nil
+}
+
+func (m *mockAPI) DeleteInstitution(
+	_ context.Context,
+	options *pocketsmith.DeleteInstitutionOptions,
+) error {
+	m.calls = append(m.calls, fmt.Sprintf("delete institution %v", options.InstitutionID))
+	return nil
+}
+
+func (m *mockAPI) CreateCategoryForUser(
+	_ context.Context,
+	options *pocketsmith.CreateCategoryForUserOptions,
+) (*pocketsmith.Category, error) {
+	if m.fail[options.Title] {
+		return nil, errors.New("boom")
+	}
+	m.nextID++
+	m.calls = append(m.calls, fmt.Sprintf("create category %s parent=%s", options.Title, options.ParentID))
+	return &pocketsmith.Category{ID: int32(100 + m.nextID), Title: options.Title}, nil
+}
+
+func (m *mockAPI) UpdateCategory(
+	_ context.Context,
+	options *pocketsmith.UpdateCategoryOptions,
+) (*pocketsmith.Category, error) {
+	parent := ""
+	if options.ParentID != nil {
+		parent = fmt.Sprint(*options.ParentID)
+	}
+	m.calls = append(m.calls, fmt.Sprintf(
+		"update category %v title=%s parent=%s top=%v",
+		options.CategoryID, options.Title, parent, options.TopLevel,
+	))
+	return &pocketsmith.Category{ID: options.CategoryID}, nil
+}
+
+func (m *mockAPI) DeleteCategory(
+	_ context.Context,
+	options *pocketsmith.DeleteCategoryOptions,
+) error {
+	m.calls = append(m.calls, fmt.Sprintf("delete category %v", options.CategoryID))
+	return nil
+}
+
+// categories returns a category tree of Food/Supermarket, Transport/Fuel and
+// Misc.
+func categories() pocketsmith.Categories {
+	return pocketsmith.Categories{
+		{ID: 1, Title: "Food", Colour: "#4caf50", Children: []*pocketsmith.Category{
+			{ID: 2, Title: "Supermarket"},
+		}},
+		{ID: 3, Title: "Transport", Children: []*pocketsmith.Category{
+			{ID: 4, Title: "Fuel"},
+		}},
+		{ID: 5, Title: "Misc"},
+	}
+}
+
+func Test_PlanApply(t *testing.T) {
+	tests := map[string]struct {
+		config    string
+		fail      map[string]bool
+		wantSteps []string
+		wantCalls []string
+	}{
+		"unchanged": {
+			config: `
+categories:
+  - title: Food
+    colour: "#4CAF50"
+    children:
+      - title: Supermarket
+`,
+		},
+		"rename and recolour": {
+			config: `
+categories:
+  - title: Food
+    colour: "#ff0000"
+    children:
+      - title: Groceries
+        renamed_from: [Food/Supermarket]
+        is_bill: false
+        roll_up: true
+`,
+			wantSteps: []string{
+				"update category Food (1): colour #4caf50→#ff0000",
+				"update category Food/Groceries (2): title Supermarket→Groceries, roll_up false→true",
+			},
+			wantCalls: []string{
+				"update category 1 title= parent= top=false",
+				"update category 2 title=Groceries parent= top=false",
+			},
+		},
+		"children follow a renamed parent": {
+			config: `
+categories:
+  - title: Travel
+    renamed_from: [Transport]
+    children:
+      - title: Fuel
+`,
+			wantSteps: []string{
+				"update category Travel (3): title Transport→Travel",
+			},
+			wantCalls: []string{
+				"update category 3 title=Travel parent= top=false",
+			},
+		},
+		"move with renamed from": {
+			config: `
+categories:
+  - title: Transport
+    children:
+      - title: Misc
+        renamed_from: [Misc]
+  - title: Fuel
+    renamed_from: [Transport/Fuel]
+`,
+			wantSteps: []string{
+				"update category Transport/Misc (5): parent (top level)→Transport",
+				"update category Fuel (4): parent Transport→(top level)",
+			},
+			wantCalls: []string{
+				"update category 5 title= parent=3 top=false",
+				"update category 4 title= parent= top=true",
+			},
+		},
+		"create under a new parent": {
+			config: `
+institutions:
+  - title: Bank
+    currency: aud
+categories:
+  - title: Home
+    colour: "#000000"
+    children:
+      - title: Rent
+        is_bill: true
+      - title: Supermarket
+        renamed_from: [Food/Supermarket]
+`,
+			wantSteps: []string{
+				"create institution Bank (101): currency=aud",
+				"create category Home (102): colour=#000000",
+				"create category Home/Rent (103): is_bill=true",
+				"update category Home/Supermarket (2): parent Food→Home",
+			},
+			wantCalls: []string{
+				"create institution Bank",
+				"create category Home parent=",
+				"create category Rent parent=102",
+				"update category 2 title= parent=102 top=false",
+			},
+		},
+		"same title under another parent": {
+			config: `
+categories:
+  - title: Food
+    children:
+      - title: Supermarket
+  - title: Misc
+    children:
+      - title: Fuel
+`,
+			wantSteps: []string{
+				"create category Misc/Fuel (101)",
+			},
+			wantCalls: []string{
+				"create category Fuel parent=5",
+			},
+		},
+		"delete unlisted": {
+			config: `
+delete_unlisted: true
+institutions:
+  - title: Credit Union
+    renamed_from: [CU]
+categories:
+  - title: Food
+`,
+			wantSteps: []string{
+				"update institution Credit Union (7): title CU→Credit Union",
+				"delete category Food/Supermarket (2)",
+				"delete category Transport/Fuel (4)",
+				"delete category Transport (3)",
+				"delete category Misc (5)",
+				"delete institution Old (8)",
+			},
+			wantCalls: []string{
+				"update institution 7 title=Credit Union",
+				"delete category 2",
+				"delete category 4",
+				"delete category 3",
+				"delete category 5",
+				"delete institution 8",
+			},
+		},
+		"parent fails": {
+			config: `
+categories:
+  - title: Home
+    children:
+      - title: Rent
+      - title: Misc
+        renamed_from: [Misc]
+`,
+			fail: map[string]bool{"Home": true},
+			wantSteps: []string{
+				"failed   create category Home (boom)",
+				"failed   create category Home/Rent (parent category Home wasn't created)",
+				"failed   update category Home/Misc (5): parent (top level)→Home (parent category Home wasn't created)",
+			},
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config, err := Parse(strings.NewReader(tt.config))
+			if err != nil {
+				t.Fatalf("Parse() returned an error: %v", err)
+			}
+			api := &mockAPI{
+				institutions: pocketsmith.Institutions{
+					{ID: 7, Title: "CU", CurrencyCode: "aud"},
+					{ID: 8, Title: "Old", CurrencyCode: "aud"},
+				},
+				categories: categories(),
+				fail:       tt.fail,
+			}
+			report, err := Plan(context.Background(), api, 1, config)
+			if err != nil {
+				t.Fatalf("Plan() returned an error: %v", err)
+			}
+			report, err = Apply(context.Background(), api, report)
+			if err != nil {
+				t.Fatalf("Apply() returned an error: %v", err)
+			}
+			var got []string
+			for _, s := range report.Steps {
+				line := s.String()
+				if s.Status == StatusFailed {
+					line = fmt.Sprintf("%-8s %s (%v)", s.Status, line, s.Error)
+				}
+				got = append(got, line)
+			}
+			if !reflect.DeepEqual(got, tt.wantSteps) {
+				t.Errorf("Plan() returned unexpected steps;\nwant=%q\ngot=%q", tt.wantSteps, got)
+			}
+			if !reflect.DeepEqual(api.calls, tt.wantCalls) {
+				t.Errorf("Apply() made unexpected calls;\nwant=%q\ngot=%q", tt.wantCalls, api.calls)
+			}
+		})
+	}
+}
+
+func Test_Report_WriteTo(t *testing.T) {
+	report := &Report{Steps: []*Step{
+		{Action: ActionCreate, Kind: KindCategory, Name: "Home", Status: StatusPlanned},
+		{Action: ActionDelete, Kind: KindCategory, Name: "Misc", ID: 5, Status: StatusApplied, Warning: "boom"},
+	}}
+	want := "create category Home\napplied  delete category Misc (5)\n  warning: boom\n1 to create, 0 to update, 1 to delete\n"
+
+	// run tests.
+	var buf bytes.Buffer
+	if _, err := report.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() returned an error: %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTo() returned unexpected output;\nwant=%q\ngot=%q", want, got)
+	}
+}
+
+func Test_Step_json(t *testing.T) {
+	step := &Step{
+		Action: ActionUpdate,
+		Kind:   KindCategory,
+		Name:   "Food",
+		ID:     5,
+		Fields: []Field{{Name: "colour", From: "#fff", To: "#000"}},
+		Status: StatusFailed,
+		Err:    errors.New("boom"),
+		Error:  "boom",
+	}
+	want := `{"action":"update","kind":"category","name":"Food","id":5,` +
+		`"fields":[{"name":"colour","from":"#fff","to":"#000"}],"status":"failed","error":"boom"}`
+
+	// run tests.
+	b, err := json.Marshal(step)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned an error: %v", err)
+	}
+	if got := string(b); got != want {
+		t.Errorf("json.Marshal() returned unexpected json;\nwant=%v\ngot=%v", want, got)
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		err    string
+	}{
+		"valid": {
+			config: "categories:\n  - title: Food\n    children:\n      - title: Groceries\n",
+		},
+		"empty": {
+			config: "",
+		},
+		"unknown field": {
+			config: "categories:\n  - title: Food\n    color: red\n",
+			err:    "yaml: unmarshal errors:\n  line 3: field color not found in type declare.Category",
+		},
+		"missing title": {
+			config: "categories:\n  - title: Food\n    children:\n      - colour: red\n",
+			err:    "invalid config; a category under Food is missing a title",
+		},
+		"slash in title": {
+			config: "categories:\n  - title: Food/Groceries\n",
+			err:    "invalid config; category Food/Groceries can't contain a /",
+		},
+		"duplicate category": {
+			config: "categories:\n  - title: Food\n  - title: food\n",
+			err:    "invalid config; category food is listed twice",
+		},
+		"duplicate institution": {
+			config: "institutions:\n  - title: Bank\n  - title: Bank\n",
+			err:    "invalid config; institution Bank is listed twice",
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.config))
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.err {
+				t.Errorf("Parse() returned unexpected error;\nwant=%q\ngot=%q", tt.err, got)
+			}
+		})
+	}
+}
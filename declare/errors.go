@@ -0,0 +1,45 @@
+package declare
+
+import (
+	"fmt"
+)
+
+// ErrConfigFailedRead is returned when a config file fails to be read.
+type ErrConfigFailedRead struct {
+	path string
+	err  error
+}
+
+func (e ErrConfigFailedRead) Error() string {
+	return fmt.Sprintf("failed to read config %s: %v", e.path, e.err)
+}
+
+// ErrConfigInvalid is returned when a config is invalid.
+type ErrConfigInvalid struct {
+	reason string
+}
+
+func (e ErrConfigInvalid) Error() string {
+	return fmt.Sprintf("invalid config; %s", e.reason)
+}
+
+// ErrPlanFailedList is returned when Plan fails to list the existing
+// categories or institutions.
+type ErrPlanFailedList struct {
+	entity string
+	err    error
+}
+
+func (e ErrPlanFailedList) Error() string {
+	return fmt.Sprintf("failed to list %s: %v", e.entity, e.err)
+}
+
+// ErrApplyParentFailed is returned when a category can't be created or moved,
+// since its parent failed to be created.
+type ErrApplyParentFailed struct {
+	parent string
+}
+
+func (e ErrApplyParentFailed) Error() string {
+	return fmt.Sprintf("parent category %s wasn't created", e.parent)
+}
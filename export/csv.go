@@ -0,0 +1,197 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// Column defines a column written by a CSVWriter.
+type Column struct {
+	Name  string                                 // The name of the column, written as the header.
+	Value func(t pocketsmith.Transaction) string // Returns the value of the column for a transaction.
+}
+
+// The columns that can be written by a CSVWriter.
+var (
+	ColumnID = Column{"id", func(t pocketsmith.Transaction) string {
+		return strconv.Itoa(int(t.ID))
+	}}
+	ColumnDate = Column{"date", func(t pocketsmith.Transaction) string {
+		return t.Date
+	}}
+	ColumnPayee = Column{"payee", func(t pocketsmith.Transaction) string {
+		return t.Payee
+	}}
+	ColumnOriginalPayee = Column{"original_payee", func(t pocketsmith.Transaction) string {
+		return t.OriginalPayee
+	}}
+	ColumnAmount = Column{"amount", func(t pocketsmith.Transaction) string {
+		return formatAmount(t.Amount)
+	}}
+	ColumnAmountInBaseCurrency = Column{"amount_in_base_currency", func(t pocketsmith.Transaction) string {
+		return formatAmount(t.AmountInBaseCurrency)
+	}}
+	ColumnCurrency = Column{"currency", func(t pocketsmith.Transaction) string {
+		return strings.ToUpper(t.TransactionAccount.CurrencyCode)
+	}}
+	ColumnCategory = Column{"category", func(t pocketsmith.Transaction) string {
+		return t.Category.Title
+	}}
+	ColumnLabels = Column{"labels", func(t pocketsmith.Transaction) string {
+		return strings.Join(t.Labels, ",")
+	}}
+	ColumnNote = Column{"note", func(t pocketsmith.Transaction) string {
+		return t.Note
+	}}
+	ColumnMemo = Column{"memo", func(t pocketsmith.Transaction) string {
+		return t.Memo
+	}}
+	ColumnChequeNumber = Column{"cheque_number", func(t pocketsmith.Transaction) string {
+		return t.ChequeNumber
+	}}
+	ColumnType = Column{"type", func(t pocketsmith.Transaction) string {
+		return t.Type
+	}}
+	ColumnStatus = Column{"status", func(t pocketsmith.Transaction) string {
+		return t.Status
+	}}
+	ColumnIsTransfer = Column{"is_transfer", func(t pocketsmith.Transaction) string {
+		return strconv.FormatBool(t.IsTransfer)
+	}}
+	ColumnNeedsReview = Column{"needs_review", func(t pocketsmith.Transaction) string {
+		return strconv.FormatBool(t.NeedsReview)
+	}}
+	ColumnClosingBalance = Column{"closing_balance", func(t pocketsmith.Transaction) string {
+		return formatAmount(t.ClosingBalance)
+	}}
+	ColumnTransactionAccount = Column{"transaction_account", func(t pocketsmith.Transaction) string {
+		return t.TransactionAccount.Name
+	}}
+	ColumnTransactionAccountNumber = Column{"transaction_account_number", func(t pocketsmith.Transaction) string {
+		return t.TransactionAccount.Number
+	}}
+	ColumnInstitution = Column{"institution", func(t pocketsmith.Transaction) string {
+		return t.TransactionAccount.Institution.Title
+	}}
+)
+
+// Columns are all the columns that can be written by a CSVWriter.
+var Columns = []Column{
+	ColumnID,
+	ColumnDate,
+	ColumnPayee,
+	ColumnOriginalPayee,
+	ColumnAmount,
+	ColumnAmountInBaseCurrency,
+	ColumnCurrency,
+	ColumnCategory,
+	ColumnLabels,
+	ColumnNote,
+	ColumnMemo,
+	ColumnChequeNumber,
+	ColumnType,
+	ColumnStatus,
+	ColumnIsTransfer,
+	ColumnNeedsReview,
+	ColumnClosingBalance,
+	ColumnTransactionAccount,
+	ColumnTransactionAccountNumber,
+	ColumnInstitution,
+}
+
+// DefaultColumns are the columns written by a CSVWriter when none are given.
+var DefaultColumns = []Column{
+	ColumnID,
+	ColumnDate,
+	ColumnPayee,
+	ColumnAmount,
+	ColumnCurrency,
+	ColumnCategory,
+	ColumnLabels,
+	ColumnNote,
+	ColumnTransactionAccount,
+	ColumnInstitution,
+}
+
+// ColumnsByName returns the columns with the given names, in the given order.
+func ColumnsByName(names ...string) ([]Column, error) {
+	byName := make(map[string]Column, len(Columns))
+	for _, c := range Columns {
+		byName[c.Name] = c
+	}
+	var (
+		out     []Column
+		unknown []string
+	)
+	for _, name := range names {
+		c, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		out = append(out, c)
+	}
+	if len(unknown) > 0 {
+		return nil, ErrUnknownColumns{unknown}
+	}
+	return out, nil
+}
+
+// CSVWriter writes transactions as CSV, with a header row.
+type CSVWriter struct {
+	w       *csv.Writer
+	columns []Column
+	header  bool // If the header has been written.
+}
+
+// NewCSVWriter returns a CSVWriter that writes the given columns to w.
+func NewCSVWriter(w io.Writer, columns ...Column) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+// Write writes the given transactions, writing the header first if it hasn't
+// been written yet.
+func (w *CSVWriter) Write(transactions pocketsmith.Transactions) error {
+	if !w.header {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+	record := make([]string, len(w.columns))
+	for _, t := range transactions {
+		for i, c := range w.columns {
+			record[i] = c.Value(t)
+		}
+		if err := w.w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// Close writes the header, if nothing has been written, and flushes the
+// writer.
+func (w *CSVWriter) Close() error {
+	if !w.header {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// writeHeader writes the header row.
+func (w *CSVWriter) writeHeader() error {
+	header := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		header[i] = c.Name
+	}
+	w.header = true
+	return w.w.Write(header)
+}
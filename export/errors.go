@@ -0,0 +1,24 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned when an export format isn't supported.
+type ErrUnsupportedFormat struct {
+	format Format
+}
+
+func (e ErrUnsupportedFormat) Error() string {
+	return fmt.Sprintf("unsupported export format %q", e.format)
+}
+
+// ErrUnknownColumns is returned when a column name isn't known.
+type ErrUnknownColumns struct {
+	names []string
+}
+
+func (e ErrUnknownColumns) Error() string {
+	return fmt.Sprintf("unknown columns: %s", strings.Join(e.names, ", "))
+}
@@ -0,0 +1,82 @@
+// Package export writes PocketSmith transactions to common file formats.
+//
+// Each format is a Writer, whose Write method accepts a page of transactions.
+// This means a Writer can be given directly to the paginated list calls on
+// the client, so that transactions are streamed to the output as they are
+// returned from the API:
+//
+//	w := export.NewCSVWriter(os.Stdout, export.DefaultColumns...)
+//	err := c.ListTransactionsForUserPages(ctx, options, w.Write)
+//	...
+//	err = w.Close()
+//
+// A JournalWriter also writes transactions as balanced entries for
+// plain-text accounting tools (ledger, hledger and beancount).
+package export
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// Writer writes transactions in an export format.
+type Writer interface {
+
+	// Write writes the given transactions.
+	Write(transactions pocketsmith.Transactions) error
+
+	// Close flushes anything buffered by the writer. It does not close the
+	// underlying io.Writer.
+	Close() error
+}
+
+// Format defines a supported export format.
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatOFX   Format = "ofx"
+	FormatQIF   Format = "qif"
+	FormatJSONL Format = "jsonl"
+)
+
+// Formats are all the supported export formats.
+var Formats = []Format{FormatCSV, FormatOFX, FormatQIF, FormatJSONL}
+
+// NewWriter returns a Writer for the given format, writing to w. The given
+// columns are only used by FormatCSV; if none are given, DefaultColumns are
+// used.
+func NewWriter(format Format, w io.Writer, columns ...Column) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		if len(columns) == 0 {
+			columns = DefaultColumns
+		}
+		return NewCSVWriter(w, columns...), nil
+	case FormatOFX:
+		return NewOFXWriter(w), nil
+	case FormatQIF:
+		return NewQIFWriter(w), nil
+	case FormatJSONL:
+		return NewJSONLWriter(w), nil
+	}
+	return nil, ErrUnsupportedFormat{format}
+}
+
+// formatAmount formats the given amount with two decimal places.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// firstNonEmpty returns the first of the given strings that isn't empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
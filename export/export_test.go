@@ -0,0 +1,301 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// transactions used across tests in this package.
+var transactions = pocketsmith.Transactions{
+	{
+		ID:       1,
+		Date:     "2024-01-02",
+		Payee:    "Coffee, Co",
+		Amount:   -4.5,
+		Labels:   []string{"work", "coffee"},
+		Category: pocketsmith.Category{Title: "Food"},
+		TransactionAccount: pocketsmith.TransactionAccount{
+			ID:           10,
+			Name:         "Everyday",
+			Type:         "bank",
+			CurrencyCode: "aud",
+			Institution:  pocketsmith.Institution{ID: 5, Title: "Bank"},
+		},
+	},
+	{
+		ID:     2,
+		Date:   "2024-01-01",
+		Payee:  "Salary",
+		Amount: 1000,
+		TransactionAccount: pocketsmith.TransactionAccount{
+			ID:           10,
+			Name:         "Everyday",
+			Type:         "bank",
+			CurrencyCode: "aud",
+			Institution:  pocketsmith.Institution{ID: 5, Title: "Bank"},
+		},
+	},
+}
+
+func Test_Writers(t *testing.T) {
+	tests := map[string]struct {
+		format  Format
+		columns []string
+		want    []string
+	}{
+		"csv with configured columns": {
+			format:  FormatCSV,
+			columns: []string{"date", "payee", "amount", "labels"},
+			want: []string{
+				"date,payee,amount,labels\n",
+				"2024-01-02,\"Coffee, Co\",-4.50,\"work,coffee\"\n",
+				"2024-01-01,Salary,1000.00,\n",
+			},
+		},
+		"ofx": {
+			format: FormatOFX,
+			want: []string{
+				`<?OFX OFXHEADER="200" VERSION="220"`,
+				"<CURDEF>AUD</CURDEF>",
+				"<ACCTTYPE>CHECKING</ACCTTYPE>",
+				"<DTSTART>20240101</DTSTART>",
+				"<DTEND>20240102</DTEND>",
+				"<TRNTYPE>CREDIT</TRNTYPE>",
+				"<TRNAMT>-4.50</TRNAMT>",
+				"<NAME>Coffee, Co</NAME>",
+			},
+		},
+		"qif": {
+			format: FormatQIF,
+			want: []string{
+				"!Account\nNEveryday\nTBank\nDBank\n^\n!Type:Bank\n",
+				"D01/02/2024\nT-4.50\nPCoffee, Co\nLFood\n^\n",
+			},
+		},
+		"jsonl": {
+			format: FormatJSONL,
+			want: []string{
+				`{"id":1,"date":"2024-01-02","payee":"Coffee, Co"`,
+				`{"id":2,"date":"2024-01-01","payee":"Salary"`,
+			},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			columns, err := ColumnsByName(tt.columns...)
+			if err != nil {
+				t.Fatalf("ColumnsByName() returned an error; error=%v", err)
+			}
+			var buf bytes.Buffer
+			w, err := NewWriter(tt.format, &buf, columns...)
+			if err != nil {
+				t.Fatalf("NewWriter() returned an error; error=%v", err)
+			}
+			if ofx, ok := w.(*OFXWriter); ok {
+				ofx.now = func() time.Time { return time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC) }
+			}
+
+			// write transactions, one page at a time.
+			for _, page := range []pocketsmith.Transactions{transactions[:1], transactions[1:]} {
+				if err := w.Write(page); err != nil {
+					t.Fatalf("Write() returned an error; error=%v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() returned an error; error=%v", err)
+			}
+
+			// is there a mismatch from what we're expecting vs what we've got?
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Write() returned unexpected output;\nwant=%q\ngot=%s\n", want, got)
+				}
+			}
+		})
+	}
+}
+
+func Test_ColumnsByName(t *testing.T) {
+	_, err := ColumnsByName("date", "nope")
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("ColumnsByName() returned an unexpected error; got=%v", err)
+	}
+}
+
+func Test_JournalWriter(t *testing.T) {
+
+	// setup categories; "Groceries" is a child of "Food".
+	parentID := 1
+	categories := pocketsmith.Categories{
+		{
+			ID:    1,
+			Title: "Food",
+			Children: []*pocketsmith.Category{
+				{ID: 2, Title: "Groceries", ParentID: &parentID},
+			},
+		},
+		{ID: 3, Title: "Salary"},
+		{ID: 4, Title: "Transfer", IsTransfer: true},
+	}
+
+	// setup transactions.
+	everyday := pocketsmith.TransactionAccount{
+		ID:           10,
+		Name:         "Everyday",
+		Type:         "bank",
+		CurrencyCode: "aud",
+		Institution:  pocketsmith.Institution{Title: "My Bank"},
+	}
+	card := pocketsmith.TransactionAccount{
+		ID:           11,
+		Name:         "Travel Card",
+		Type:         "credits",
+		CurrencyCode: "usd",
+	}
+	transactions := pocketsmith.Transactions{
+		{
+			ID:                 1,
+			Date:               "2024-01-02",
+			Payee:              "Market",
+			Amount:             -12.5,
+			Labels:             []string{"weekly shop"},
+			Category:           pocketsmith.Category{ID: 2, Title: "Groceries"},
+			TransactionAccount: everyday,
+		},
+		{
+			ID:                 2,
+			Date:               "2024-01-03",
+			Payee:              "Employer",
+			Amount:             1000,
+			Category:           pocketsmith.Category{ID: 3, Title: "Salary"},
+			TransactionAccount: everyday,
+		},
+		{
+			ID:                   3,
+			Date:                 "2024-01-04",
+			Payee:                "Diner",
+			Amount:               -10,
+			AmountInBaseCurrency: -15,
+			Category:             pocketsmith.Category{ID: 2, Title: "Groceries"},
+			TransactionAccount:   card,
+		},
+		{
+			ID:                 4,
+			Date:               "2024-01-05",
+			Payee:              "To savings",
+			Amount:             -100,
+			Category:           pocketsmith.Category{ID: 4, Title: "Transfer", IsTransfer: true},
+			TransactionAccount: everyday,
+		},
+	}
+
+	tests := map[string]struct {
+		dialect Dialect
+		want    []string
+	}{
+		"ledger": {
+			dialect: DialectLedger,
+			want: []string{
+				"2024/01/02 * Market\n    ; :weekly shop:\n    ; pocketsmith_id: 1\n",
+				"    Assets:My Bank:Everyday  -12.50 AUD\n    Expenses:Food:Groceries  12.50 AUD\n",
+				"    Income:Salary  -1000.00 AUD\n",
+				"    Liabilities:Travel Card  -10.00 USD @@ 15.00 AUD\n    Expenses:Food:Groceries  15.00 AUD\n",
+				"    Equity:Transfers  100.00 AUD\n",
+			},
+		},
+		"hledger": {
+			dialect: DialectHLedger,
+			want: []string{
+				"2024-01-02 * Market  ; weekly shop:\n",
+			},
+		},
+		"beancount": {
+			dialect: DialectBeancount,
+			want: []string{
+				"2024-01-02 * \"Market\" #weekly-shop\n  pocketsmith_id: \"1\"\n",
+				"  Assets:My-Bank:Everyday  -12.50 AUD\n  Expenses:Food:Groceries  12.50 AUD\n",
+				"2024-01-02 open Assets:My-Bank:Everyday\n",
+				"2024-01-04 open Liabilities:Travel-Card\n",
+			},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewJournalWriter(&buf, &JournalOptions{
+				Dialect:          tt.dialect,
+				Categories:       categories,
+				IncomeCategories: []string{"Salary"},
+				BaseCurrency:     "aud",
+			})
+			if err != nil {
+				t.Fatalf("NewJournalWriter() returned an error; error=%v", err)
+			}
+			if err := w.Write(transactions); err != nil {
+				t.Fatalf("Write() returned an error; error=%v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() returned an error; error=%v", err)
+			}
+
+			// is there a mismatch from what we're expecting vs what we've got?
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Write() returned unexpected output;\nwant=%q\ngot=%s\n", want, got)
+				}
+			}
+		})
+	}
+}
+
+func Test_JournalWriter_CategoryAccountName(t *testing.T) {
+	parentID := 1
+	tests := map[string]struct {
+		categories pocketsmith.Categories
+		want       string
+	}{
+		"nested": {
+			categories: pocketsmith.Categories{
+				{ID: 1, Title: "Food", Children: []*pocketsmith.Category{{ID: 2, Title: "Groceries"}}},
+			},
+			want: "Expenses:Food:Groceries",
+		},
+		"flat, by parent id": {
+			categories: pocketsmith.Categories{
+				{ID: 2, Title: "Groceries", ParentID: &parentID},
+				{ID: 1, Title: "Food"},
+			},
+			want: "Expenses:Food:Groceries",
+		},
+		"unknown": {
+			want: "Expenses:Groceries",
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			w, err := NewJournalWriter(&bytes.Buffer{}, &JournalOptions{
+				Dialect:    DialectLedger,
+				Categories: tt.categories,
+			})
+			if err != nil {
+				t.Fatalf("NewJournalWriter() returned an error; error=%v", err)
+			}
+			got := w.CategoryAccountName(pocketsmith.Category{ID: 2, Title: "Groceries"}, -1)
+			if got != tt.want {
+				t.Errorf("CategoryAccountName() returned %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
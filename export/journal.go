@@ -0,0 +1,376 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// Dialect defines a supported plain-text accounting dialect.
+type Dialect string
+
+const (
+	DialectLedger    Dialect = "ledger"
+	DialectHLedger   Dialect = "hledger"
+	DialectBeancount Dialect = "beancount"
+)
+
+// Dialects are all the supported plain-text accounting dialects.
+var Dialects = []Dialect{DialectLedger, DialectHLedger, DialectBeancount}
+
+// The default account names used by a JournalWriter.
+const (
+	defaultTransferAccount       = "Equity:Transfers"
+	defaultOpeningBalanceAccount = "Equity:Opening-Balances"
+	defaultUncategorised         = "Uncategorised"
+)
+
+// JournalOptions defines the options for writing a journal.
+type JournalOptions struct {
+
+	// Dialect is the plain-text accounting dialect to write.
+	Dialect Dialect
+
+	// Categories are the categories of the user, used to name category
+	// accounts by their path in the category tree (eg.
+	// "Expenses:Food:Groceries"). If not given, only the category title is
+	// used.
+	Categories pocketsmith.Categories
+
+	// IncomeCategories are the titles of the categories (and their children)
+	// that are named under "Income" rather than "Expenses".
+	IncomeCategories []string
+
+	// BaseCurrency, if given, is the currency that category postings are
+	// written in. Transactions in other currencies are written with a total
+	// price (`@@`), using Transaction.AmountInBaseCurrency.
+	BaseCurrency string
+
+	// TransferAccount is the account used as the other side of transfers.
+	// Defaults to "Equity:Transfers".
+	TransferAccount string
+}
+
+// JournalWriter writes transactions as balanced journal entries in a
+// plain-text accounting dialect (ledger, hledger or beancount).
+//
+// Each transaction account is named under "Assets" or "Liabilities" (eg.
+// "Assets:Bank:Everyday"), and each category is named under "Expenses" or
+// "Income" using the path to the category in the category tree. Transfers
+// are posted against the transfer account, so that both sides of a transfer
+// cancel each other out.
+type JournalWriter struct {
+	w       *bufio.Writer
+	options JournalOptions
+
+	// categories.
+	categories *pocketsmith.CategoryTree
+	income     map[string]bool // The titles of the income categories.
+
+	// opened holds the earliest date each account is used; beancount needs an
+	// `open` directive for every account, which is written on Close.
+	opened map[string]string
+}
+
+// NewJournalWriter returns a JournalWriter that writes to w.
+func NewJournalWriter(w io.Writer, options *JournalOptions) (*JournalWriter, error) {
+	jw := &JournalWriter{
+		w:          bufio.NewWriter(w),
+		options:    *options,
+		categories: pocketsmith.NewCategoryTree(options.Categories),
+		income:     make(map[string]bool),
+		opened:     make(map[string]string),
+	}
+	switch jw.options.Dialect {
+	case DialectLedger, DialectHLedger, DialectBeancount:
+	default:
+		return nil, ErrUnsupportedFormat{Format(jw.options.Dialect)}
+	}
+	if jw.options.TransferAccount == "" {
+		jw.options.TransferAccount = defaultTransferAccount
+	}
+	jw.options.BaseCurrency = strings.ToUpper(jw.options.BaseCurrency)
+
+	for _, title := range options.IncomeCategories {
+		jw.income[strings.ToLower(title)] = true
+	}
+	return jw, nil
+}
+
+// AccountName returns the journal account name for the given transaction
+// account.
+func (w *JournalWriter) AccountName(ta pocketsmith.TransactionAccount) string {
+	root := "Assets"
+	switch ta.Type {
+	case "credits", "loans", "mortgage", "other_liability":
+		root = "Liabilities"
+	}
+	parts := []string{root}
+	if ta.Institution.Title != "" {
+		parts = append(parts, ta.Institution.Title)
+	}
+	parts = append(parts, accountName(ta))
+	return w.join(parts...)
+}
+
+// CategoryAccountName returns the journal account name for the given
+// category. Transactions without a category are named under
+// "Uncategorised", as income or expenses depending on the given amount.
+func (w *JournalWriter) CategoryAccountName(category pocketsmith.Category, amount float64) string {
+	if category.ID == 0 && category.Title == "" {
+		if amount > 0 {
+			return w.join("Income", defaultUncategorised)
+		}
+		return w.join("Expenses", defaultUncategorised)
+	}
+
+	// build path from root to category.
+	path := []string{category.Title}
+	for _, parent := range w.categories.Ancestors(category.ID) {
+		path = append([]string{parent.Title}, path...)
+	}
+
+	// determine if this is an income category.
+	root := "Expenses"
+	for _, title := range path {
+		if w.income[strings.ToLower(title)] {
+			root = "Income"
+			break
+		}
+	}
+	return w.join(append([]string{root}, path...)...)
+}
+
+// Write writes the given transactions as journal entries.
+func (w *JournalWriter) Write(transactions pocketsmith.Transactions) error {
+	for _, t := range transactions {
+		w.writeTransaction(t)
+	}
+	return w.w.Flush()
+}
+
+// WriteOpeningBalances writes an opening balance entry for each of the given
+// transaction accounts, using their starting balance and starting balance
+// date.
+func (w *JournalWriter) WriteOpeningBalances(accounts pocketsmith.TransactionAccounts) error {
+	for _, ta := range accounts {
+		if ta.StartingBalanceDate == "" {
+			continue
+		}
+		account := w.AccountName(ta)
+		equity := w.join(strings.Split(defaultOpeningBalanceAccount, ":")...)
+		currency := w.currency(ta)
+		w.writeHeader(ta.StartingBalanceDate, "*", "Opening Balance", "", nil)
+		w.writePosting(account, ta.StartingBalance, currency, "")
+		w.writePosting(equity, -ta.StartingBalance, currency, "")
+		fmt.Fprintln(w.w)
+		w.open(account, ta.StartingBalanceDate)
+		w.open(equity, ta.StartingBalanceDate)
+	}
+	return w.w.Flush()
+}
+
+// Close writes any `open` directives needed by the dialect, and flushes the
+// writer.
+func (w *JournalWriter) Close() error {
+	if w.options.Dialect == DialectBeancount && len(w.opened) > 0 {
+		accounts := make([]string, 0, len(w.opened))
+		for a := range w.opened {
+			accounts = append(accounts, a)
+		}
+		sort.Strings(accounts)
+		for _, a := range accounts {
+			fmt.Fprintf(w.w, "%s open %s\n", w.opened[a], a)
+		}
+	}
+	return w.w.Flush()
+}
+
+// writeTransaction writes the given transaction as a balanced journal entry.
+func (w *JournalWriter) writeTransaction(t pocketsmith.Transaction) {
+
+	// determine accounts.
+	account := w.AccountName(t.TransactionAccount)
+	other := w.CategoryAccountName(t.Category, t.Amount)
+	if t.IsTransfer || t.Category.IsTransfer {
+		other = w.join(strings.Split(w.options.TransferAccount, ":")...)
+	}
+	w.open(account, t.Date)
+	w.open(other, t.Date)
+
+	// determine status.
+	status := "*"
+	if t.Status == "pending" {
+		status = "!"
+	}
+
+	// write entry.
+	currency := w.currency(t.TransactionAccount)
+	payee := firstNonEmpty(t.Payee, t.OriginalPayee)
+	w.writeHeader(t.Date, status, payee, firstNonEmpty(t.Note, t.Memo), t.Labels)
+	w.writeMetadata("pocketsmith_id", strconv.Itoa(int(t.ID)))
+	if w.options.BaseCurrency != "" && currency != w.options.BaseCurrency {
+
+		// NOTE: the category posting is written in the base currency, and the
+		// account posting is given a total price in the base currency, so that
+		// the entry balances.
+		base := math.Abs(t.AmountInBaseCurrency)
+		price := fmt.Sprintf("@@ %s %s", formatAmount(base), w.options.BaseCurrency)
+		w.writePosting(account, t.Amount, currency, price)
+		w.writePosting(other, -math.Copysign(base, t.Amount), w.options.BaseCurrency, "")
+	} else {
+		w.writePosting(account, t.Amount, currency, "")
+		w.writePosting(other, -t.Amount, currency, "")
+	}
+	fmt.Fprintln(w.w)
+}
+
+// writeHeader writes the first line of a journal entry.
+func (w *JournalWriter) writeHeader(date, status, payee, note string, labels []string) {
+	payee = journalLine(payee)
+	note = journalLine(note)
+	switch w.options.Dialect {
+	case DialectBeancount:
+		fmt.Fprintf(w.w, "%s %s %s", date, status, strconv.Quote(payee))
+		if note != "" {
+			fmt.Fprintf(w.w, " %s", strconv.Quote(note))
+		}
+		for _, l := range labels {
+			if tag := beancountTag(l); tag != "" {
+				fmt.Fprintf(w.w, " #%s", tag)
+			}
+		}
+		fmt.Fprintln(w.w)
+	case DialectHLedger:
+		fmt.Fprintf(w.w, "%s %s %s", date, status, payee)
+		var comments []string
+		if note != "" {
+			comments = append(comments, note)
+		}
+		for _, l := range labels {
+			comments = append(comments, journalLine(strings.ReplaceAll(l, ",", " "))+":")
+		}
+		if len(comments) > 0 {
+			fmt.Fprintf(w.w, "  ; %s", strings.Join(comments, ", "))
+		}
+		fmt.Fprintln(w.w)
+	default:
+		fmt.Fprintf(w.w, "%s %s %s\n", strings.ReplaceAll(date, "-", "/"), status, payee)
+		if note != "" {
+			fmt.Fprintf(w.w, "    ; %s\n", note)
+		}
+		if len(labels) > 0 {
+			tags := make([]string, len(labels))
+			for i, l := range labels {
+				tags[i] = strings.ReplaceAll(journalLine(l), ":", "-")
+			}
+			fmt.Fprintf(w.w, "    ; :%s:\n", strings.Join(tags, ":"))
+		}
+	}
+}
+
+// writeMetadata writes a key / value pair against the current entry.
+func (w *JournalWriter) writeMetadata(key, value string) {
+	switch w.options.Dialect {
+	case DialectBeancount:
+		fmt.Fprintf(w.w, "  %s: %s\n", key, strconv.Quote(value))
+	default:
+		fmt.Fprintf(w.w, "    ; %s: %s\n", key, value)
+	}
+}
+
+// writePosting writes a posting against the current entry.
+func (w *JournalWriter) writePosting(account string, amount float64, currency, price string) {
+	indent := "    "
+	if w.options.Dialect == DialectBeancount {
+		indent = "  "
+	}
+	line := fmt.Sprintf("%s%s  %s %s", indent, account, formatAmount(amount), currency)
+	if price != "" {
+		line += " " + price
+	}
+	fmt.Fprintln(w.w, line)
+}
+
+// open records the earliest date the given account is used.
+func (w *JournalWriter) open(account, date string) {
+	if d, ok := w.opened[account]; !ok || date < d {
+		w.opened[account] = date
+	}
+}
+
+// currency returns the currency code of the given transaction account.
+func (w *JournalWriter) currency(ta pocketsmith.TransactionAccount) string {
+	return strings.ToUpper(firstNonEmpty(ta.CurrencyCode, w.options.BaseCurrency, "USD"))
+}
+
+// join joins the given account name components, cleaning each one for the
+// dialect being written.
+func (w *JournalWriter) join(parts ...string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = w.component(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ":")
+}
+
+// rgxBeancountInvalid matches characters not allowed in beancount account
+// name components.
+var rgxBeancountInvalid = regexp.MustCompile(`[^\p{L}\p{N}-]+`)
+
+// rgxSpaces matches runs of whitespace.
+var rgxSpaces = regexp.MustCompile(`\s+`)
+
+// component cleans the given account name component for the dialect being
+// written. Ledger and hledger allow most characters, except ':' (the
+// component separator) and double spaces (the amount separator). Beancount
+// only allows letters, numbers and dashes, and each component must start with
+// a capital letter or a number.
+func (w *JournalWriter) component(p string) string {
+	p = strings.ReplaceAll(strings.TrimSpace(p), ":", "-")
+	if w.options.Dialect != DialectBeancount {
+		return rgxSpaces.ReplaceAllString(p, " ")
+	}
+	words := rgxBeancountInvalid.Split(p, -1)
+	for i, word := range words {
+		r := []rune(word)
+		if len(r) > 0 {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		words[i] = string(r)
+	}
+	p = strings.Trim(strings.Join(words, "-"), "-")
+	for strings.Contains(p, "--") {
+		p = strings.ReplaceAll(p, "--", "-")
+	}
+	if p == "" {
+		return ""
+	}
+	if r := []rune(p)[0]; !unicode.IsUpper(r) && !unicode.IsDigit(r) {
+		p = "X" + p
+	}
+	return p
+}
+
+// rgxBeancountTagInvalid matches characters not allowed in beancount tags.
+var rgxBeancountTagInvalid = regexp.MustCompile(`[^A-Za-z0-9\-_/.]+`)
+
+// beancountTag cleans the given label into a beancount tag.
+func beancountTag(label string) string {
+	return strings.Trim(rgxBeancountTagInvalid.ReplaceAllString(label, "-"), "-")
+}
+
+// journalLine removes newlines from the given value.
+func journalLine(value string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ").Replace(value))
+}
@@ -0,0 +1,34 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// JSONLWriter writes transactions as JSON Lines; one JSON encoded transaction
+// per line, as returned from the API.
+type JSONLWriter struct {
+	enc *json.Encoder
+}
+
+// NewJSONLWriter returns a JSONLWriter that writes to w.
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{enc: json.NewEncoder(w)}
+}
+
+// Write writes the given transactions.
+func (w *JSONLWriter) Write(transactions pocketsmith.Transactions) error {
+	for _, t := range transactions {
+		if err := w.enc.Encode(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close is a no-op, as nothing is buffered.
+func (w *JSONLWriter) Close() error {
+	return nil
+}
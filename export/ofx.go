@@ -0,0 +1,251 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// The header written at the top of OFX 2.x files.
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// The date formats used in OFX files.
+const (
+	ofxDateFormat     = "20060102"
+	ofxDateTimeFormat = "20060102150405"
+)
+
+// OFXWriter writes transactions as an OFX 2.x file, with one statement per
+// transaction account. Bank and loan accounts are written as bank statements,
+// and credit card accounts as credit card statements.
+//
+// NOTE: an OFX statement needs its date range before its transactions, so the
+// transactions are buffered and only written on Close.
+type OFXWriter struct {
+	w        io.Writer
+	now      func() time.Time // The clock used for the server date.
+	accounts map[int]*ofxAccount
+}
+
+// ofxAccount holds the buffered transactions for a transaction account.
+type ofxAccount struct {
+	account      pocketsmith.TransactionAccount
+	transactions pocketsmith.Transactions
+}
+
+// NewOFXWriter returns an OFXWriter that writes to w.
+func NewOFXWriter(w io.Writer) *OFXWriter {
+	return &OFXWriter{w: w, now: time.Now, accounts: make(map[int]*ofxAccount)}
+}
+
+// Write buffers the given transactions.
+func (w *OFXWriter) Write(transactions pocketsmith.Transactions) error {
+	for _, t := range transactions {
+		a, ok := w.accounts[t.TransactionAccount.ID]
+		if !ok {
+			a = &ofxAccount{account: t.TransactionAccount}
+			w.accounts[t.TransactionAccount.ID] = a
+		}
+		a.transactions = append(a.transactions, t)
+	}
+	return nil
+}
+
+// Close writes the buffered transactions.
+func (w *OFXWriter) Close() error {
+	now := w.now()
+	doc := ofxDocument{
+		SignOn: ofxSignOn{
+			Status:   ofxStatusOK,
+			DTServer: now.UTC().Format(ofxDateTimeFormat),
+			Language: "ENG",
+		},
+	}
+
+	// write statements, ordered by transaction account.
+	ids := make([]int, 0, len(w.accounts))
+	for id := range w.accounts {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for i, id := range ids {
+		a := w.accounts[id]
+		stmt := ofxStatement{
+			CurDef:   strings.ToUpper(firstNonEmpty(a.account.CurrencyCode, "USD")),
+			TranList: a.transactionList(),
+			LedgerBal: ofxBalance{
+				BalAmt: formatAmount(a.account.CurrentBalance),
+				DTAsOf: ofxDate(a.account.CurrentBalanceDate, now),
+			},
+		}
+		acct := &ofxAccountFrom{
+			BankID: strconv.Itoa(a.account.Institution.ID),
+			AcctID: firstNonEmpty(a.account.Number, strconv.Itoa(a.account.ID)),
+		}
+		resp := ofxStatementResponse{TrnUID: strconv.Itoa(i + 1), Status: ofxStatusOK}
+		if a.account.Type == "credits" {
+			stmt.CCAcctFrom = &ofxAccountFrom{AcctID: acct.AcctID}
+			resp.CCStmtRs = &stmt
+			if doc.CreditCard == nil {
+				doc.CreditCard = &ofxCreditCardMessages{}
+			}
+			doc.CreditCard.Responses = append(doc.CreditCard.Responses, resp)
+			continue
+		}
+		acct.AcctType = ofxAccountType(a.account.Type)
+		stmt.BankAcctFrom = acct
+		resp.StmtRs = &stmt
+		if doc.Bank == nil {
+			doc.Bank = &ofxBankMessages{}
+		}
+		doc.Bank.Responses = append(doc.Bank.Responses, resp)
+	}
+
+	// write document.
+	if _, err := io.WriteString(w.w, ofxHeader); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n")
+	return err
+}
+
+// transactionList returns the buffered transactions as an OFX transaction
+// list, ordered by date.
+func (a *ofxAccount) transactionList() ofxTransactionList {
+	sort.SliceStable(a.transactions, func(i, j int) bool {
+		return a.transactions[i].Date < a.transactions[j].Date
+	})
+	list := ofxTransactionList{}
+	if len(a.transactions) > 0 {
+		list.DTStart = strings.ReplaceAll(a.transactions[0].Date, "-", "")
+		list.DTEnd = strings.ReplaceAll(a.transactions[len(a.transactions)-1].Date, "-", "")
+	}
+	for _, t := range a.transactions {
+		trnType := "DEBIT"
+		switch {
+		case t.IsTransfer:
+			trnType = "XFER"
+		case t.Amount > 0:
+			trnType = "CREDIT"
+		}
+		name := firstNonEmpty(t.Payee, t.OriginalPayee)
+		if r := []rune(name); len(r) > 32 {
+			name = string(r[:32])
+		}
+		list.Transactions = append(list.Transactions, ofxTransaction{
+			TrnType:  trnType,
+			DTPosted: strings.ReplaceAll(t.Date, "-", ""),
+			TrnAmt:   formatAmount(t.Amount),
+			FITID:    strconv.Itoa(int(t.ID)),
+			CheckNum: t.ChequeNumber,
+			Name:     name,
+			Memo:     firstNonEmpty(t.Note, t.Memo, t.Category.Title),
+		})
+	}
+	return list
+}
+
+// ofxAccountType returns the OFX bank account type for the given transaction
+// account type.
+func ofxAccountType(typ string) string {
+	switch typ {
+	case "loans", "mortgage", "other_liability":
+		return "CREDITLINE"
+	}
+	return "CHECKING"
+}
+
+// ofxDate returns the given PocketSmith date in the OFX date format, or the
+// given fallback if the date is empty or invalid.
+func ofxDate(date string, fallback time.Time) string {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return fallback.UTC().Format(ofxDateFormat)
+	}
+	return d.Format(ofxDateFormat)
+}
+
+// ---
+
+// ofxStatusOK is the status returned in a successful OFX response.
+var ofxStatusOK = ofxStatus{Code: 0, Severity: "INFO"}
+
+type ofxDocument struct {
+	XMLName    xml.Name               `xml:"OFX"`
+	SignOn     ofxSignOn              `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank       *ofxBankMessages       `xml:"BANKMSGSRSV1,omitempty"`
+	CreditCard *ofxCreditCardMessages `xml:"CREDITCARDMSGSRSV1,omitempty"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxBankMessages struct {
+	Responses []ofxStatementResponse `xml:"STMTTRNRS"`
+}
+
+type ofxCreditCardMessages struct {
+	Responses []ofxStatementResponse `xml:"CCSTMTTRNRS"`
+}
+
+type ofxStatementResponse struct {
+	TrnUID   string        `xml:"TRNUID"`
+	Status   ofxStatus     `xml:"STATUS"`
+	StmtRs   *ofxStatement `xml:"STMTRS,omitempty"`
+	CCStmtRs *ofxStatement `xml:"CCSTMTRS,omitempty"`
+}
+
+type ofxStatement struct {
+	CurDef       string             `xml:"CURDEF"`
+	BankAcctFrom *ofxAccountFrom    `xml:"BANKACCTFROM,omitempty"`
+	CCAcctFrom   *ofxAccountFrom    `xml:"CCACCTFROM,omitempty"`
+	TranList     ofxTransactionList `xml:"BANKTRANLIST"`
+	LedgerBal    ofxBalance         `xml:"LEDGERBAL"`
+}
+
+type ofxAccountFrom struct {
+	BankID   string `xml:"BANKID,omitempty"`
+	AcctID   string `xml:"ACCTID"`
+	AcctType string `xml:"ACCTTYPE,omitempty"`
+}
+
+type ofxTransactionList struct {
+	DTStart      string           `xml:"DTSTART"`
+	DTEnd        string           `xml:"DTEND"`
+	Transactions []ofxTransaction `xml:"STMTTRN"`
+}
+
+type ofxTransaction struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DTPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FITID    string `xml:"FITID"`
+	CheckNum string `xml:"CHECKNUM,omitempty"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	BalAmt string `xml:"BALAMT"`
+	DTAsOf string `xml:"DTASOF"`
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// The date format used in QIF files.
+const qifDateFormat = "01/02/2006"
+
+// QIFWriter writes transactions as QIF. Since QIF files can hold more than one
+// account, an `!Account` block is written whenever the transaction account
+// changes between transactions, so transactions should be written grouped by
+// transaction account (as they are when listed per account).
+type QIFWriter struct {
+	w       *bufio.Writer
+	account int // The id of the transaction account currently being written.
+}
+
+// NewQIFWriter returns a QIFWriter that writes to w.
+func NewQIFWriter(w io.Writer) *QIFWriter {
+	return &QIFWriter{w: bufio.NewWriter(w), account: -1}
+}
+
+// Write writes the given transactions.
+func (w *QIFWriter) Write(transactions pocketsmith.Transactions) error {
+	for _, t := range transactions {
+		if t.TransactionAccount.ID != w.account {
+			w.writeAccount(t.TransactionAccount)
+		}
+		w.writeTransaction(t)
+	}
+	return w.w.Flush()
+}
+
+// Close flushes the writer.
+func (w *QIFWriter) Close() error {
+	return w.w.Flush()
+}
+
+// writeAccount writes the header for the given transaction account.
+func (w *QIFWriter) writeAccount(ta pocketsmith.TransactionAccount) {
+	w.account = ta.ID
+	typ := qifAccountType(ta.Type)
+	fmt.Fprintf(w.w, "!Account\nN%s\nT%s\n", qifLine(accountName(ta)), typ)
+	if ta.Institution.Title != "" {
+		fmt.Fprintf(w.w, "D%s\n", qifLine(ta.Institution.Title))
+	}
+	fmt.Fprintf(w.w, "^\n!Type:%s\n", typ)
+}
+
+// writeTransaction writes the given transaction.
+func (w *QIFWriter) writeTransaction(t pocketsmith.Transaction) {
+	if d, err := time.Parse("2006-01-02", t.Date); err == nil {
+		fmt.Fprintf(w.w, "D%s\n", d.Format(qifDateFormat))
+	}
+	fmt.Fprintf(w.w, "T%s\n", formatAmount(t.Amount))
+	if payee := firstNonEmpty(t.Payee, t.OriginalPayee); payee != "" {
+		fmt.Fprintf(w.w, "P%s\n", qifLine(payee))
+	}
+	if memo := firstNonEmpty(t.Note, t.Memo); memo != "" {
+		fmt.Fprintf(w.w, "M%s\n", qifLine(memo))
+	}
+	if t.ChequeNumber != "" {
+		fmt.Fprintf(w.w, "N%s\n", qifLine(t.ChequeNumber))
+	}
+	if t.Category.Title != "" {
+		fmt.Fprintf(w.w, "L%s\n", qifLine(t.Category.Title))
+	}
+	if t.Status == "posted" {
+		fmt.Fprintf(w.w, "CX\n")
+	}
+	fmt.Fprintf(w.w, "^\n")
+}
+
+// qifAccountType returns the QIF account type for the given transaction
+// account type.
+func qifAccountType(typ string) string {
+	switch typ {
+	case "bank":
+		return "Bank"
+	case "credits":
+		return "CCard"
+	case "cash":
+		return "Cash"
+	case "loans", "mortgage", "other_liability":
+		return "Oth L"
+	case "stocks", "property", "vehicle", "insurance", "other_asset":
+		return "Oth A"
+	}
+	return "Bank"
+}
+
+// qifLine removes newlines from the given value, as each QIF field must be on
+// a single line.
+func qifLine(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+// accountName returns a display name for the given transaction account.
+func accountName(ta pocketsmith.TransactionAccount) string {
+	return firstNonEmpty(ta.Name, ta.Number, fmt.Sprintf("Account %v", ta.ID))
+}
@@ -0,0 +1,224 @@
+package pocketsmith
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go/internal/atomicfile"
+)
+
+// errStopPages is returned from a page callback to stop listing early.
+var errStopPages = errors.New("stop pages")
+
+// IdempotencyKey returns a deterministic key for the given options; the same
+// options always return the same key. An explicit options.IdempotencyKey is
+// returned as is.
+func IdempotencyKey(options *CreateTransactionAccountTransactionOptions) string {
+	if options.IdempotencyKey != "" {
+		return options.IdempotencyKey
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%v\n%s\n%.2f\n%s\n%t\n%s\n%v\n%s\n%s\n%s",
+		options.TransactionAccountID,
+		options.Payee,
+		options.Amount,
+		options.Date,
+		options.IsTransfer,
+		options.Labels,
+		options.CategoryID,
+		options.Note,
+		options.Memo,
+		options.ChequeNumber,
+	)
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// idempotencyMarker returns the marker added to the note of a transaction to
+// store the given idempotency key.
+func idempotencyMarker(key string) string {
+	return fmt.Sprintf("[idempotency-key:%s]", key)
+}
+
+// createIdempotent creates a transaction in the given transaction account,
+// unless a transaction was already created with the same options, in which
+// case that transaction is returned.
+//
+// The idempotency key is stored either in the note of the transaction, or, if
+// the client has an IdempotencyLedger, in the ledger. Since a transaction can
+// only be created on its own date, only transactions on that date are checked.
+func (c *Client) createIdempotent(
+	ctx context.Context,
+	options *CreateTransactionAccountTransactionOptions,
+) (transaction *Transaction, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "createIdempotent")
+	defer span.End()
+
+	key := IdempotencyKey(options)
+	span.SetAttributes(attribute.String("key", key))
+
+	// determine how to find an existing transaction.
+	create := *options
+	create.Idempotent = false
+	var match func(t Transaction) bool
+	if c.ledger == nil {
+		marker := idempotencyMarker(key)
+		match = func(t Transaction) bool {
+			return strings.Contains(t.Note, marker)
+		}
+		create.Note = strings.TrimSpace(create.Note + " " + marker)
+	} else {
+		id, found, err := c.ledger.Get(key)
+		if err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to read ledger: %v", err))
+			span.RecordError(err)
+			return nil, err
+		}
+		switch {
+		case found && id != 0:
+			match = func(t Transaction) bool { return t.ID == id }
+		case found:
+
+			// a previous attempt was sent, but its response never arrived; so
+			// look for a transaction that matches the options instead.
+			match = func(t Transaction) bool {
+				return t.Payee == options.Payee &&
+					math.Abs(t.Amount-options.Amount) < 0.005 &&
+					t.Memo == options.Memo &&
+					t.ChequeNumber == options.ChequeNumber
+			}
+		}
+	}
+
+	// find an existing transaction.
+	if match != nil {
+		err := c.ListTransactionAccountTransactionsPages(newCtx,
+			&ListTransactionAccountTransactionsOptions{
+				TransactionAccountID: strconv.Itoa(options.TransactionAccountID),
+				StartDate:            options.Date,
+				EndDate:              options.Date,
+			},
+			func(batch Transactions) error {
+				for i := range batch {
+					if match(batch[i]) {
+						transaction = &batch[i]
+						return errStopPages
+					}
+				}
+				return nil
+			},
+		)
+		if err != nil && !errors.Is(err, errStopPages) {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to find existing transaction: %v", err))
+			span.RecordError(err)
+			return nil, err
+		}
+		if transaction != nil {
+			span.SetAttributes(attribute.Bool("existing", true))
+			if c.ledger != nil {
+				if err := c.ledger.Put(key, transaction.ID); err != nil {
+					return nil, err
+				}
+			}
+			return transaction, nil
+		}
+	}
+
+	// create transaction; recording the attempt in the ledger first, so that a
+	// retry knows to check for it.
+	if c.ledger != nil {
+		if err := c.ledger.Put(key, 0); err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to write ledger: %v", err))
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+	if transaction, err = c.CreateTransactionAccountTransaction(newCtx, &create); err != nil {
+		return nil, err
+	}
+	if c.ledger != nil {
+		if err := c.ledger.Put(key, transaction.ID); err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to write ledger: %v", err))
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+	return transaction, nil
+}
+
+// IdempotencyLedger stores the idempotency keys of created transactions.
+type IdempotencyLedger interface {
+
+	// Get returns the id of the transaction created with the given key, and
+	// if the key was found. An id of 0 means a transaction was being created,
+	// but it isn't known if that succeeded.
+	Get(key string) (id int32, found bool, err error)
+
+	// Put stores the id of the transaction created with the given key.
+	Put(key string, id int32) error
+}
+
+// IdempotencyFileLedger is an IdempotencyLedger that persists keys to a JSON
+// file on disk. The file is rewritten atomically after every change.
+type IdempotencyFileLedger struct {
+	mu   sync.Mutex
+	path string
+	keys map[string]int32
+}
+
+// NewIdempotencyFileLedger returns an IdempotencyFileLedger backed by the file
+// at the given path. If the file already exists, it is loaded; otherwise it is
+// created on the first write.
+func NewIdempotencyFileLedger(path string) (*IdempotencyFileLedger, error) {
+	l := &IdempotencyFileLedger{path: path, keys: make(map[string]int32)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, ErrLedgerFailedRead{path, err}
+	}
+	if err := json.Unmarshal(b, &l.keys); err != nil {
+		return nil, ErrLedgerFailedRead{path, err}
+	}
+	return l, nil
+}
+
+// Get returns the id of the transaction created with the given key.
+func (l *IdempotencyFileLedger) Get(key string) (int32, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id, ok := l.keys[key]
+	return id, ok, nil
+}
+
+// Put stores the id of the transaction created with the given key.
+func (l *IdempotencyFileLedger) Put(key string, id int32) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keys[key] = id
+	b, err := json.MarshalIndent(l.keys, "", "  ")
+	if err != nil {
+		return ErrLedgerFailedWrite{l.path, err}
+	}
+
+	// write atomically, so that a failed write never corrupts the ledger.
+	if err := atomicfile.Write(l.path, b, 0o600); err != nil {
+		return ErrLedgerFailedWrite{l.path, err}
+	}
+	return nil
+}
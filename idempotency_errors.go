@@ -0,0 +1,24 @@
+package pocketsmith
+
+import "fmt"
+
+// ErrLedgerFailedRead is returned when an idempotency ledger can't be read.
+type ErrLedgerFailedRead struct {
+	path string
+	err  error
+}
+
+func (e ErrLedgerFailedRead) Error() string {
+	return fmt.Sprintf("failed to read idempotency ledger %s: %v", e.path, e.err)
+}
+
+// ErrLedgerFailedWrite is returned when an idempotency ledger can't be
+// written.
+type ErrLedgerFailedWrite struct {
+	path string
+	err  error
+}
+
+func (e ErrLedgerFailedWrite) Error() string {
+	return fmt.Sprintf("failed to write idempotency ledger %s: %v", e.path, e.err)
+}
@@ -0,0 +1,119 @@
+package pocketsmith
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// mockTransactionAccount is a mock transaction account, used to test
+// idempotent transaction creation. The first create times out after the
+// transaction has been created.
+type mockTransactionAccount struct {
+	mu           sync.Mutex
+	transactions Transactions
+	created      int
+}
+
+func (m *mockTransactionAccount) roundTrip(req *http.Request) *http.Response {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if req.Method == http.MethodGet {
+		var out Transactions
+		for _, t := range m.transactions {
+			if t.Date == req.URL.Query().Get("start_date") {
+				out = append(out, t)
+			}
+		}
+		return mockResponse(http.StatusOK, out)
+	}
+	var t Transaction
+	_ = json.NewDecoder(req.Body).Decode(&t)
+	m.created++
+	t.ID = int32(m.created)
+	m.transactions = append(m.transactions, t)
+	if m.created == 1 {
+		return mockResponse(http.StatusGatewayTimeout, apiErrorResponse{Error: "timeout"})
+	}
+	return mockResponse(http.StatusOK, t)
+}
+
+func Test_CreateTransactionAccountTransaction_idempotent(t *testing.T) {
+	ledger, err := NewIdempotencyFileLedger(filepath.Join(t.TempDir(), "ledger.json"))
+	if err != nil {
+		t.Fatalf("NewIdempotencyFileLedger() returned an error: %v", err)
+	}
+	tests := map[string]struct {
+		ledger IdempotencyLedger
+		note   string
+	}{
+		"key stored in note": {
+			note: "salary [idempotency-key:",
+		},
+		"key stored in ledger": {
+			ledger: ledger,
+			note:   "salary",
+		},
+	}
+	for name, tt := range tests {
+
+		// setup client with mock.
+		m := &mockTransactionAccount{}
+		c := newMockClient(m.roundTrip)
+		c.ledger = tt.ledger
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			options := &CreateTransactionAccountTransactionOptions{
+				TransactionAccountID: 5,
+				Payee:                "Work",
+				Amount:               1000,
+				Date:                 "2024-01-15",
+				Note:                 "salary",
+				Idempotent:           true,
+			}
+
+			// the first attempt times out, after the server has created it.
+			if _, err := c.CreateTransactionAccountTransaction(context.Background(), options); err == nil {
+				t.Fatalf("CreateTransactionAccountTransaction() didn't time out")
+			}
+
+			// retrying returns the existing transaction.
+			for i := 0; i < 2; i++ {
+				got, err := c.CreateTransactionAccountTransaction(context.Background(), options)
+				if err != nil {
+					t.Fatalf("CreateTransactionAccountTransaction() returned an error: %v", err)
+				}
+				if got.ID != 1 || !strings.HasPrefix(got.Note, tt.note) {
+					t.Errorf("CreateTransactionAccountTransaction() returned an unexpected transaction; got=%+v", got)
+				}
+			}
+			if m.created != 1 {
+				t.Errorf("CreateTransactionAccountTransaction() created %v transactions; want=1", m.created)
+			}
+
+			// different options create a new transaction.
+			options.Amount = 1001
+			if _, err := c.CreateTransactionAccountTransaction(context.Background(), options); err != nil {
+				t.Fatalf("CreateTransactionAccountTransaction() returned an error: %v", err)
+			}
+			if m.created != 2 {
+				t.Errorf("CreateTransactionAccountTransaction() created %v transactions; want=2", m.created)
+			}
+
+			// the same options with a different explicit key create a new
+			// transaction.
+			options.IdempotencyKey = "archive:2"
+			if _, err := c.CreateTransactionAccountTransaction(context.Background(), options); err != nil {
+				t.Fatalf("CreateTransactionAccountTransaction() returned an error: %v", err)
+			}
+			if m.created != 3 {
+				t.Errorf("CreateTransactionAccountTransaction() created %v transactions; want=3", m.created)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSVColumns defines which columns in a CSV statement hold each field. Each
+// column is either the name of the column in the header row, or the zero
+// based index of the column (eg. "0").
+type CSVColumns struct {
+	Date         string // The column holding the date. Required.
+	Amount       string // The column holding the signed amount. Required, unless Debit & Credit are given.
+	Debit        string // The column holding debits, when debits & credits are in separate columns.
+	Credit       string // The column holding credits, when debits & credits are in separate columns.
+	Payee        string // The column holding the payee.
+	Memo         string // The column holding the memo.
+	ChequeNumber string // The column holding the cheque number.
+	Category     string // The column holding the category.
+	ID           string // The column holding a unique id for the transaction.
+}
+
+// CSVOptions defines the options for parsing a CSV statement.
+type CSVOptions struct {
+	Columns      CSVColumns // The columns holding each field.
+	DateFormat   string     // The layout of the dates, as used by time.Parse. Defaults to 2006-01-02.
+	Comma        rune       // The field delimiter. Defaults to ','.
+	HasHeader    bool       // If the first (non-skipped) row is a header row.
+	SkipRows     int        // The number of rows to skip before the header, or the first row.
+	InvertAmount bool       // If the sign of amounts should be inverted (eg. for credit card statements).
+	Decimal      rune       // The decimal separator of amounts, '.' or ','. Defaults to detecting it from each amount.
+}
+
+// ParseCSV parses the transactions in a CSV statement, using the given
+// options to determine which columns hold each field.
+func ParseCSV(r io.Reader, options *CSVOptions) (rows []Row, err error) {
+
+	// setup reader.
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if options.Comma != 0 {
+		reader.Comma = options.Comma
+	}
+	layout := options.DateFormat
+	if layout == "" {
+		layout = "2006-01-02"
+	}
+
+	// skip rows.
+	line := 0
+	for ; line < options.SkipRows; line++ {
+		if _, err := reader.Read(); err != nil {
+			return nil, ErrParserFailedParse{"csv", line + 1, err}
+		}
+	}
+
+	// read header.
+	var header []string
+	if options.HasHeader {
+		line++
+		if header, err = reader.Read(); err != nil {
+			return nil, ErrParserFailedParse{"csv", line, err}
+		}
+	}
+
+	// resolve columns.
+	c := options.Columns
+	columns := make(map[string]int)
+	for field, column := range map[string]string{
+		"date":          c.Date,
+		"amount":        c.Amount,
+		"debit":         c.Debit,
+		"credit":        c.Credit,
+		"payee":         c.Payee,
+		"memo":          c.Memo,
+		"cheque_number": c.ChequeNumber,
+		"category":      c.Category,
+		"id":            c.ID,
+	} {
+		if column == "" {
+			continue
+		}
+		i, err := resolveColumn(header, column)
+		if err != nil {
+			return nil, ErrParserFailedParse{"csv", line, err}
+		}
+		columns[field] = i
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, ErrParserFailedParse{"csv", 0, fmt.Errorf("no date column given")}
+	}
+	_, hasAmount := columns["amount"]
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if !hasAmount && !(hasDebit && hasCredit) {
+		return nil, ErrParserFailedParse{
+			"csv", 0, fmt.Errorf("no amount, or debit & credit, columns given"),
+		}
+	}
+
+	// read rows.
+	for {
+		record, err := reader.Read()
+		line++
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrParserFailedParse{"csv", line, err}
+		}
+		if isBlank(record) {
+			continue
+		}
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		// parse row.
+		row := Row{
+			ID:           value("id"),
+			Payee:        value("payee"),
+			Memo:         value("memo"),
+			ChequeNumber: value("cheque_number"),
+			Category:     value("category"),
+		}
+		if row.Date, err = parseDate(value("date"), layout); err != nil {
+			return nil, ErrParserFailedParse{"csv", line, err}
+		}
+		if hasAmount {
+			if row.Amount, err = parseAmount(value("amount"), options.Decimal); err != nil {
+				return nil, ErrParserFailedParse{"csv", line, err}
+			}
+		} else {
+			debit, credit := value("debit"), value("credit")
+			switch {
+			case debit != "":
+				d, err := parseAmount(debit, options.Decimal)
+				if err != nil {
+					return nil, ErrParserFailedParse{"csv", line, err}
+				}
+				row.Amount = -abs(d)
+			case credit != "":
+				cr, err := parseAmount(credit, options.Decimal)
+				if err != nil {
+					return nil, ErrParserFailedParse{"csv", line, err}
+				}
+				row.Amount = abs(cr)
+			}
+		}
+		if options.InvertAmount {
+			row.Amount = -row.Amount
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// resolveColumn returns the index of the given column, either by its name in
+// the header, or by its index.
+func resolveColumn(header []string, column string) (int, error) {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(column)) {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(column); err == nil && i >= 0 {
+		return i, nil
+	}
+	return 0, fmt.Errorf("unknown column %q", column)
+}
+
+// isBlank returns if every field in the given record is empty.
+func isBlank(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// abs returns the absolute value of the given amount.
+func abs(amount float64) float64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"fmt"
+)
+
+// ErrParserFailedParse is returned when a statement fails to be parsed.
+type ErrParserFailedParse struct {
+	format string
+	line   int
+	err    error
+}
+
+func (e ErrParserFailedParse) Error() string {
+	if e.line > 0 {
+		return fmt.Sprintf("failed to parse %s statement on line %v: %v", e.format, e.line, e.err)
+	}
+	return fmt.Sprintf("failed to parse %s statement: %v", e.format, e.err)
+}
+
+// ErrImporterNoTransactionAccount is returned when no transaction account is
+// given to the importer.
+type ErrImporterNoTransactionAccount struct {
+}
+
+func (e ErrImporterNoTransactionAccount) Error() string {
+	return "the provided transaction account id is empty"
+}
+
+// ErrImporterFailedListExisting is returned when the importer fails to list
+// the existing transactions in the transaction account.
+type ErrImporterFailedListExisting struct {
+	err error
+}
+
+func (e ErrImporterFailedListExisting) Error() string {
+	return fmt.Sprintf("failed to list existing transactions: %v", e.err)
+}
@@ -0,0 +1,337 @@
+// Package importer imports bank statements (CSV, OFX and QIF) into a
+// PocketSmith transaction account, skipping any transactions that already
+// exist.
+//
+// Statements are first parsed into rows, with ParseCSV, ParseOFX or ParseQIF.
+// Importing the rows is then done in two steps; Plan compares the rows against
+// the transactions that already exist in the transaction account and returns
+// a Report (a dry-run), then Apply creates the transactions in the report that
+// aren't duplicates.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/internal/fuzzy"
+)
+
+// The name of the tracer output in the traces.
+const tracerName = "pocketsmith-go/importer"
+
+// API defines the parts of the *pocketsmith.Client used by an Importer.
+type API interface {
+	ListTransactionAccountTransactions(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionAccountTransactionsOptions,
+	) ([]pocketsmith.Transaction, error)
+	CreateTransactionAccountTransaction(
+		ctx context.Context,
+		options *pocketsmith.CreateTransactionAccountTransactionOptions,
+	) (*pocketsmith.Transaction, error)
+}
+
+// Options defines the options for an Importer.
+type Options struct {
+
+	// TransactionAccountID is the id of the transaction account to import
+	// into. Required.
+	TransactionAccountID int
+
+	// DateTolerance is the number of days either side of a row that an
+	// existing transaction can be dated, and still be considered a duplicate.
+	// Banks often post a transaction a few days after it was made. Defaults
+	// to 0 (same day only).
+	DateTolerance int
+
+	// PayeeSimilarity is the minimum similarity (from 0 to 1) between the
+	// payee of a row and an existing transaction for them to be considered
+	// duplicates. Defaults to DefaultPayeeSimilarity.
+	PayeeSimilarity float64
+
+	// IgnorePayee matches duplicates on date and amount alone, ignoring
+	// PayeeSimilarity.
+	IgnorePayee bool
+
+	// Labels, CategoryID and NeedsReview are set on every created
+	// transaction.
+	Labels      string
+	CategoryID  int32
+	NeedsReview bool
+}
+
+// DefaultPayeeSimilarity is the PayeeSimilarity used when none is given.
+const DefaultPayeeSimilarity = 0.6
+
+// Importer imports rows parsed from a bank statement into a transaction
+// account.
+type Importer struct {
+	api     API
+	options Options
+}
+
+// New returns a new Importer.
+func New(api API, options *Options) (*Importer, error) {
+	if options == nil || options.TransactionAccountID == 0 {
+		return nil, ErrImporterNoTransactionAccount{}
+	}
+	o := *options
+	if o.PayeeSimilarity == 0 {
+		o.PayeeSimilarity = DefaultPayeeSimilarity
+	}
+	return &Importer{api: api, options: o}, nil
+}
+
+// Status defines the status of an item in a Report.
+type Status string
+
+const (
+	StatusCreate    Status = "create"    // The row will be created.
+	StatusDuplicate Status = "duplicate" // The row already exists, and will be skipped.
+	StatusCreated   Status = "created"   // The row was created.
+	StatusFailed    Status = "failed"    // The row failed to be created.
+)
+
+// Item defines what will happen, or has happened, to a row.
+type Item struct {
+	Row        Row                                                     // The row parsed from the statement.
+	Status     Status                                                  // The status of the row.
+	Options    *pocketsmith.CreateTransactionAccountTransactionOptions // The options used to create the transaction.
+	Duplicate  *pocketsmith.Transaction                                // The existing transaction the row duplicates, if any.
+	Similarity float64                                                 // The payee similarity to the duplicate, if any.
+	Created    *pocketsmith.Transaction                                // The created transaction, if any.
+	Err        error                                                   // The error returned when creating the transaction, if any.
+}
+
+// Report defines the outcome of planning, or applying, an import.
+type Report struct {
+	TransactionAccountID int
+	Items                []*Item
+}
+
+// Count returns the number of items in the report with the given status.
+func (r *Report) Count(status Status) (n int) {
+	for _, i := range r.Items {
+		if i.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteTo writes a human readable summary of the report to w.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, i := range r.Items {
+		fmt.Fprintf(&b, "%-9s %s %10.2f %s", i.Status, i.Row.Date, i.Row.Amount, i.Row.Payee)
+		switch {
+		case i.Duplicate != nil:
+			fmt.Fprintf(
+				&b,
+				" (matches transaction %v %s %q, similarity %.2f)",
+				i.Duplicate.ID,
+				i.Duplicate.Date,
+				i.Duplicate.Payee,
+				i.Similarity,
+			)
+		case i.Created != nil:
+			fmt.Fprintf(&b, " (transaction %v)", i.Created.ID)
+		case i.Err != nil:
+			fmt.Fprintf(&b, " (%v)", i.Err)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(
+		&b,
+		"%v to create, %v duplicates, %v created, %v failed\n",
+		r.Count(StatusCreate),
+		r.Count(StatusDuplicate),
+		r.Count(StatusCreated),
+		r.Count(StatusFailed),
+	)
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Plan compares the given rows against the transactions that already exist
+// in the transaction account, over the date range of the rows, and returns a
+// report of which rows will be created and which are duplicates. Nothing is
+// created; this is a dry-run.
+//
+// Each existing transaction can only be matched by one row, so a statement
+// holding two identical transactions on the same day will only have one
+// skipped if only one exists.
+func (i *Importer) Plan(ctx context.Context, rows []Row) (report *Report, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Plan")
+	defer span.End()
+
+	report = &Report{TransactionAccountID: i.options.TransactionAccountID}
+	if len(rows) == 0 {
+		return report, nil
+	}
+
+	// determine date range.
+	start, end := rows[0].Date, rows[0].Date
+	for _, r := range rows {
+		if r.Date < start {
+			start = r.Date
+		}
+		if r.Date > end {
+			end = r.Date
+		}
+	}
+
+	// list existing transactions in date range.
+	existing, err := i.api.ListTransactionAccountTransactions(
+		newCtx,
+		&pocketsmith.ListTransactionAccountTransactionsOptions{
+			TransactionAccountID: strconv.Itoa(i.options.TransactionAccountID),
+			StartDate:            shiftDate(start, -i.options.DateTolerance),
+			EndDate:              shiftDate(end, i.options.DateTolerance),
+		},
+	)
+	if err != nil {
+		err = ErrImporterFailedListExisting{err}
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// match rows to existing transactions.
+	matched := make(map[int32]bool)
+	for _, r := range rows {
+		item := &Item{Row: r, Status: StatusCreate, Options: i.createOptions(r)}
+		if t, similarity := i.match(r, existing, matched); t != nil {
+			matched[t.ID] = true
+			item.Status = StatusDuplicate
+			item.Duplicate = t
+			item.Similarity = similarity
+		}
+		report.Items = append(report.Items, item)
+	}
+	span.SetAttributes(
+		attribute.Int("create", report.Count(StatusCreate)),
+		attribute.Int("duplicate", report.Count(StatusDuplicate)),
+	)
+	return report, nil
+}
+
+// Apply creates the transactions for the items in the given report that are
+// to be created, updating the status of each item. Items that fail to be
+// created don't stop the import; their error is recorded against the item.
+// Applying a report again only retries the items that failed.
+func (i *Importer) Apply(ctx context.Context, report *Report) (*Report, error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Apply")
+	defer span.End()
+
+	for _, item := range report.Items {
+		if item.Status != StatusCreate && item.Status != StatusFailed {
+			continue
+		}
+		t, err := i.api.CreateTransactionAccountTransaction(newCtx, item.Options)
+		if err != nil {
+			item.Status, item.Err = StatusFailed, err
+			continue
+		}
+		item.Status, item.Created, item.Err = StatusCreated, t, nil
+	}
+	span.SetAttributes(
+		attribute.Int("created", report.Count(StatusCreated)),
+		attribute.Int("failed", report.Count(StatusFailed)),
+	)
+	if n := report.Count(StatusFailed); n > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to create %v transactions", n))
+	}
+	return report, nil
+}
+
+// createOptions maps the given row to the options used to create it.
+func (i *Importer) createOptions(r Row) *pocketsmith.CreateTransactionAccountTransactionOptions {
+	payee := r.Payee
+	if strings.TrimSpace(payee) == "" {
+		payee = r.Memo
+	}
+	return &pocketsmith.CreateTransactionAccountTransactionOptions{
+		TransactionAccountID: i.options.TransactionAccountID,
+		Payee:                payee,
+		Amount:               r.Amount,
+		Date:                 r.Date,
+		Memo:                 r.Memo,
+		ChequeNumber:         r.ChequeNumber,
+		Labels:               i.options.Labels,
+		CategoryID:           i.options.CategoryID,
+		NeedsReview:          i.options.NeedsReview,
+	}
+}
+
+// match returns the best existing transaction that the given row duplicates,
+// that hasn't already been matched, along with its payee similarity.
+func (i *Importer) match(
+	r Row,
+	existing []pocketsmith.Transaction,
+	matched map[int32]bool,
+) (best *pocketsmith.Transaction, bestSimilarity float64) {
+	bestDays := math.MaxInt
+	for j := range existing {
+		t := &existing[j]
+		if matched[t.ID] || math.Abs(t.Amount-r.Amount) >= 0.005 {
+			continue
+		}
+		days := daysBetween(r.Date, t.Date)
+		if days > i.options.DateTolerance {
+			continue
+		}
+		similarity := math.Max(fuzzy.Similarity(r.Payee, t.Payee), fuzzy.Similarity(r.Payee, t.OriginalPayee))
+		if r.Payee == "" {
+			similarity = 1
+		}
+		if !i.options.IgnorePayee && similarity < i.options.PayeeSimilarity {
+			continue
+		}
+
+		// prefer the closest date, then the most similar payee.
+		if best == nil || days < bestDays || (days == bestDays && similarity > bestSimilarity) {
+			best, bestDays, bestSimilarity = t, days, similarity
+		}
+	}
+	return best, bestSimilarity
+}
+
+// shiftDate returns the given YYYY-MM-DD date shifted by the given number of
+// days.
+func shiftDate(date string, days int) string {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return d.AddDate(0, 0, days).Format("2006-01-02")
+}
+
+// daysBetween returns the absolute number of days between the given
+// YYYY-MM-DD dates.
+func daysBetween(a, b string) int {
+	da, errA := time.Parse("2006-01-02", a)
+	db, errB := time.Parse("2006-01-02", b)
+	if errA != nil || errB != nil {
+		return math.MaxInt
+	}
+	days := int(math.Round(da.Sub(db).Hours() / 24))
+	if days < 0 {
+		days = -days
+	}
+	return days
+}
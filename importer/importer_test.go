@@ -0,0 +1,274 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_Parse(t *testing.T) {
+	tests := map[string]struct {
+		parse func() ([]Row, error)
+		want  []Row
+	}{
+		"ofx 1.x": {
+			parse: func() ([]Row, error) {
+				return ParseOFX(strings.NewReader(`OFXHEADER:100
+DATA:OFXSGML
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240102120000[-5:EST]
+<TRNAMT>-4.50
+<FITID>abc1
+<NAME>COFFEE &amp; CO
+<MEMO>card 1234
+</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`))
+			},
+			want: []Row{
+				{ID: "abc1", Date: "2024-01-02", Amount: -4.5, Payee: "COFFEE & CO", Memo: "card 1234"},
+			},
+		},
+		"ofx 2.x": {
+			parse: func() ([]Row, error) {
+				return ParseOFX(strings.NewReader(`<?xml version="1.0"?>
+<OFX><STMTTRN><TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20240103</DTPOSTED>` +
+					`<TRNAMT>1000.00</TRNAMT><FITID>abc2</FITID><NAME>Salary</NAME></STMTTRN></OFX>`))
+			},
+			want: []Row{
+				{ID: "abc2", Date: "2024-01-03", Amount: 1000, Payee: "Salary"},
+			},
+		},
+		"qif": {
+			parse: func() ([]Row, error) {
+				return ParseQIF(strings.NewReader(`!Type:Bank
+D02/01'24
+T-1,234.50
+PRent
+MJanuary
+N101
+LHousing
+^
+D3/01/2024
+T20.00
+PRefund
+^
+`), &QIFOptions{DateFormat: "02/01/2006"})
+			},
+			want: []Row{
+				{Date: "2024-01-02", Amount: -1234.5, Payee: "Rent", Memo: "January", ChequeNumber: "101", Category: "Housing"},
+				{Date: "2024-01-03", Amount: 20, Payee: "Refund"},
+			},
+		},
+		"csv with header & debit / credit columns": {
+			parse: func() ([]Row, error) {
+				return ParseCSV(strings.NewReader(`Statement for account 123
+Date,Description,Debit,Credit
+02/01/2024,Groceries,"$1,050.25",
+03/01/2024,Interest,,0.50
+
+`), &CSVOptions{
+					Columns:    CSVColumns{Date: "Date", Payee: "Description", Debit: "Debit", Credit: "Credit"},
+					DateFormat: "02/01/2006",
+					HasHeader:  true,
+					SkipRows:   1,
+				})
+			},
+			want: []Row{
+				{Date: "2024-01-02", Amount: -1050.25, Payee: "Groceries"},
+				{Date: "2024-01-03", Amount: 0.5, Payee: "Interest"},
+			},
+		},
+		"csv with decimal commas": {
+			parse: func() ([]Row, error) {
+				return ParseCSV(strings.NewReader("2024-01-02;-1.234;Shop\n"), &CSVOptions{
+					Columns: CSVColumns{Date: "0", Amount: "1", Payee: "2"},
+					Comma:   ';',
+					Decimal: ',',
+				})
+			},
+			want: []Row{
+				{Date: "2024-01-02", Amount: -1234, Payee: "Shop"},
+			},
+		},
+		"csv without header, by index": {
+			parse: func() ([]Row, error) {
+				return ParseCSV(strings.NewReader("2024-01-02;(12.00);Shop\n"), &CSVOptions{
+					Columns: CSVColumns{Date: "0", Amount: "1", Payee: "2"},
+					Comma:   ';',
+				})
+			},
+			want: []Row{
+				{Date: "2024-01-02", Amount: -12, Payee: "Shop"},
+			},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			got, err := tt.parse()
+			if err != nil {
+				t.Fatalf("parse returned an error; error=%v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse returned unexpected rows;\nwant=%+v\ngot=%+v\n", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_parseAmount(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		decimal rune
+		want    float64
+	}{
+		"decimal point":                        {value: "-12.50", want: -12.5},
+		"decimal comma":                        {value: "12,50", want: 12.5},
+		"thousands comma":                      {value: "$1,050.25", want: 1050.25},
+		"thousands point":                      {value: "1.050,25 €", want: 1050.25},
+		"repeated thousands":                   {value: "1,234,567", want: 1234567},
+		"single comma before 3 digits":         {value: "1,234", want: 1234},
+		"single comma before 3 digits, as set": {value: "1,234", decimal: ',', want: 1.234},
+		"parentheses":                          {value: "(12,5)", want: -12.5},
+		"trailing DR":                          {value: "7,25 DR", want: -7.25},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseAmount(tt.value, tt.decimal)
+			if err != nil {
+				t.Fatalf("parseAmount() returned an error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseAmount() returned an unexpected amount; want=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
+
+// mockAPI is a mock implementation of the API interface.
+type mockAPI struct {
+	existing []pocketsmith.Transaction
+	listed   *pocketsmith.ListTransactionAccountTransactionsOptions
+	created  []*pocketsmith.CreateTransactionAccountTransactionOptions
+}
+
+func (m *mockAPI) ListTransactionAccountTransactions(
+	_ context.Context,
+	options *pocketsmith.ListTransactionAccountTransactionsOptions,
+) ([]pocketsmith.Transaction, error) {
+	m.listed = options
+	return m.existing, nil
+}
+
+func (m *mockAPI) CreateTransactionAccountTransaction(
+	_ context.Context,
+	options *pocketsmith.CreateTransactionAccountTransactionOptions,
+) (*pocketsmith.Transaction, error) {
+	if options.Payee == "fail" {
+		return nil, fmt.Errorf("an error occurred")
+	}
+	m.created = append(m.created, options)
+	return &pocketsmith.Transaction{ID: int32(100 + len(m.created))}, nil
+}
+
+func Test_Importer(t *testing.T) {
+
+	// setup mock.
+	api := &mockAPI{
+		existing: []pocketsmith.Transaction{
+			{ID: 1, Date: "2024-01-03", Amount: -4.5, Payee: "Coffee Co", OriginalPayee: "SQ *COFFEE CO 1234"},
+			{ID: 2, Date: "2024-01-05", Amount: -20, Payee: "Petrol"},
+		},
+	}
+	rows := []Row{
+		{Date: "2024-01-02", Amount: -4.5, Payee: "SQ *COFFEE CO"}, // duplicate of 1; a day apart.
+		{Date: "2024-01-02", Amount: -4.5, Payee: "SQ *COFFEE CO"}, // a second coffee; not a duplicate.
+		{Date: "2024-01-05", Amount: -20, Payee: "Bakery"},         // same amount, different payee.
+		{Date: "2024-01-06", Amount: -1, Payee: "fail"},
+	}
+
+	// plan.
+	i, err := New(api, &Options{TransactionAccountID: 7, DateTolerance: 2, PayeeSimilarity: 0.5})
+	if err != nil {
+		t.Fatalf("New() returned an error; error=%v", err)
+	}
+	report, err := i.Plan(context.Background(), rows)
+	if err != nil {
+		t.Fatalf("Plan() returned an error; error=%v", err)
+	}
+	if api.listed.StartDate != "2023-12-31" || api.listed.EndDate != "2024-01-08" {
+		t.Errorf("Plan() listed an unexpected date range; got=%+v", api.listed)
+	}
+	var got []Status
+	for _, item := range report.Items {
+		got = append(got, item.Status)
+	}
+	want := []Status{StatusDuplicate, StatusCreate, StatusCreate, StatusCreate}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Plan() returned unexpected statuses;\nwant=%v\ngot=%v\n", want, got)
+	}
+	if len(api.created) != 0 {
+		t.Errorf("Plan() created transactions; got=%v", len(api.created))
+	}
+
+	// apply.
+	report, err = i.Apply(context.Background(), report)
+	if err != nil {
+		t.Fatalf("Apply() returned an error; error=%v", err)
+	}
+	got = nil
+	for _, item := range report.Items {
+		got = append(got, item.Status)
+	}
+	want = []Status{StatusDuplicate, StatusCreated, StatusCreated, StatusFailed}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() returned unexpected statuses;\nwant=%v\ngot=%v\n", want, got)
+	}
+	if len(api.created) != 2 || api.created[0].TransactionAccountID != 7 {
+		t.Errorf("Apply() created unexpected transactions; got=%+v", api.created)
+	}
+}
+
+func Test_Importer_payee(t *testing.T) {
+	existing := []pocketsmith.Transaction{{ID: 1, Date: "2024-01-05", Amount: -20, Payee: "Petrol"}}
+	rows := []Row{{Date: "2024-01-05", Amount: -20, Payee: "Bakery"}}
+	tests := map[string]struct {
+		options *Options
+		want    Status
+	}{
+		"default similarity": {
+			options: &Options{TransactionAccountID: 7},
+			want:    StatusCreate,
+		},
+		"ignore payee": {
+			options: &Options{TransactionAccountID: 7, IgnorePayee: true},
+			want:    StatusDuplicate,
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			i, err := New(&mockAPI{existing: existing}, tt.options)
+			if err != nil {
+				t.Fatalf("New() returned an error; error=%v", err)
+			}
+			report, err := i.Plan(context.Background(), rows)
+			if err != nil {
+				t.Fatalf("Plan() returned an error; error=%v", err)
+			}
+			if got := report.Items[0].Status; got != tt.want {
+				t.Errorf("Plan() returned an unexpected status; want=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+
+	// rgxOFXTransaction matches a transaction in an OFX statement. This works
+	// for both OFX 1.x (SGML) and OFX 2.x (XML), since the aggregate is closed
+	// in both.
+	rgxOFXTransaction = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+
+	// rgxOFXElement matches an element in an OFX transaction. In OFX 1.x the
+	// elements aren't closed, so the value runs until the next tag or newline.
+	rgxOFXElement = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+)
+
+// ParseOFX parses the transactions in an OFX (1.x or 2.x) statement.
+func ParseOFX(r io.Reader) (rows []Row, err error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrParserFailedParse{"ofx", 0, err}
+	}
+	for _, m := range rgxOFXTransaction.FindAllStringSubmatch(string(b), -1) {
+
+		// extract elements.
+		elements := make(map[string]string)
+		for _, e := range rgxOFXElement.FindAllStringSubmatch(m[1], -1) {
+			elements[strings.ToUpper(e[1])] = unescapeOFX(strings.TrimSpace(e[2]))
+		}
+
+		// convert to row.
+		posted := elements["DTPOSTED"]
+		if len(posted) > 8 {
+			posted = posted[:8] // drop the time & timezone.
+		}
+		date, err := parseDate(posted, "20060102")
+		if err != nil {
+			return nil, ErrParserFailedParse{"ofx", 0, err}
+		}
+		amount, err := parseAmount(elements["TRNAMT"], 0)
+		if err != nil {
+			return nil, ErrParserFailedParse{"ofx", 0, err}
+		}
+		payee := elements["NAME"]
+		if payee == "" {
+			payee = elements["PAYEE"]
+		}
+		rows = append(rows, Row{
+			ID:           elements["FITID"],
+			Date:         date,
+			Amount:       amount,
+			Payee:        payee,
+			Memo:         elements["MEMO"],
+			ChequeNumber: elements["CHECKNUM"],
+		})
+	}
+	return rows, nil
+}
+
+// unescapeOFX replaces the XML entities that may appear in an OFX value.
+func unescapeOFX(value string) string {
+	return strings.NewReplacer(
+		"&amp;", "&",
+		"&lt;", "<",
+		"&gt;", ">",
+		"&quot;", `"`,
+		"&apos;", "'",
+	).Replace(value)
+}
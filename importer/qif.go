@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// QIFOptions defines the options for parsing a QIF statement.
+type QIFOptions struct {
+
+	// DateFormat is the layout of the dates in the statement, as used by
+	// time.Parse. QIF dates are usually either US (01/02/2006) or
+	// international (02/01/2006) ordered, depending on the bank. Defaults to
+	// US ordered.
+	DateFormat string
+}
+
+// rgxQIFShortYear matches the two digit years used in some QIF dates (eg.
+// 1/2'24 or 1/2/24).
+var rgxQIFShortYear = regexp.MustCompile(`^(\d{1,2})[/.-](\d{1,2})['/.-](\d{2})$`)
+
+// ParseQIF parses the transactions in a QIF statement. Only the transactions
+// for bank, cash and credit card accounts are parsed; investment transactions
+// are skipped.
+func ParseQIF(r io.Reader, options *QIFOptions) (rows []Row, err error) {
+
+	// setup date layouts.
+	layout := "01/02/2006"
+	if options != nil && options.DateFormat != "" {
+		layout = options.DateFormat
+	}
+	short := strings.NewReplacer("01", "1", "02", "2").Replace(layout) // eg. 1/2/2006.
+	layouts := []string{layout, short, "2006-01-02"}
+
+	// parse lines.
+	var (
+		row     Row
+		started bool
+		skip    bool
+		line    int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+
+		// headers.
+		if strings.HasPrefix(text, "!") {
+			header := strings.ToLower(strings.TrimSpace(text))
+			switch {
+			case strings.HasPrefix(header, "!type:invst"),
+				strings.HasPrefix(header, "!type:cat"),
+				strings.HasPrefix(header, "!type:class"),
+				strings.HasPrefix(header, "!type:memorized"),
+				header == "!account":
+				skip = true
+			case strings.HasPrefix(header, "!type:"):
+				skip = false
+			}
+			continue
+		}
+
+		// fields.
+		code, value := text[0], strings.TrimSpace(text[1:])
+		if code == '^' {
+			if started && !skip {
+				rows = append(rows, row)
+			}
+			row, started = Row{}, false
+			continue
+		}
+		if skip {
+			continue
+		}
+		started = true
+		switch code {
+		case 'D':
+			v := strings.ReplaceAll(value, " ", "")
+			if m := rgxQIFShortYear.FindStringSubmatch(v); m != nil {
+				v = m[1] + "/" + m[2] + "/20" + m[3]
+			}
+			v = strings.ReplaceAll(v, "'", "/")
+			if row.Date, err = parseDate(v, layouts...); err != nil {
+				return nil, ErrParserFailedParse{"qif", line, err}
+			}
+		case 'T', 'U':
+			if row.Amount, err = parseAmount(value, 0); err != nil {
+				return nil, ErrParserFailedParse{"qif", line, err}
+			}
+		case 'P':
+			row.Payee = value
+		case 'M':
+			row.Memo = value
+		case 'N':
+			row.ChequeNumber = value
+		case 'L':
+			row.Category = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ErrParserFailedParse{"qif", line, err}
+	}
+	if started && !skip {
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,103 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Row defines a single transaction parsed from a bank statement.
+type Row struct {
+	ID           string  // The id of the transaction in the statement (eg. an OFX FITID), if any.
+	Date         string  // The date of the transaction, formatted as YYYY-MM-DD.
+	Amount       float64 // The amount of the transaction; negative for debits.
+	Payee        string  // The payee of the transaction.
+	Memo         string  // Any memo attached to the transaction.
+	ChequeNumber string  // The cheque number of the transaction, if any.
+	Category     string  // The category given in the statement, if any.
+}
+
+// parseAmount parses an amount from a statement, removing any currency
+// symbols and thousands separators. Amounts wrapped in parentheses, or with a
+// trailing minus or "DR", are treated as negative. The decimal separator is
+// either '.' or ','; when it's 0, it's detected from the amount (see
+// decimalSeparator).
+func parseAmount(value string, decimal rune) (float64, error) {
+	v := strings.TrimSpace(value)
+	negative := false
+	switch {
+	case strings.HasPrefix(v, "(") && strings.HasSuffix(v, ")"):
+		negative, v = true, v[1:len(v)-1]
+	case strings.HasSuffix(v, "-"):
+		negative, v = true, strings.TrimSuffix(v, "-")
+	case strings.HasSuffix(strings.ToUpper(v), "DR"):
+		negative, v = true, strings.TrimSpace(v[:len(v)-2])
+	case strings.HasSuffix(strings.ToUpper(v), "CR"):
+		v = strings.TrimSpace(v[:len(v)-2])
+	}
+	if decimal == 0 {
+		decimal = decimalSeparator(v)
+	}
+	v = strings.Map(func(r rune) rune {
+		switch {
+		case r == decimal:
+			return '.'
+		case r >= '0' && r <= '9', r == '-', r == '+':
+			return r
+		}
+		return -1
+	}, v)
+	if v == "" {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	amount, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// decimalSeparator returns the decimal separator used in the given amount;
+// whichever of '.' or ',' comes last, unless it's repeated, or it's a single
+// ',' followed by 3 digits, which are taken as thousands separators (eg.
+// "1,234"). It defaults to '.'.
+func decimalSeparator(amount string) rune {
+	i := strings.LastIndexAny(amount, ".,")
+	if i < 0 {
+		return '.'
+	}
+	last, other := rune(amount[i]), ','
+	if last == ',' {
+		other = '.'
+	}
+	switch {
+	case strings.ContainsRune(amount[:i], other):
+		return last // eg. "1.234,50".
+	case strings.Count(amount, string(last)) > 1:
+		return other // eg. "1,234,567".
+	case last == ',' && len(strings.TrimRightFunc(amount[i+1:], notDigit)) == 3:
+		return '.' // eg. "1,234".
+	}
+	return last
+}
+
+// notDigit returns if the given rune isn't a digit.
+func notDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+// parseDate parses a date from a statement using the given layouts, returning
+// it formatted as YYYY-MM-DD.
+func parseDate(value string, layouts ...string) (string, error) {
+	v := strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if d, err := time.Parse(layout, v); err == nil {
+			return d.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date %q", value)
+}
@@ -163,3 +163,78 @@ func (c *Client) ListInstitutions(
 	// list institutions for authed user.
 	return c.ListInstitutionsForUser(newCtx, &ListInstitutionsForUser{UserID: c.authedUser.ID})
 }
+
+// GetInstitutionOptions defines the options for getting an institution.
+type GetInstitutionOptions struct {
+	InstitutionID int `json:"-" validator:"required"`
+}
+
+// GetInstitution gets an institution from Pocketsmith, by the institution id.
+// https://developers.pocketsmith.com/reference/get_institutions-id-1.
+func (c *Client) GetInstitution(
+	ctx context.Context,
+	options *GetInstitutionOptions,
+) (institution *Institution, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "GetInstitution")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// get institution.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/institutions/%v", options.InstitutionID),
+	}, &institution)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to get institution: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return institution, nil
+}
+
+// UpdateInstitutionOptions defines the options for updating an institution.
+type UpdateInstitutionOptions struct {
+	InstitutionID int    `json:"-"                       validator:"required"`
+	Title         string `json:"title,omitempty"`
+	CurrencyCode  string `json:"currency_code,omitempty"`
+}
+
+// UpdateInstitution updates an institution in Pocketsmith, by the institution id.
+// https://developers.pocketsmith.com/reference/put_institutions-id-1.
+func (c *Client) UpdateInstitution(
+	ctx context.Context,
+	options *UpdateInstitutionOptions,
+) (institution *Institution, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "UpdateInstitution")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// update institution.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/institutions/%v", options.InstitutionID),
+		body:   options,
+	}, &institution)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to update institution: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return institution, nil
+}
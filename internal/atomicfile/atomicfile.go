@@ -0,0 +1,36 @@
+// Package atomicfile writes files via a temporary file that is renamed over
+// the original, so that a failed, or interrupted, write never leaves a
+// partial file behind.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write writes the given bytes to the file at the given path, with the given
+// permissions. The bytes are written, and synced, to a temporary file in the
+// same directory, which is then renamed over the original.
+func Write(path string, b []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,67 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func Test_Write(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]struct {
+		path    string
+		perm    os.FileMode
+		wantErr bool
+	}{
+		"new file": {
+			path: filepath.Join(dir, "new.json"),
+			perm: 0o600,
+		},
+		"replaces an existing file": {
+			path: filepath.Join(dir, "existing.json"),
+			perm: 0o644,
+		},
+		"missing directory": {
+			path:    filepath.Join(dir, "missing", "file.json"),
+			perm:    0o600,
+			wantErr: true,
+		},
+	}
+	if err := os.WriteFile(filepath.Join(dir, "existing.json"), []byte("old"), 0o600); err != nil {
+		t.Fatalf("failed to write existing file: %v", err)
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Write(tt.path, []byte(`{"id":1}`), tt.perm)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Write() didn't return an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Write() returned an error: %v", err)
+			}
+			b, err := os.ReadFile(tt.path)
+			if err != nil || string(b) != `{"id":1}` {
+				t.Errorf("Write() wrote unexpected content; got=%q, err=%v", b, err)
+			}
+			info, err := os.Stat(tt.path)
+			if err != nil {
+				t.Fatalf("failed to stat file: %v", err)
+			}
+			if got := info.Mode().Perm(); runtime.GOOS != "windows" && got != tt.perm {
+				t.Errorf("Write() wrote a file with unexpected permissions; want=%v, got=%v", tt.perm, got)
+			}
+		})
+	}
+
+	// no temporary files are left behind.
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Write() left unexpected files behind; got=%v", entries)
+	}
+}
@@ -0,0 +1,54 @@
+// Package fuzzy holds the payee normalisation and similarity shared by the
+// packages that match transactions by payee.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalise lowercases the given payee, keeping only letters and single
+// spaces, so that payees that differ only by card numbers, references or
+// punctuation are the same.
+func Normalise(payee string) string {
+	fields := strings.FieldsFunc(strings.ToLower(payee), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Similarity returns how similar two payees are, from 0 (nothing in common)
+// to 1 (the same), ignoring case, punctuation and numbers. It uses the Dice
+// coefficient of the character bigrams in each payee.
+func Similarity(a, b string) float64 {
+	a, b = Normalise(a), Normalise(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	bigrams := func(s string) map[string]int {
+		out := make(map[string]int)
+		r := []rune(s)
+		for i := 0; i < len(r)-1; i++ {
+			out[string(r[i:i+2])]++
+		}
+		return out
+	}
+	ab, bb := bigrams(a), bigrams(b)
+	total, shared := 0, 0
+	for k, n := range ab {
+		total += n
+		if m, ok := bb[k]; ok {
+			shared += min(n, m)
+		}
+	}
+	for _, n := range bb {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(shared) / float64(total)
+}
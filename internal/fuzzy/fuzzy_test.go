@@ -0,0 +1,27 @@
+package fuzzy
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_Similarity(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+		want float64
+	}{
+		"same, ignoring case & numbers": {a: "SQ *COFFEE CO 1234", b: "sq coffee co", want: 1},
+		"similar":                       {a: "Coffee Co", b: "Coffee Company", want: 0.76},
+		"different":                     {a: "Petrol", b: "Bakery", want: 0},
+		"empty":                         {a: "", b: "Bakery", want: 0},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Similarity() returned an unexpected value; want=%v, got=%v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Package update holds the loop shared by the packages that apply changes to
+// transactions through UpdateTransaction.
+package update
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// API defines the parts of the *pocketsmith.Client used to apply changes.
+type API interface {
+	UpdateTransaction(
+		ctx context.Context,
+		options *pocketsmith.UpdateTransactionOptions,
+	) (*pocketsmith.Transaction, error)
+}
+
+// Outcome defines the outcome of a single update.
+type Outcome struct {
+	Transaction *pocketsmith.Transaction // The updated transaction, if successful.
+	Skipped     bool                     // If there was nothing to update, so nothing was sent.
+	Err         error                    // The error returned when updating, if any.
+}
+
+// Apply sends each of the given options through UpdateTransaction, in order,
+// under an "Apply" span from the given tracer. Nil options are skipped. An
+// update that fails doesn't stop the others; its error is recorded in its
+// outcome.
+func Apply(
+	ctx context.Context,
+	tracerName string,
+	api API,
+	options []*pocketsmith.UpdateTransactionOptions,
+) []Outcome {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Apply")
+	defer span.End()
+
+	outcomes := make([]Outcome, len(options))
+	failed, skipped := 0, 0
+	for i, o := range options {
+		if o == nil {
+			outcomes[i].Skipped = true
+			skipped++
+			continue
+		}
+		outcomes[i].Transaction, outcomes[i].Err = api.UpdateTransaction(newCtx, o)
+		if outcomes[i].Err != nil {
+			failed++
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("applied", len(options)-failed-skipped),
+		attribute.Int("skipped", skipped),
+		attribute.Int("failed", failed),
+	)
+	if failed > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to apply %v changes", failed))
+	}
+	return outcomes
+}
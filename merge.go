@@ -0,0 +1,287 @@
+package pocketsmith
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// mergeBatchSize is the number of transactions moved between calls to
+// MergeCategoriesOptions.Progress.
+var mergeBatchSize = 100
+
+// mergePasses is the number of times the transactions of the source are
+// listed & moved, while more keep arriving in it, before giving up.
+var mergePasses = 3
+
+// MergeStage defines a stage of a category merge.
+type MergeStage string
+
+const (
+	MergeStageRules        MergeStage = "rules"        // Copying the category rules of the source to the target.
+	MergeStageChildren     MergeStage = "children"     // Moving the children of the source under the target.
+	MergeStageTransactions MergeStage = "transactions" // Moving the transactions of the source to the target.
+	MergeStageDelete       MergeStage = "delete"       // Deleting the source.
+	MergeStageDone         MergeStage = "done"         // The merge is done.
+)
+
+// MergeCategoriesReport defines the progress of a category merge. It can be
+// saved (eg. as JSON) and given back to MergeCategories to resume a merge that
+// was interrupted.
+type MergeCategoriesReport struct {
+	SourceID     int32      `json:"source_id"`
+	TargetID     int32      `json:"target_id"`
+	Stage        MergeStage `json:"stage"`        // The stage the merge reached.
+	Rules        []int32    `json:"rules"`        // The ids of the source rules copied to the target.
+	Children     []int32    `json:"children"`     // The ids of the children moved under the target.
+	Transactions []int32    `json:"transactions"` // The ids of the transactions moved to the target.
+	Failed       []int32    `json:"failed"`       // The ids of the transactions that failed to move, in the last attempt.
+}
+
+// MergeCategoriesOptions defines the options for merging one category into
+// another.
+type MergeCategoriesOptions struct {
+	UserID   int   `validator:"required"` // The id of the user with both categories.
+	SourceID int32 `validator:"required"` // The id of the category merged, then deleted.
+	TargetID int32 `validator:"required"` // The id of the category merged into.
+
+	// Resume is the report returned from an interrupted merge of the same
+	// categories, to carry on from.
+	Resume *MergeCategoriesReport
+
+	// Progress, if given, is called with the report after each stage, and
+	// after each batch of transactions is moved, so it can be saved.
+	Progress func(*MergeCategoriesReport)
+
+	// BulkOptions are used to move the transactions.
+	BulkOptions
+}
+
+// MergeCategories merges the source category into the target, in Pocketsmith;
+// the category rules of the source are copied to the target, the children of
+// the source are moved under the target, every transaction in the source is
+// moved to the target with UpdateTransaction, then the source is deleted.
+//
+// A merge that fails, or is interrupted, returns the report of how far it got
+// along with the error; running it again (with Resume) carries on from there.
+// Each stage works from what is left in the source, so nothing is moved twice,
+// and rules already copied aren't copied again. The source is only deleted
+// once it holds no transactions, so they're never orphaned.
+//
+// NOTE: children with the same title as a child of the target are moved
+// alongside it, rather than merged into it; merge them afterwards.
+func (c *Client) MergeCategories(
+	ctx context.Context,
+	options *MergeCategoriesOptions,
+) (report *MergeCategoriesReport, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "MergeCategories")
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to merge categories: %v", err))
+			span.RecordError(err)
+		}
+	}()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		return nil, err
+	}
+	report = &MergeCategoriesReport{SourceID: options.SourceID, TargetID: options.TargetID}
+	if options.Resume != nil {
+		if options.Resume.SourceID != options.SourceID || options.Resume.TargetID != options.TargetID {
+			return nil, ErrMergeInvalid{"the report to resume is for a merge of other categories"}
+		}
+		r := *options.Resume
+		report = &r
+	}
+	if report.Stage == MergeStageDone {
+		return report, nil
+	}
+	progress := func(stage MergeStage) {
+		report.Stage = stage
+		if options.Progress != nil {
+			options.Progress(report)
+		}
+	}
+
+	// check the categories; the target can't be the source, or under it.
+	categories, err := c.ListCategoriesForUser(newCtx, &ListCategoriesForUserOptions{UserID: options.UserID})
+	if err != nil {
+		return report, err
+	}
+	tree := NewCategoryTree(categories)
+	source, ok := tree.ByID(options.SourceID)
+	if !ok && report.Stage == MergeStageDelete {
+		progress(MergeStageDone) // deleted, but interrupted before reporting it.
+		return report, nil
+	}
+	if !ok {
+		return report, ErrMergeInvalid{fmt.Sprintf("source category %v doesn't exist", options.SourceID)}
+	}
+	if _, ok := tree.ByID(options.TargetID); !ok {
+		return report, ErrMergeInvalid{fmt.Sprintf("target category %v doesn't exist", options.TargetID)}
+	}
+	if options.SourceID == options.TargetID {
+		return report, ErrMergeInvalid{"can't merge a category into itself"}
+	}
+	for _, a := range tree.Ancestors(options.TargetID) {
+		if a.ID == options.SourceID {
+			return report, ErrMergeInvalid{"can't merge a category into one of its descendants"}
+		}
+	}
+	span.SetAttributes(
+		attribute.String("source", tree.Path(options.SourceID)),
+		attribute.String("target", tree.Path(options.TargetID)),
+	)
+
+	// copy the rules of the source, unless the target has one for the payee.
+	progress(MergeStageRules)
+	if err := c.mergeRules(newCtx, options, report); err != nil {
+		return report, ErrMergeFailed{MergeStageRules, err}
+	}
+
+	// move the children of the source.
+	progress(MergeStageChildren)
+	for _, child := range source.Children {
+		if child == nil {
+			continue
+		}
+		targetID := options.TargetID
+		if _, err := c.UpdateCategory(newCtx, &UpdateCategoryOptions{
+			CategoryID: child.ID,
+			ParentID:   &targetID,
+		}); err != nil {
+			return report, ErrMergeFailed{MergeStageChildren, err}
+		}
+		report.Children = append(report.Children, child.ID)
+	}
+
+	// move the transactions of the source, until listing it again finds none;
+	// more can arrive while moving them, eg. from the rules of the source,
+	// which apply until it's deleted.
+	progress(MergeStageTransactions)
+	for pass := 1; ; pass++ {
+		moved, err := c.mergeTransactions(newCtx, options, report, progress)
+		if err != nil {
+			return report, err
+		}
+		if moved == 0 {
+			break
+		}
+		if pass == mergePasses {
+			return report, ErrMergeIncomplete{arrived: moved}
+		}
+	}
+
+	// delete the source.
+	progress(MergeStageDelete)
+	if err := c.DeleteCategory(newCtx, &DeleteCategoryOptions{CategoryID: options.SourceID}); err != nil {
+		return report, ErrMergeFailed{MergeStageDelete, err}
+	}
+	progress(MergeStageDone)
+	span.SetAttributes(attribute.Int("transactions", len(report.Transactions)))
+	return report, nil
+}
+
+// mergeRules copies the category rules of the source to the target, skipping
+// rules already copied, or with a payee the target already has a rule for.
+func (c *Client) mergeRules(
+	ctx context.Context,
+	options *MergeCategoriesOptions,
+	report *MergeCategoriesReport,
+) error {
+	rules, err := c.ListCategoryRulesForUser(ctx, &ListCategoryRulesForUserOptions{UserID: options.UserID})
+	if err != nil {
+		return err
+	}
+	copied := make(map[int32]bool, len(report.Rules))
+	for _, id := range report.Rules {
+		copied[id] = true
+	}
+	payees := make(map[string]bool)
+	for _, r := range rules {
+		if r.Category.ID == options.TargetID {
+			payees[strings.ToLower(r.PayeeMatches)] = true
+		}
+	}
+	for _, r := range rules {
+		if r.Category.ID != options.SourceID || copied[r.ID] {
+			continue
+		}
+		if payee := strings.ToLower(r.PayeeMatches); !payees[payee] {
+			if _, err := c.CreateCategoryRule(ctx, &CreateCategoryRuleOptions{
+				CategoryID:   options.TargetID,
+				PayeeMatches: r.PayeeMatches,
+			}); err != nil {
+				return err
+			}
+			payees[payee] = true
+		}
+		report.Rules = append(report.Rules, r.ID)
+	}
+	return nil
+}
+
+// mergeTransactions moves every transaction in the source to the target, in
+// batches, reporting progress after each, and returns how many were moved.
+// They're all listed first, since moving them while paging would shift the
+// pages.
+func (c *Client) mergeTransactions(
+	ctx context.Context,
+	options *MergeCategoriesOptions,
+	report *MergeCategoriesReport,
+	progress func(MergeStage),
+) (moved int, err error) {
+	var ids []int32
+	err = c.ListTransactionsForCategoryPages(
+		ctx,
+		&ListTransactionsForCategoryOptions{CategoryID: options.SourceID},
+		func(batch Transactions) error {
+			for _, t := range batch {
+				if t.Category.ID == options.SourceID {
+					ids = append(ids, t.ID)
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return 0, ErrMergeFailed{MergeStageTransactions, err}
+	}
+	report.Failed = nil
+	var failed BulkResults
+	for start := 0; start < len(ids); start += mergeBatchSize {
+		batch := ids[start:min(start+mergeBatchSize, len(ids))]
+		bulk := &BulkUpdateTransactionsOptions{BulkOptions: options.BulkOptions}
+		for _, id := range batch {
+			bulk.Transactions = append(bulk.Transactions, &UpdateTransactionOptions{
+				TransactionID: id,
+				CategoryID:    options.TargetID,
+			})
+		}
+		for _, r := range c.BulkUpdateTransactions(ctx, bulk) {
+			if r.Err != nil {
+				report.Failed = append(report.Failed, batch[r.Index])
+				failed = append(failed, r)
+				continue
+			}
+			report.Transactions = append(report.Transactions, batch[r.Index])
+			moved++
+		}
+		progress(MergeStageTransactions)
+		if err := ctx.Err(); err != nil {
+			return moved, ErrMergeFailed{MergeStageTransactions, err}
+		}
+	}
+	if len(failed) > 0 {
+		return moved, ErrMergeIncomplete{failed: len(failed), first: failed[0].Err}
+	}
+	return moved, nil
+}
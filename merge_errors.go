@@ -0,0 +1,55 @@
+package pocketsmith
+
+import (
+	"fmt"
+)
+
+// ErrMergeInvalid is returned when two categories can't be merged.
+type ErrMergeInvalid struct {
+	reason string
+}
+
+func (e ErrMergeInvalid) Error() string {
+	return fmt.Sprintf("can't merge categories: %s", e.reason)
+}
+
+// ErrMergeFailed is returned when a stage of a category merge fails.
+type ErrMergeFailed struct {
+	stage MergeStage
+	err   error
+}
+
+func (e ErrMergeFailed) Error() string {
+	return fmt.Sprintf("failed to merge categories at stage %s: %v", e.stage, e.err)
+}
+
+func (e ErrMergeFailed) Unwrap() error {
+	return e.err
+}
+
+// ErrMergeIncomplete is returned when some transactions failed to be moved
+// during a category merge, or kept arriving in the source while they were
+// moved; the source isn't deleted.
+type ErrMergeIncomplete struct {
+	failed  int
+	first   error
+	arrived int // The transactions moved in the last pass, that arrived while merging.
+}
+
+func (e ErrMergeIncomplete) Error() string {
+	if e.failed == 0 {
+		return fmt.Sprintf(
+			"%v transactions arrived in the source category while merging, so it wasn't deleted; merge again",
+			e.arrived,
+		)
+	}
+	return fmt.Sprintf(
+		"%v transactions failed to move, so the source category wasn't deleted; first error: %v",
+		e.failed,
+		e.first,
+	)
+}
+
+func (e ErrMergeIncomplete) Unwrap() error {
+	return e.first
+}
@@ -0,0 +1,216 @@
+package pocketsmith
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_MergeCategories(t *testing.T) {
+
+	// setup mock; Supermarket (3) is merged into Food/Groceries (2), and
+	// moving transaction 102 fails the first time.
+	var mu sync.Mutex
+	categoryOf := map[int32]int32{100: 3, 101: 3, 102: 3, 103: 3, 104: 2}
+	failed := false
+	var calls []string
+	c := newMockClient(func(req *http.Request) *http.Response {
+		mu.Lock()
+		defer mu.Unlock()
+		path := req.URL.Path
+		switch {
+		case path == "/users/1/categories":
+			return mockResponse(http.StatusOK, Categories{
+				{ID: 1, Title: "Food", Children: []*Category{{ID: 2, Title: "Groceries"}}},
+				{ID: 3, Title: "Supermarket", Children: []*Category{{ID: 4, Title: "Bulk"}}},
+			})
+		case path == "/users/1/category_rules":
+			return mockResponse(http.StatusOK, CategoryRules{
+				{ID: 10, Category: Category{ID: 3}, PayeeMatches: "coles"},
+				{ID: 11, Category: Category{ID: 3}, PayeeMatches: "aldi"},
+				{ID: 12, Category: Category{ID: 2}, PayeeMatches: "ALDI"},
+			})
+		case path == "/categories/3/transactions":
+			var transactions Transactions
+			for id := int32(100); id <= 104; id++ {
+				transactions = append(transactions, Transaction{ID: id, Category: Category{ID: categoryOf[id]}})
+			}
+			return mockResponse(http.StatusOK, transactions)
+		case strings.HasPrefix(path, "/transactions/"):
+			id, _ := strconv.Atoi(strings.TrimPrefix(path, "/transactions/"))
+			if id == 102 && !failed {
+				failed = true
+				return mockResponse(http.StatusNotFound, map[string]string{"error": "not found"})
+			}
+			var options UpdateTransactionOptions
+			json.NewDecoder(req.Body).Decode(&options)
+			categoryOf[int32(id)] = options.CategoryID
+			return mockResponse(http.StatusOK, Transaction{ID: int32(id)})
+		}
+		b, _ := io.ReadAll(req.Body)
+		calls = append(calls, req.Method+" "+path+" "+string(b))
+		return mockResponse(http.StatusOK, map[string]any{"id": 1})
+	})
+	defer func(size int) { mergeBatchSize = size }(mergeBatchSize)
+	mergeBatchSize = 2
+
+	// run tests.
+	var stages []MergeStage
+	options := &MergeCategoriesOptions{
+		UserID:      1,
+		SourceID:    3,
+		TargetID:    2,
+		Progress:    func(r *MergeCategoriesReport) { stages = append(stages, r.Stage) },
+		BulkOptions: BulkOptions{RetryWait: time.Millisecond},
+	}
+	report, err := c.MergeCategories(context.Background(), options)
+	var incomplete ErrMergeIncomplete
+	if !errors.As(err, &incomplete) {
+		t.Fatalf("MergeCategories() returned an unexpected error; got=%v", err)
+	}
+	want := &MergeCategoriesReport{
+		SourceID:     3,
+		TargetID:     2,
+		Stage:        MergeStageTransactions,
+		Rules:        []int32{10, 11},
+		Children:     []int32{4},
+		Transactions: []int32{100, 101, 103},
+		Failed:       []int32{102},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("MergeCategories() returned an unexpected report;\nwant=%+v\ngot=%+v", want, report)
+	}
+	wantStages := []MergeStage{
+		MergeStageRules,
+		MergeStageChildren,
+		MergeStageTransactions,
+		MergeStageTransactions,
+		MergeStageTransactions,
+	}
+	if !reflect.DeepEqual(stages, wantStages) {
+		t.Errorf("MergeCategories() reported unexpected progress;\nwant=%v\ngot=%v", wantStages, stages)
+	}
+	wantCalls := []string{
+		`POST /categories/2/category_rules {"payee_matches":"coles"}`,
+		`PUT /categories/4 {"parent_id":2}`,
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("MergeCategories() made unexpected calls;\nwant=%q\ngot=%q", wantCalls, calls)
+	}
+
+	// resume; only the transaction left is moved, and the source deleted. The
+	// mock still lists Bulk under the source, so it is moved again.
+	calls = nil
+	options.Resume = report
+	report, err = c.MergeCategories(context.Background(), options)
+	if err != nil {
+		t.Fatalf("MergeCategories() returned an error when resuming: %v", err)
+	}
+	if report.Stage != MergeStageDone || !reflect.DeepEqual(report.Transactions, []int32{100, 101, 103, 102}) {
+		t.Errorf("MergeCategories() returned an unexpected report when resuming; got=%+v", report)
+	}
+	wantCalls = []string{
+		`PUT /categories/4 {"parent_id":2}`,
+		`DELETE /categories/3 `,
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("MergeCategories() made unexpected calls when resuming;\nwant=%q\ngot=%q", wantCalls, calls)
+	}
+
+	// invalid.
+	_, err = c.MergeCategories(context.Background(), &MergeCategoriesOptions{UserID: 1, SourceID: 1, TargetID: 2})
+	if err == nil || err.Error() != "can't merge categories: can't merge a category into one of its descendants" {
+		t.Errorf("MergeCategories() returned an unexpected error; got=%v", err)
+	}
+}
+
+func Test_MergeCategories_arriving(t *testing.T) {
+	tests := map[string]struct {
+		arrivals         int // The listings of the source that find a new transaction in it.
+		wantTransactions []int32
+		wantDeleted      bool
+		wantErr          string
+	}{
+		"arrives once": {
+			arrivals:         2,
+			wantTransactions: []int32{200, 201},
+			wantDeleted:      true,
+		},
+		"keeps arriving": {
+			arrivals:         5,
+			wantTransactions: []int32{200, 201, 202},
+			wantErr:          "1 transactions arrived in the source category while merging, so it wasn't deleted; merge again",
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+
+			// setup mock; each listing of the source, up to arrivals, finds a
+			// transaction that arrived since the last.
+			var mu sync.Mutex
+			categoryOf := map[int32]int32{}
+			listings := 0
+			deleted := false
+			c := newMockClient(func(req *http.Request) *http.Response {
+				mu.Lock()
+				defer mu.Unlock()
+				path := req.URL.Path
+				switch {
+				case path == "/users/1/categories":
+					return mockResponse(http.StatusOK, Categories{{ID: 2, Title: "Groceries"}, {ID: 3, Title: "Supermarket"}})
+				case path == "/users/1/category_rules":
+					return mockResponse(http.StatusOK, CategoryRules{})
+				case path == "/categories/3/transactions":
+					if listings < tt.arrivals {
+						categoryOf[int32(200+listings)] = 3
+					}
+					listings++
+					var transactions Transactions
+					for id, category := range categoryOf {
+						if category == 3 {
+							transactions = append(transactions, Transaction{ID: id, Category: Category{ID: category}})
+						}
+					}
+					return mockResponse(http.StatusOK, transactions)
+				case strings.HasPrefix(path, "/transactions/"):
+					id, _ := strconv.Atoi(strings.TrimPrefix(path, "/transactions/"))
+					categoryOf[int32(id)] = 2
+					return mockResponse(http.StatusOK, Transaction{ID: int32(id)})
+				case req.Method == http.MethodDelete:
+					deleted = true
+				}
+				return mockResponse(http.StatusOK, map[string]any{"id": 1})
+			})
+
+			report, err := c.MergeCategories(context.Background(), &MergeCategoriesOptions{
+				UserID:   1,
+				SourceID: 3,
+				TargetID: 2,
+			})
+			if tt.wantErr != "" {
+				var incomplete ErrMergeIncomplete
+				if !errors.As(err, &incomplete) || err.Error() != tt.wantErr {
+					t.Fatalf("MergeCategories() returned an unexpected error;\nwant=%v\ngot=%v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("MergeCategories() returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(report.Transactions, tt.wantTransactions) {
+				t.Errorf("MergeCategories() moved unexpected transactions;\nwant=%v\ngot=%v", tt.wantTransactions, report.Transactions)
+			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("MergeCategories() deleted the source=%v; want=%v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
@@ -3,9 +3,14 @@ package pocketsmith
 // This file contains common mocks used across both public tests.
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+
+	"github.com/go-playground/validator/v10"
 )
 
 // mockRoundTripper is a mock implementation of the http.RoundTripper interface,
@@ -23,3 +28,29 @@ func (m *mockRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	}
 	return m.MockFunc(req), nil
 }
+
+// newMockClient returns a client that sends every request to the given mock
+// function, rather than the API.
+func newMockClient(mock func(req *http.Request) *http.Response) *Client {
+	return &Client{
+		httpClient: &http.Client{Transport: &mockRoundTripper{MockFunc: mock}},
+		logger:     slog.Default(),
+		headers:    make(http.Header),
+		validator:  validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+// mockResponse returns a response with the given status code and body; a
+// string body is returned as is, anything else is marshalled to json.
+func mockResponse(code int, body any) *http.Response {
+	s, ok := body.(string)
+	if !ok {
+		b, _ := json.Marshal(body)
+		s = string(b)
+	}
+	return &http.Response{
+		StatusCode: code,
+		Body:       io.NopCloser(strings.NewReader(s)),
+		Header:     make(http.Header),
+	}
+}
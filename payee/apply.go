@@ -0,0 +1,39 @@
+package payee
+
+import (
+	"context"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/internal/update"
+)
+
+// The name of the tracer output in the traces.
+const tracerName = "pocketsmith-go/payee"
+
+// API defines the parts of the *pocketsmith.Client used to apply changes.
+type API interface {
+	UpdateTransaction(
+		ctx context.Context,
+		options *pocketsmith.UpdateTransactionOptions,
+	) (*pocketsmith.Transaction, error)
+}
+
+// Result defines the outcome of applying a change.
+type Result struct {
+	Change      Change                   // The change that was applied.
+	Transaction *pocketsmith.Transaction // The updated transaction, if successful.
+	Err         error                    // The error returned when applying the change, if any.
+}
+
+// Apply applies the given changes through UpdateTransaction. A change that
+// fails to apply doesn't stop the others; its error is recorded in its result.
+func Apply(ctx context.Context, api API, changes []Change) (results []Result) {
+	options := make([]*pocketsmith.UpdateTransactionOptions, len(changes))
+	for i, c := range changes {
+		options[i] = c.Options
+	}
+	for i, o := range update.Apply(ctx, tracerName, api, options) {
+		results = append(results, Result{Change: changes[i], Transaction: o.Transaction, Err: o.Err})
+	}
+	return results
+}
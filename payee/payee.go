@@ -0,0 +1,267 @@
+// Package payee cleans up the noisy payees that banks send, such as
+// "SQ *COFFEE 1234 SYDNEY AU", into readable names, such as "Coffee".
+//
+// Built-in cleanup rules strip payment processor prefixes, card numbers,
+// references and locations, and a user-defined alias table maps what's left
+// to a preferred name. Changes are previewed first, then applied through
+// UpdateTransaction:
+//
+//	n := payee.New(&payee.Options{Aliases: aliases})
+//	changes := n.Preview(transactions)
+//	for _, c := range changes {
+//		fmt.Println(c)
+//	}
+//	results := payee.Apply(ctx, c, changes)
+package payee
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// Alias maps payees to a preferred name.
+type Alias struct {
+
+	// Name is the preferred name of the payee.
+	Name string `json:"name" yaml:"name"`
+
+	// Contains are case-insensitive substrings; the alias is used when any of
+	// them is in the original payee, or in the cleaned up payee.
+	Contains []string `json:"contains" yaml:"contains"`
+}
+
+// Options defines the options for a Normaliser.
+type Options struct {
+
+	// Aliases are checked in order; the first that matches is used.
+	Aliases []Alias
+
+	// Prefixes, Processors and Locations extend the built-in bank prefixes
+	// (eg. "EFTPOS"), payment processors (eg. "SQ", in "SQ *COFFEE") and
+	// locations (eg. "SYDNEY") that are stripped.
+	Prefixes   []string
+	Processors []string
+	Locations  []string
+
+	// DisableCleanup turns off the built-in cleanup rules, so that only the
+	// aliases are used.
+	DisableCleanup bool
+
+	// Overwrite includes transactions whose payee has already been changed
+	// from the original payee, which are skipped by default so that manual
+	// edits aren't lost.
+	Overwrite bool
+}
+
+// Normaliser normalises payees.
+type Normaliser struct {
+	options    Options
+	prefixes   []string
+	processors map[string]bool
+	locations  map[string]bool
+}
+
+// New returns a Normaliser using the given options.
+func New(options *Options) *Normaliser {
+	n := &Normaliser{processors: make(map[string]bool), locations: make(map[string]bool)}
+	if options != nil {
+		n.options = *options
+	}
+	n.prefixes = append(n.prefixes, prefixes...)
+	for _, p := range n.options.Prefixes {
+		n.prefixes = append(n.prefixes, strings.ToUpper(p))
+	}
+	for _, p := range append(processors, n.options.Processors...) {
+		n.processors[strings.ToUpper(p)] = true
+	}
+	for _, l := range append(locations, n.options.Locations...) {
+		n.locations[strings.ToUpper(l)] = true
+	}
+	return n
+}
+
+// The built-in prefixes added by payment processors and banks.
+var prefixes = []string{
+	"VISA DEBIT PURCHASE",
+	"DEBIT CARD PURCHASE",
+	"CARD PURCHASE",
+	"VISA PURCHASE",
+	"EFTPOS PURCHASE",
+	"EFTPOS",
+	"POS",
+	"PURCHASE",
+	"DIRECT DEBIT",
+}
+
+// The built-in payment processors, whose name is put before the merchant with
+// an asterisk; eg. Square ("SQ *COFFEE") and Toast ("TST* DINER").
+var processors = []string{
+	"SQ", "TST", "SP", "PP", "PAYPAL", "IZ", "ZETTLE", "SUMUP", "LS",
+}
+
+// The built-in locations; country codes, states and large cities.
+var locations = []string{
+	"AU", "AUS", "NZ", "NZL", "US", "USA", "GB", "GBR", "UK", "CA", "IE", "SG",
+	"NSW", "VIC", "QLD", "WA", "SA", "TAS", "ACT", "NT",
+	"NY", "TX", "FL", "IL", "MA",
+	"SYDNEY", "MELBOURNE", "BRISBANE", "PERTH", "ADELAIDE", "HOBART", "DARWIN",
+	"CANBERRA", "GOLD COAST", "NEWCASTLE", "AUCKLAND", "WELLINGTON",
+	"CHRISTCHURCH", "HAMILTON", "DUNEDIN", "LONDON", "MANCHESTER", "DUBLIN",
+	"NEW YORK", "SAN FRANCISCO", "LOS ANGELES", "SEATTLE", "CHICAGO",
+	"TORONTO", "VANCOUVER", "SINGAPORE",
+}
+
+var (
+
+	// processor matches what could be a processor prefix, eg. "SQ *" or
+	// "TST* "; only known processors are stripped, since merchants use the
+	// same form, eg. "UBER *TRIP".
+	processor = regexp.MustCompile(`^([A-Za-z]{2,8}) ?\* ?`)
+
+	// reference matches a reference after an asterisk, eg. "MKTP US*2K4XY".
+	reference = regexp.MustCompile(`\*\S*`)
+
+	// masked matches a masked card number, eg. "XXXX1234" or "#123".
+	masked = regexp.MustCompile(`^[xX*#]+\d*$`)
+)
+
+// Payee returns the normalised version of the given payee.
+func (n *Normaliser) Payee(original string) string {
+	cleaned := original
+	if !n.options.DisableCleanup {
+		cleaned = n.clean(original)
+	}
+	for _, a := range n.options.Aliases {
+		for _, c := range a.Contains {
+			c = strings.ToLower(c)
+			if c == "" {
+				continue
+			}
+			if strings.Contains(strings.ToLower(original), c) ||
+				strings.Contains(strings.ToLower(cleaned), c) {
+				return a.Name
+			}
+		}
+	}
+	return cleaned
+}
+
+// clean applies the built-in cleanup rules to the given payee.
+func (n *Normaliser) clean(original string) string {
+	s := strings.Join(strings.Fields(original), " ")
+
+	// strip prefixes.
+	for changed := true; changed; {
+		changed = false
+		upper := strings.ToUpper(s)
+		for _, p := range n.prefixes {
+			if strings.HasPrefix(upper, p+" ") {
+				s, changed = s[len(p)+1:], true
+				break
+			}
+		}
+		if m := processor.FindStringSubmatchIndex(s); m != nil && m[1] < len(s) &&
+			n.processors[strings.ToUpper(s[m[2]:m[3]])] {
+			s, changed = s[m[1]:], true
+		}
+	}
+	s = reference.ReplaceAllString(s, "")
+
+	// strip card numbers and references.
+	var tokens []string
+	for _, token := range strings.Fields(s) {
+		digits := 0
+		for _, r := range token {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		if digits >= 3 || masked.MatchString(token) {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+
+	// strip trailing locations, keeping at least one token.
+	for len(tokens) > 1 {
+		last := strings.ToUpper(tokens[len(tokens)-1])
+		if len(tokens) > 2 && n.locations[strings.ToUpper(tokens[len(tokens)-2])+" "+last] {
+			tokens = tokens[:len(tokens)-2]
+			continue
+		}
+		if !n.locations[last] {
+			break
+		}
+		tokens = tokens[:len(tokens)-1]
+	}
+	s = strings.Join(tokens, " ")
+	if s == "" {
+		return strings.TrimSpace(original)
+	}
+
+	// title case payees that are all upper case.
+	if strings.ToUpper(s) == s && strings.ToLower(s) != s {
+		r := []rune(strings.ToLower(s))
+		for i := range r {
+			if i == 0 || (!unicode.IsLetter(r[i-1]) && r[i-1] != '\'') {
+				r[i] = unicode.ToUpper(r[i])
+			}
+		}
+		s = string(r)
+	}
+	return s
+}
+
+// Change defines a change to the payee of a transaction.
+type Change struct {
+	Transaction pocketsmith.Transaction               `json:"transaction"`
+	From        string                                `json:"from"`
+	To          string                                `json:"to"`
+	Options     *pocketsmith.UpdateTransactionOptions `json:"-"` // The options used to apply the change.
+}
+
+// String returns the change as a single line diff, eg.
+// "transaction 123 (-4.50): payee "SQ *COFFEE 1234 SYDNEY AU"→"Coffee"".
+func (c Change) String() string {
+	return fmt.Sprintf(
+		"transaction %v (%.2f): payee %q→%q",
+		c.Transaction.ID,
+		c.Transaction.Amount,
+		c.From,
+		c.To,
+	)
+}
+
+// Preview returns the changes to the payees of the given transactions,
+// without updating anything. The original payee is normalised, falling back
+// to the payee when there is no original payee. Transactions whose payee
+// wouldn't change are not returned.
+func (n *Normaliser) Preview(transactions pocketsmith.Transactions) (changes []Change) {
+	for _, t := range transactions {
+		original := t.OriginalPayee
+		if original == "" {
+			original = t.Payee
+		}
+		if !n.options.Overwrite && t.OriginalPayee != "" && t.Payee != t.OriginalPayee {
+			continue
+		}
+		to := n.Payee(original)
+		if to == "" || to == t.Payee {
+			continue
+		}
+		changes = append(changes, Change{
+			Transaction: t,
+			From:        t.Payee,
+			To:          to,
+			Options: &pocketsmith.UpdateTransactionOptions{
+				TransactionID: t.ID,
+				Payee:         to,
+			},
+		})
+	}
+	return changes
+}
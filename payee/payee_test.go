@@ -0,0 +1,75 @@
+package payee
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_Payee(t *testing.T) {
+	n := New(&Options{
+		Aliases: []Alias{
+			{Name: "Amazon", Contains: []string{"amzn", "amazon"}},
+		},
+		Locations: []string{"Surry Hills"},
+	})
+	tests := map[string]string{
+		"SQ *COFFEE 1234 SYDNEY AU":            "Coffee",
+		"TST* Joe's Diner 0042 New York NY":    "Joe's Diner",
+		"EFTPOS WOOLWORTHS 1234 SURRY HILLS":   "Woolworths",
+		"VISA PURCHASE 7-ELEVEN 2145 XXXX1234": "7-Eleven",
+		"AMZN Mktp US*2K4XY3":                  "Amazon",
+		"UBER *TRIP":                           "Uber",
+		"Netflix.com":                          "Netflix.com",
+		"1234567":                              "1234567",
+	}
+	for original, want := range tests {
+
+		// run tests.
+		t.Run(original, func(t *testing.T) {
+			if got := n.Payee(original); got != want {
+				t.Errorf("Payee() returned an unexpected payee; want=%q, got=%q", want, got)
+			}
+		})
+	}
+}
+
+// mockAPI is a mock implementation of the API interface.
+type mockAPI struct {
+	updated []*pocketsmith.UpdateTransactionOptions
+}
+
+func (m *mockAPI) UpdateTransaction(
+	_ context.Context,
+	options *pocketsmith.UpdateTransactionOptions,
+) (*pocketsmith.Transaction, error) {
+	m.updated = append(m.updated, options)
+	return &pocketsmith.Transaction{ID: options.TransactionID}, nil
+}
+
+func Test_Apply(t *testing.T) {
+	transactions := pocketsmith.Transactions{
+		{ID: 1, Payee: "SQ *COFFEE 1234", OriginalPayee: "SQ *COFFEE 1234"},
+		{ID: 2, Payee: "My Cafe", OriginalPayee: "SQ *CAFE 99999"}, // manually edited.
+		{ID: 3, Payee: "Coffee", OriginalPayee: "SQ *COFFEE 5678"}, // already clean.
+	}
+	changes := New(nil).Preview(transactions)
+	if len(changes) != 1 || changes[0].String() != `transaction 1 (0.00): payee "SQ *COFFEE 1234"→"Coffee"` {
+		t.Fatalf("Preview() returned unexpected changes; got=%v", changes)
+	}
+	if got := New(&Options{Overwrite: true}).Preview(transactions); len(got) != 2 {
+		t.Errorf("Preview() didn't overwrite manual edits; got=%v", got)
+	}
+
+	api := &mockAPI{}
+	results := Apply(context.Background(), api, changes)
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("Apply() returned unexpected results; got=%+v", results)
+	}
+	want := &pocketsmith.UpdateTransactionOptions{TransactionID: 1, Payee: "Coffee"}
+	if !reflect.DeepEqual(api.updated[0], want) {
+		t.Errorf("Apply() sent unexpected options;\nwant=%+v\ngot=%+v\n", want, api.updated[0])
+	}
+}
@@ -1,6 +1,10 @@
 package pocketsmith
 
-import "net/url"
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
 
 // setupQueries largely exists to add default queries to the given queries
 // *map[string]string. In this case, setupQueries returns url.Values that
@@ -22,3 +26,38 @@ func setupQueries(queries *map[string]string) url.Values {
 
 	return out
 }
+
+// toQueries converts the given options struct into a *map[string]string that
+// can be given to setupQueries, using the json tags on the struct as the query
+// keys. Fields that are omitted when marshalled (eg. `json:"-"` or empty
+// `omitempty` fields) are not included.
+func toQueries(options interface{}) (*map[string]string, error) {
+
+	// marshal options.
+	b, err := json.Marshal(options)
+	if err != nil {
+		return nil, ErrFailedMarshal{err}
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, ErrFailedUnmarshal{err}
+	}
+
+	// convert fields to queries.
+	queries := make(map[string]string)
+	for key, value := range fields {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case bool:
+			if v {
+				queries[key] = "1"
+			} else {
+				queries[key] = "0"
+			}
+		default:
+			queries[key] = fmt.Sprintf("%v", v)
+		}
+	}
+	return &queries, nil
+}
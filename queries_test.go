@@ -46,3 +46,59 @@ func Test_setupQueries(t *testing.T) {
 		})
 	}
 }
+
+func Test_toQueries(t *testing.T) {
+	tests := map[string]struct {
+		options interface{}
+		want    *map[string]string
+	}{
+		"converts fields using json tags": {
+			options: struct {
+				ID        int    `json:"-"`
+				StartDate string `json:"start_date,omitempty"`
+				EndDate   string `json:"end_date,omitempty"`
+				Page      int    `json:"page,omitempty"`
+			}{
+				ID:        1,
+				StartDate: "2024-01-01",
+				Page:      2,
+			},
+			want: &map[string]string{
+				"start_date": "2024-01-01",
+				"page":       "2",
+			},
+		},
+		"converts bools to 1 or 0": {
+			options: struct {
+				NeedsReview   bool `json:"needs_review"`
+				Uncategorised bool `json:"uncategorised"`
+			}{
+				NeedsReview: true,
+			},
+			want: &map[string]string{
+				"needs_review":  "1",
+				"uncategorised": "0",
+			},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			got, err := toQueries(tt.options)
+			if err != nil {
+				t.Errorf("toQueries() returned an error;\nerror=%v\n", err)
+				return
+			}
+
+			// is there a mismatch from what we're expecting vs what we've got?
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf(
+					"toQueries() returned unexpected queries;\nwant=%+v\ngot=%+v\n",
+					tt.want,
+					got,
+				)
+			}
+		})
+	}
+}
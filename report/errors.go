@@ -0,0 +1,44 @@
+package report
+
+import "fmt"
+
+// ErrUnknownPeriod is returned when a report period isn't known.
+type ErrUnknownPeriod struct {
+	period Period
+}
+
+func (e ErrUnknownPeriod) Error() string {
+	return fmt.Sprintf("unknown report period %q", e.period)
+}
+
+// ErrUnknownDimension is returned when a report dimension isn't known.
+type ErrUnknownDimension struct {
+	dimension Dimension
+}
+
+func (e ErrUnknownDimension) Error() string {
+	return fmt.Sprintf("unknown report dimension %q", e.dimension)
+}
+
+// ErrInvalidDate is returned when a transaction, or an option, has an invalid
+// date.
+type ErrInvalidDate struct {
+	id   int32
+	date string
+}
+
+func (e ErrInvalidDate) Error() string {
+	if e.id == 0 {
+		return fmt.Sprintf("invalid date %q", e.date)
+	}
+	return fmt.Sprintf("transaction %v has an invalid date %q", e.id, e.date)
+}
+
+// ErrUnknownInterval is returned when a net worth interval isn't known.
+type ErrUnknownInterval struct {
+	interval Interval
+}
+
+func (e ErrUnknownInterval) Error() string {
+	return fmt.Sprintf("unknown net worth interval %q", e.interval)
+}
@@ -0,0 +1,291 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// Interval defines the time between points in a net worth history.
+type Interval string
+
+// The intervals a net worth history can be built with.
+const (
+	IntervalDaily   Interval = "daily"
+	IntervalMonthly Interval = "monthly"
+)
+
+// NetWorthOptions defines the options for building a net worth history.
+type NetWorthOptions struct {
+	Interval Interval // Defaults to IntervalMonthly.
+
+	// StartDate and EndDate, in the format "2006-01-02", are the range of the
+	// history. They default to the date of the earliest transaction (or the
+	// end date, if there are none) and the latest current balance date of the
+	// accounts.
+	StartDate string
+	EndDate   string
+
+	// BaseCurrency is the currency the balances are converted to, which should
+	// be the User.BaseCurrencyCode.
+	BaseCurrency string
+}
+
+// NetWorthPoint defines the net worth at the end of a single date.
+type NetWorthPoint struct {
+	Date          string             `json:"date"`
+	Total         float64            `json:"total"`
+	Assets        float64            `json:"assets"`         // The sum of the positive balances.
+	Liabilities   float64            `json:"liabilities"`    // The sum of the negative balances.
+	ByType        map[string]float64 `json:"by_type"`        // Keyed by Account.Type.
+	ByInstitution map[string]float64 `json:"by_institution"` // Keyed by Institution.Title.
+}
+
+// NetWorth defines a history of net worth.
+type NetWorth struct {
+	BaseCurrency string          `json:"base_currency"`
+	Points       []NetWorthPoint `json:"points"`
+}
+
+// NetWorthHistory rebuilds the balances of every given account that counts
+// towards net worth, backwards from its current balance, using the given
+// transactions. The balances are converted to the base currency using the
+// AmountInBaseCurrency of each transaction, falling back to the current
+// exchange rate of the transaction account.
+func NetWorthHistory(
+	accounts pocketsmith.Accounts,
+	transactions pocketsmith.Transactions,
+	options *NetWorthOptions,
+) (*NetWorth, error) {
+
+	// default options.
+	o := NetWorthOptions{}
+	if options != nil {
+		o = *options
+	}
+	if o.Interval == "" {
+		o.Interval = IntervalMonthly
+	}
+	if o.Interval != IntervalDaily && o.Interval != IntervalMonthly {
+		return nil, ErrUnknownInterval{interval: o.Interval}
+	}
+
+	// collect the transaction accounts that count towards net worth.
+	type balance struct {
+		accountType string
+		institution string
+		current     float64
+		rate        float64
+		changes     []pocketsmith.Transaction // Sorted newest first.
+	}
+	balances := make(map[int]*balance)
+	var ids []int
+	latest := ""
+	for _, a := range accounts {
+		if !a.IsNetWorth {
+			continue
+		}
+		for _, ta := range a.TransactionAccounts {
+			if _, ok := balances[ta.ID]; ok {
+				continue
+			}
+			b := &balance{
+				accountType: string(a.Type),
+				institution: ta.Institution.Title,
+				current:     ta.CurrentBalanceInBaseCurrency,
+				rate:        ta.CurrentBalanceExchangeRate,
+			}
+			if b.rate == 0 {
+				b.rate = 1
+			}
+			if b.current == 0 {
+				b.current = ta.CurrentBalance * b.rate
+			}
+			if b.institution == "" {
+				b.institution = a.PrimaryTransactionAccount.Institution.Title
+			}
+			balances[ta.ID] = b
+			ids = append(ids, ta.ID)
+			if ta.CurrentBalanceDate > latest {
+				latest = ta.CurrentBalanceDate
+			}
+		}
+	}
+	sort.Ints(ids)
+
+	// assign transactions to their transaction accounts.
+	earliest := ""
+	for _, t := range transactions {
+		b, ok := balances[t.TransactionAccount.ID]
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(dateFormat, t.Date); err != nil {
+			return nil, ErrInvalidDate{id: t.ID, date: t.Date}
+		}
+		b.changes = append(b.changes, t)
+		if earliest == "" || t.Date < earliest {
+			earliest = t.Date
+		}
+		if t.Date > latest {
+			latest = t.Date
+		}
+	}
+	for _, b := range balances {
+		sort.SliceStable(b.changes, func(i, j int) bool {
+			return b.changes[i].Date > b.changes[j].Date
+		})
+	}
+
+	// setup the dates of each point; without any transactions, there's a
+	// single point at the current balances.
+	if o.EndDate == "" {
+		o.EndDate = latest
+	}
+	if o.StartDate == "" {
+		o.StartDate = earliest
+	}
+	if o.StartDate == "" {
+		o.StartDate = o.EndDate
+	}
+	nw := &NetWorth{BaseCurrency: strings.ToUpper(o.BaseCurrency)}
+	if o.EndDate == "" {
+		return nw, nil // no balances to build a history from.
+	}
+	dates, err := o.dates()
+	if err != nil || len(dates) == 0 {
+		return nw, err
+	}
+	nw.Points = make([]NetWorthPoint, len(dates))
+
+	// rebuild balances backwards, from the latest point to the earliest.
+	for _, id := range ids {
+		b := balances[id]
+		running, next := b.current, 0
+		for i := len(dates) - 1; i >= 0; i-- {
+			for ; next < len(b.changes) && b.changes[next].Date > dates[i]; next++ {
+				t := b.changes[next]
+				amount := t.AmountInBaseCurrency
+				if amount == 0 {
+					amount = t.Amount * b.rate
+				}
+				running -= amount
+			}
+			p := &nw.Points[i]
+			if p.ByType == nil {
+				p.Date = dates[i]
+				p.ByType = make(map[string]float64)
+				p.ByInstitution = make(map[string]float64)
+			}
+			p.Total += running
+			if running >= 0 {
+				p.Assets += running
+			} else {
+				p.Liabilities += running
+			}
+			p.ByType[b.accountType] += running
+			p.ByInstitution[b.institution] += running
+		}
+	}
+
+	// round totals.
+	for i := range nw.Points {
+		p := &nw.Points[i]
+		p.Date = dates[i]
+		p.Total, p.Assets, p.Liabilities = round(p.Total), round(p.Assets), round(p.Liabilities)
+		for k, v := range p.ByType {
+			p.ByType[k] = round(v)
+		}
+		for k, v := range p.ByInstitution {
+			p.ByInstitution[k] = round(v)
+		}
+	}
+	return nw, nil
+}
+
+// dates returns the dates of each point in the history; the end of each day,
+// or the end of each month, with the end date as the last point.
+func (o *NetWorthOptions) dates() (dates []string, err error) {
+	start, err := time.Parse(dateFormat, o.StartDate)
+	if err != nil {
+		return nil, ErrInvalidDate{date: o.StartDate}
+	}
+	end, err := time.Parse(dateFormat, o.EndDate)
+	if err != nil {
+		return nil, ErrInvalidDate{date: o.EndDate}
+	}
+	for d := start; !d.After(end); {
+		if o.Interval == IntervalDaily {
+			dates = append(dates, d.Format(dateFormat))
+			d = d.AddDate(0, 0, 1)
+			continue
+		}
+		next := time.Date(d.Year(), d.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		last := next.AddDate(0, 0, -1)
+		if last.After(end) {
+			last = end
+		}
+		dates = append(dates, last.Format(dateFormat))
+		d = next
+	}
+	return dates, nil
+}
+
+// WriteCSV writes the history to the given writer as CSV, with a column for
+// each account type and institution.
+func (nw *NetWorth) WriteCSV(w io.Writer) error {
+	types, institutions := make(map[string]bool), make(map[string]bool)
+	for _, p := range nw.Points {
+		for k := range p.ByType {
+			types[k] = true
+		}
+		for k := range p.ByInstitution {
+			institutions[k] = true
+		}
+	}
+	typeKeys, institutionKeys := sortedKeys(types), sortedKeys(institutions)
+
+	header := []string{"date", "total", "assets", "liabilities"}
+	for _, k := range typeKeys {
+		header = append(header, "type:"+k)
+	}
+	for _, k := range institutionKeys {
+		header = append(header, "institution:"+k)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, p := range nw.Points {
+		row := []string{
+			p.Date,
+			formatAmount(p.Total),
+			formatAmount(p.Assets),
+			formatAmount(p.Liabilities),
+		}
+		for _, k := range typeKeys {
+			row = append(row, formatAmount(p.ByType[k]))
+		}
+		for _, k := range institutionKeys {
+			row = append(row, formatAmount(p.ByInstitution[k]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// sortedKeys returns the keys of the given map, sorted.
+func sortedKeys(m map[string]bool) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_NetWorthHistory(t *testing.T) {
+
+	// setup accounts; the savings account is in USD, at 1.5 NZD to the USD.
+	bank := pocketsmith.Institution{Title: "Bank"}
+	everyday := pocketsmith.TransactionAccount{
+		ID:                 1,
+		CurrencyCode:       "nzd",
+		CurrentBalance:     100,
+		CurrentBalanceDate: "2024-02-10",
+		Institution:        bank,
+	}
+	savings := pocketsmith.TransactionAccount{
+		ID:                           2,
+		CurrencyCode:                 "usd",
+		CurrentBalance:               200,
+		CurrentBalanceInBaseCurrency: 300,
+		CurrentBalanceExchangeRate:   1.5,
+		CurrentBalanceDate:           "2024-02-10",
+		Institution:                  pocketsmith.Institution{Title: "Broker"},
+	}
+	card := pocketsmith.TransactionAccount{ID: 3, CurrentBalance: -50, Institution: bank}
+	accounts := pocketsmith.Accounts{
+		{Type: "bank", IsNetWorth: true, TransactionAccounts: pocketsmith.TransactionAccounts{everyday, savings}},
+		{Type: "credits", IsNetWorth: false, TransactionAccounts: pocketsmith.TransactionAccounts{card}},
+	}
+	transactions := pocketsmith.Transactions{
+		{ID: 1, Date: "2024-01-15", Amount: 40, AmountInBaseCurrency: 40, TransactionAccount: everyday},
+		{ID: 2, Date: "2024-02-05", Amount: -10, AmountInBaseCurrency: -10, TransactionAccount: everyday},
+		{ID: 3, Date: "2024-01-20", Amount: 20, TransactionAccount: savings}, // no base amount; converted at 1.5.
+		{ID: 4, Date: "2024-01-20", Amount: -500, TransactionAccount: card},  // not net worth.
+	}
+
+	got, err := NetWorthHistory(accounts, transactions, &NetWorthOptions{BaseCurrency: "nzd"})
+	if err != nil {
+		t.Fatalf("NetWorthHistory() returned an error: %v", err)
+	}
+	want := &NetWorth{
+		BaseCurrency: "NZD",
+		Points: []NetWorthPoint{
+			{
+				Date:          "2024-01-31",
+				Total:         410,
+				Assets:        410,
+				ByType:        map[string]float64{"bank": 410},
+				ByInstitution: map[string]float64{"Bank": 110, "Broker": 300},
+			},
+			{
+				Date:          "2024-02-10",
+				Total:         400,
+				Assets:        400,
+				ByType:        map[string]float64{"bank": 400},
+				ByInstitution: map[string]float64{"Bank": 100, "Broker": 300},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NetWorthHistory() returned unexpected results;\nwant=%+v\ngot=%+v", want, got)
+	}
+
+	// daily.
+	got, err = NetWorthHistory(accounts, transactions, &NetWorthOptions{
+		Interval:  IntervalDaily,
+		StartDate: "2024-01-19",
+		EndDate:   "2024-01-20",
+	})
+	if err != nil {
+		t.Fatalf("NetWorthHistory() returned an error: %v", err)
+	}
+	if len(got.Points) != 2 || got.Points[0].Total != 380 || got.Points[1].Total != 410 {
+		t.Errorf("NetWorthHistory() returned unexpected daily points; got=%+v", got.Points)
+	}
+
+	// csv.
+	var buf bytes.Buffer
+	if err := got.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() returned an error: %v", err)
+	}
+	wantCSV := strings.Join([]string{
+		"date,total,assets,liabilities,type:bank,institution:Bank,institution:Broker",
+		"2024-01-19,380.00,380.00,0.00,380.00,110.00,270.00",
+		"2024-01-20,410.00,410.00,0.00,410.00,110.00,300.00",
+	}, "\n") + "\n"
+	if buf.String() != wantCSV {
+		t.Errorf("WriteCSV() returned unexpected output;\nwant=%q\ngot=%q", wantCSV, buf.String())
+	}
+}
+
+func Test_NetWorthHistory_noTransactions(t *testing.T) {
+	accounts := pocketsmith.Accounts{
+		{Type: "bank", IsNetWorth: true, TransactionAccounts: pocketsmith.TransactionAccounts{
+			{ID: 1, CurrentBalance: 250, CurrentBalanceDate: "2024-03-05"},
+		}},
+	}
+
+	// run tests.
+	got, err := NetWorthHistory(accounts, nil, nil)
+	if err != nil {
+		t.Fatalf("NetWorthHistory() returned an error: %v", err)
+	}
+	want := []NetWorthPoint{{
+		Date:          "2024-03-05",
+		Total:         250,
+		Assets:        250,
+		ByType:        map[string]float64{"bank": 250},
+		ByInstitution: map[string]float64{"": 250},
+	}}
+	if !reflect.DeepEqual(got.Points, want) {
+		t.Errorf("NetWorthHistory() returned unexpected points;\nwant=%+v\ngot=%+v", want, got.Points)
+	}
+
+	// no accounts.
+	got, err = NetWorthHistory(nil, nil, nil)
+	if err != nil || len(got.Points) != 0 {
+		t.Errorf("NetWorthHistory() returned unexpected results for no accounts; got=%+v, err=%v", got, err)
+	}
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// table returns the report as a header and rows of cells, with the net
+// totals as the last row.
+func (r *Report) table() (header []string, rows [][]string) {
+	header = append(header, string(r.Dimension))
+	for _, p := range r.Periods {
+		header = append(header, p.Label)
+	}
+	header = append(header, "total")
+
+	for _, row := range r.Rows {
+		name := row.Name
+		if row.Path != "" {
+			name = row.Path
+		}
+		rows = append(rows, cells(name, row.Amounts, row.Total))
+	}
+	total := 0.0
+	for _, n := range r.Net {
+		total += n
+	}
+	rows = append(rows, cells("Total", r.Net, round(total)))
+	return header, rows
+}
+
+// cells returns the cells of a single row.
+func cells(name string, amounts []float64, total float64) []string {
+	out := []string{name}
+	for _, a := range amounts {
+		out = append(out, formatAmount(a))
+	}
+	return append(out, formatAmount(total))
+}
+
+// WriteCSV writes the report to the given writer as CSV.
+func (r *Report) WriteCSV(w io.Writer) error {
+	header, rows := r.table()
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteMarkdown writes the report to the given writer as a markdown table.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	header, rows := r.table()
+	align := []string{"---"}
+	for range header[1:] {
+		align = append(align, "---:")
+	}
+	lines := []string{markdownRow(header), markdownRow(align)}
+	for _, row := range rows {
+		lines = append(lines, markdownRow(row))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// markdownRow returns the given cells as a markdown table row.
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, c := range cells {
+		escaped[i] = strings.ReplaceAll(c, "|", `\|`)
+	}
+	return "| " + strings.Join(escaped, " | ") + " |"
+}
+
+// formatAmount formats the given amount with two decimal places.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,342 @@
+// Package report rolls up a user's PocketSmith transactions into spending and
+// income reports, by category, label, payee or transaction account, over
+// weekly, monthly, quarterly or yearly periods. It also rebuilds the history
+// of a user's net worth, which the API only gives current balances for.
+//
+// Reports are built from transactions that have already been listed (eg. via
+// ListTransactionsForUser, or a sync.Store), and can be rendered as CSV or
+// markdown tables.
+package report
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// The format of the dates returned from the API.
+const dateFormat = "2006-01-02"
+
+// Period defines the length of each column in a report.
+type Period string
+
+// The periods a report can be split into.
+const (
+	PeriodWeekly    Period = "weekly"
+	PeriodMonthly   Period = "monthly"
+	PeriodQuarterly Period = "quarterly"
+	PeriodYearly    Period = "yearly"
+)
+
+// Dimension defines what the rows of a report are grouped by.
+type Dimension string
+
+// The dimensions a report can be grouped by.
+const (
+	DimensionCategory           Dimension = "category"
+	DimensionLabel              Dimension = "label"
+	DimensionPayee              Dimension = "payee"
+	DimensionTransactionAccount Dimension = "transaction_account"
+)
+
+// Options defines the options for building a report.
+type Options struct {
+	Period    Period    // Defaults to PeriodMonthly.
+	Dimension Dimension // Defaults to DimensionCategory.
+
+	// WeekStartDay is the day weekly periods start on, from 0 (Sunday) to 6
+	// (Saturday), the same as User.WeekStartDay.
+	WeekStartDay int
+
+	// Location is the time zone the periods are in. Defaults to UTC.
+	Location *time.Location
+
+	// Categories is the category tree of the user. When set, category reports
+	// roll up the amounts of child categories into their parents.
+	Categories pocketsmith.Categories
+
+	// StartDate and EndDate, in the format "2006-01-02", limit the
+	// transactions included in the report. Both are inclusive and optional.
+	StartDate string
+	EndDate   string
+
+	BaseCurrency     bool // Use AmountInBaseCurrency, rather than Amount.
+	ExcludeTransfers bool // Skip transfers, so they don't count as income or spending.
+}
+
+// OptionsForUser returns options for the given period and dimension that use
+// the week start day and time zone of the given user.
+func OptionsForUser(user *pocketsmith.User, period Period, dimension Dimension) *Options {
+	return &Options{
+		Period:       period,
+		Dimension:    dimension,
+		WeekStartDay: user.WeekStartDay,
+		Location:     loadLocation(user.TimeZone, user.UTCOffset),
+		BaseCurrency: user.UsingMultipleCurrencies,
+	}
+}
+
+// Range defines a single period in a report.
+type Range struct {
+	Label string    `json:"label"`
+	Start time.Time `json:"start"` // Inclusive.
+	End   time.Time `json:"end"`   // Exclusive.
+}
+
+// Row defines a single row in a report.
+type Row struct {
+	Key  string `json:"key"`  // The ID of the category or transaction account, the label, or the payee.
+	Name string `json:"name"` // The display name of the row.
+
+	// Path and Depth are the position of a category in the category tree, eg.
+	// "Food/Groceries" at depth 1. They're only set for category reports.
+	Path  string `json:"path,omitempty"`
+	Depth int    `json:"depth,omitempty"`
+
+	Amounts []float64 `json:"amounts"` // The net amount for each period.
+	Total   float64   `json:"total"`
+	Count   int       `json:"count"` // The number of transactions in the row.
+}
+
+// Report defines a rolled up report of transactions.
+type Report struct {
+	Period    Period    `json:"period"`
+	Dimension Dimension `json:"dimension"`
+	Periods   []Range   `json:"periods"`
+	Rows      []Row     `json:"rows"`
+
+	// Income, Spending and Net are the totals for each period. They're
+	// calculated from the transactions, rather than the rows, since a
+	// transaction with many labels appears in many rows.
+	Income   []float64 `json:"income"`
+	Spending []float64 `json:"spending"`
+	Net      []float64 `json:"net"`
+}
+
+// Build rolls up the given transactions into a report.
+func Build(transactions pocketsmith.Transactions, options *Options) (*Report, error) {
+
+	// default options.
+	o := Options{}
+	if options != nil {
+		o = *options
+	}
+	if o.Period == "" {
+		o.Period = PeriodMonthly
+	}
+	if o.Dimension == "" {
+		o.Dimension = DimensionCategory
+	}
+	if o.Location == nil {
+		o.Location = time.UTC
+	}
+	switch o.Period {
+	case PeriodWeekly, PeriodMonthly, PeriodQuarterly, PeriodYearly:
+	default:
+		return nil, ErrUnknownPeriod{period: o.Period}
+	}
+	switch o.Dimension {
+	case DimensionCategory, DimensionLabel, DimensionPayee, DimensionTransactionAccount:
+	default:
+		return nil, ErrUnknownDimension{dimension: o.Dimension}
+	}
+
+	// filter transactions.
+	var included pocketsmith.Transactions
+	var dates []time.Time
+	for _, t := range transactions {
+		if o.StartDate != "" && t.Date < o.StartDate {
+			continue
+		}
+		if o.EndDate != "" && t.Date > o.EndDate {
+			continue
+		}
+		if o.ExcludeTransfers && (t.IsTransfer || t.Category.IsTransfer) {
+			continue
+		}
+		date, err := time.ParseInLocation(dateFormat, t.Date, o.Location)
+		if err != nil {
+			return nil, ErrInvalidDate{id: t.ID, date: t.Date}
+		}
+		included = append(included, t)
+		dates = append(dates, date)
+	}
+
+	// setup periods, from the first to the last transaction.
+	r := &Report{Period: o.Period, Dimension: o.Dimension}
+	if len(included) == 0 {
+		return r, nil
+	}
+	first, last := dates[0], dates[0]
+	for _, d := range dates {
+		if d.Before(first) {
+			first = d
+		}
+		if d.After(last) {
+			last = d
+		}
+	}
+	for start := o.periodStart(first); !start.After(last); start = o.nextPeriod(start) {
+		r.Periods = append(r.Periods, Range{
+			Label: o.periodLabel(start),
+			Start: start,
+			End:   o.nextPeriod(start),
+		})
+	}
+	r.Income = make([]float64, len(r.Periods))
+	r.Spending = make([]float64, len(r.Periods))
+	r.Net = make([]float64, len(r.Periods))
+
+	// roll up transactions.
+	rows := make(map[string]*Row)
+	var keys []string
+	add := func(key, name string, i int, amount float64) {
+		row, ok := rows[key]
+		if !ok {
+			row = &Row{Key: key, Name: name, Amounts: make([]float64, len(r.Periods))}
+			rows[key] = row
+			keys = append(keys, key)
+		}
+		row.Amounts[i] += amount
+		row.Total += amount
+		row.Count++
+	}
+	tree := pocketsmith.NewCategoryTree(o.Categories)
+	for n, t := range included {
+		i := r.index(dates[n])
+		amount := t.Amount
+		if o.BaseCurrency {
+			amount = t.AmountInBaseCurrency
+		}
+		if amount > 0 {
+			r.Income[i] += amount
+		} else {
+			r.Spending[i] += amount
+		}
+		r.Net[i] += amount
+
+		switch o.Dimension {
+		case DimensionCategory:
+			if t.Category.ID == 0 {
+				add("", "Uncategorised", i, amount)
+				continue
+			}
+			add(categoryKey(t.Category.ID), t.Category.Title, i, amount)
+			for _, parent := range tree.Ancestors(t.Category.ID) {
+				add(categoryKey(parent.ID), parent.Title, i, amount)
+			}
+		case DimensionLabel:
+			if len(t.Labels) == 0 {
+				add("", "Unlabelled", i, amount)
+			}
+			for _, label := range t.Labels {
+				add(label, label, i, amount)
+			}
+		case DimensionPayee:
+			add(t.Payee, t.Payee, i, amount)
+		case DimensionTransactionAccount:
+			add(
+				strconv.Itoa(t.TransactionAccount.ID),
+				t.TransactionAccount.Name,
+				i,
+				amount,
+			)
+		}
+	}
+
+	// order rows; categories follow the tree, everything else is by name.
+	if o.Dimension == DimensionCategory {
+		for _, c := range tree.Flatten() {
+			row, ok := rows[categoryKey(c.ID)]
+			if !ok {
+				continue
+			}
+			row.Path, row.Depth = c.Path, c.Depth
+			r.Rows = append(r.Rows, *row)
+			delete(rows, row.Key)
+		}
+	}
+	var remaining []Row
+	for _, key := range keys {
+		if row, ok := rows[key]; ok {
+			remaining = append(remaining, *row)
+		}
+	}
+	sort.SliceStable(remaining, func(a, b int) bool {
+		return strings.ToLower(remaining[a].Name) < strings.ToLower(remaining[b].Name)
+	})
+	r.Rows = append(r.Rows, remaining...)
+	for i := range r.Rows {
+		r.Rows[i].Total = round(r.Rows[i].Total)
+		for j := range r.Rows[i].Amounts {
+			r.Rows[i].Amounts[j] = round(r.Rows[i].Amounts[j])
+		}
+	}
+	for i := range r.Periods {
+		r.Income[i], r.Spending[i], r.Net[i] = round(r.Income[i]), round(r.Spending[i]), round(r.Net[i])
+	}
+	return r, nil
+}
+
+// index returns the index of the period the given date is in.
+func (r *Report) index(date time.Time) int {
+	return sort.Search(len(r.Periods), func(i int) bool {
+		return date.Before(r.Periods[i].End)
+	})
+}
+
+// periodStart returns the start of the period the given date is in.
+func (o *Options) periodStart(d time.Time) time.Time {
+	year, month, day := d.Date()
+	switch o.Period {
+	case PeriodWeekly:
+		offset := (int(d.Weekday()) - o.WeekStartDay%7 + 7) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, o.Location)
+	case PeriodQuarterly:
+		return time.Date(year, (month-1)/3*3+1, 1, 0, 0, 0, 0, o.Location)
+	case PeriodYearly:
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, o.Location)
+	}
+	return time.Date(year, month, 1, 0, 0, 0, 0, o.Location)
+}
+
+// nextPeriod returns the start of the period after the given period start.
+func (o *Options) nextPeriod(start time.Time) time.Time {
+	switch o.Period {
+	case PeriodWeekly:
+		return start.AddDate(0, 0, 7)
+	case PeriodQuarterly:
+		return start.AddDate(0, 3, 0)
+	case PeriodYearly:
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// periodLabel returns the label of the period starting at the given time.
+func (o *Options) periodLabel(start time.Time) string {
+	switch o.Period {
+	case PeriodWeekly:
+		return start.Format(dateFormat)
+	case PeriodQuarterly:
+		return start.Format("2006") + "-Q" + strconv.Itoa(int(start.Month()-1)/3+1)
+	case PeriodYearly:
+		return start.Format("2006")
+	}
+	return start.Format("2006-01")
+}
+
+// categoryKey returns the row key for the given category ID.
+func categoryKey(id int32) string {
+	return strconv.Itoa(int(id))
+}
+
+// round rounds the given amount to cents, to avoid floating point drift when
+// summing many amounts.
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+func Test_Build(t *testing.T) {
+
+	// setup categories; "Groceries" is a child of "Food", which also has a
+	// nil child.
+	parentID := 1
+	categories := pocketsmith.Categories{
+		{
+			ID:    1,
+			Title: "Food",
+			Children: []*pocketsmith.Category{
+				{ID: 2, Title: "Groceries", ParentID: &parentID},
+				nil,
+			},
+		},
+		{ID: 3, Title: "Salary"},
+	}
+	food := pocketsmith.Category{ID: 1, Title: "Food"}
+	groceries := pocketsmith.Category{ID: 2, Title: "Groceries", ParentID: &parentID}
+	salary := pocketsmith.Category{ID: 3, Title: "Salary"}
+
+	// setup transactions; 2024-01-07 is a Sunday.
+	transactions := pocketsmith.Transactions{
+		{ID: 1, Date: "2024-01-01", Amount: 1000, Payee: "Work", Category: salary},
+		{ID: 2, Date: "2024-01-06", Amount: -20, Payee: "Cafe", Category: food, Labels: []string{"work"}},
+		{ID: 3, Date: "2024-01-07", Amount: -50.5, Payee: "Market", Category: groceries},
+		{ID: 4, Date: "2024-02-01", Amount: -9.5, Payee: "Cafe", Labels: []string{"work", "coffee"}},
+	}
+
+	tests := map[string]struct {
+		options *Options
+		periods []string
+		rows    []Row
+		net     []float64
+		err     string
+	}{
+		"monthly by category": {
+			options: &Options{Categories: categories},
+			periods: []string{"2024-01", "2024-02"},
+			rows: []Row{
+				{Key: "1", Name: "Food", Path: "Food", Amounts: []float64{-70.5, 0}, Total: -70.5, Count: 2},
+				{Key: "2", Name: "Groceries", Path: "Food/Groceries", Depth: 1, Amounts: []float64{-50.5, 0}, Total: -50.5, Count: 1},
+				{Key: "3", Name: "Salary", Path: "Salary", Amounts: []float64{1000, 0}, Total: 1000, Count: 1},
+				{Key: "", Name: "Uncategorised", Amounts: []float64{0, -9.5}, Total: -9.5, Count: 1},
+			},
+			net: []float64{929.5, -9.5},
+		},
+		"weekly by label, starting on monday": {
+			options: &Options{Period: PeriodWeekly, Dimension: DimensionLabel, WeekStartDay: 1, EndDate: "2024-01-31"},
+			periods: []string{"2024-01-01"},
+			rows: []Row{
+				{Key: "", Name: "Unlabelled", Amounts: []float64{949.5}, Total: 949.5, Count: 2},
+				{Key: "work", Name: "work", Amounts: []float64{-20}, Total: -20, Count: 1},
+			},
+			net: []float64{929.5},
+		},
+		"weekly by payee, starting on sunday": {
+			options: &Options{Period: PeriodWeekly, Dimension: DimensionPayee, EndDate: "2024-01-31"},
+			periods: []string{"2023-12-31", "2024-01-07"},
+			rows: []Row{
+				{Key: "Cafe", Name: "Cafe", Amounts: []float64{-20, 0}, Total: -20, Count: 1},
+				{Key: "Market", Name: "Market", Amounts: []float64{0, -50.5}, Total: -50.5, Count: 1},
+				{Key: "Work", Name: "Work", Amounts: []float64{1000, 0}, Total: 1000, Count: 1},
+			},
+			net: []float64{980, -50.5},
+		},
+		"unknown period": {
+			options: &Options{Period: "daily"},
+			err:     `unknown report period "daily"`,
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			got, err := Build(transactions, tt.options)
+			if tt.err != "" || err != nil {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Build() returned an unexpected error; want=%v, got=%v", tt.err, err)
+				}
+				return
+			}
+			var periods []string
+			for _, p := range got.Periods {
+				periods = append(periods, p.Label)
+			}
+			if !reflect.DeepEqual(periods, tt.periods) {
+				t.Errorf("Build() returned unexpected periods; want=%v, got=%v", tt.periods, periods)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.rows) {
+				t.Errorf("Build() returned unexpected rows;\nwant=%+v\ngot=%+v", tt.rows, got.Rows)
+			}
+			if !reflect.DeepEqual(got.Net, tt.net) {
+				t.Errorf("Build() returned unexpected net totals; want=%v, got=%v", tt.net, got.Net)
+			}
+		})
+	}
+}
+
+func Test_Render(t *testing.T) {
+	r, err := Build(pocketsmith.Transactions{
+		{ID: 1, Date: "2024-01-01", Amount: -4.5, Payee: "Coffee | Co"},
+		{ID: 2, Date: "2024-04-01", Amount: 100, Payee: "Salary"},
+	}, &Options{Period: PeriodQuarterly, Dimension: DimensionPayee})
+	if err != nil {
+		t.Fatalf("Build() returned an error: %v", err)
+	}
+
+	var csv bytes.Buffer
+	if err := r.WriteCSV(&csv); err != nil {
+		t.Fatalf("WriteCSV() returned an error: %v", err)
+	}
+	want := strings.Join([]string{
+		"payee,2024-Q1,2024-Q2,total",
+		"Coffee | Co,-4.50,0.00,-4.50",
+		"Salary,0.00,100.00,100.00",
+		"Total,-4.50,100.00,95.50",
+	}, "\n") + "\n"
+	if csv.String() != want {
+		t.Errorf("WriteCSV() returned unexpected output;\nwant=%q\ngot=%q", want, csv.String())
+	}
+
+	var md bytes.Buffer
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatalf("WriteMarkdown() returned an error: %v", err)
+	}
+	want = strings.Join([]string{
+		"| payee | 2024-Q1 | 2024-Q2 | total |",
+		"| --- | ---: | ---: | ---: |",
+		`| Coffee \| Co | -4.50 | 0.00 | -4.50 |`,
+		"| Salary | 0.00 | 100.00 | 100.00 |",
+		"| Total | -4.50 | 100.00 | 95.50 |",
+	}, "\n") + "\n"
+	if md.String() != want {
+		t.Errorf("WriteMarkdown() returned unexpected output;\nwant=%q\ngot=%q", want, md.String())
+	}
+}
+
+func Test_OptionsForUser(t *testing.T) {
+	tests := map[string]struct {
+		user       *pocketsmith.User
+		wantZone   string
+		wantOffset int // In seconds, on 2024-01-01.
+	}{
+		"rails name": {
+			user:       &pocketsmith.User{TimeZone: "Auckland", UTCOffset: 780},
+			wantZone:   "Pacific/Auckland",
+			wantOffset: 13 * 60 * 60,
+		},
+		"iana name": {
+			user:       &pocketsmith.User{TimeZone: "Asia/Kolkata", UTCOffset: 330},
+			wantZone:   "Asia/Kolkata",
+			wantOffset: 330 * 60,
+		},
+		"unknown, so the utc offset": {
+			user:       &pocketsmith.User{TimeZone: "Nowhere", UTCOffset: -210},
+			wantZone:   "Nowhere",
+			wantOffset: -210 * 60,
+		},
+		"none": {
+			user:     &pocketsmith.User{},
+			wantZone: "UTC",
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := OptionsForUser(tt.user, PeriodWeekly, DimensionPayee)
+			_, offset := time.Date(2024, 1, 1, 0, 0, 0, 0, o.Location).Zone()
+			if o.Location.String() != tt.wantZone || offset != tt.wantOffset {
+				t.Errorf("OptionsForUser() returned an unexpected location;\nwant=%v %v\ngot=%v %v",
+					tt.wantZone, tt.wantOffset, o.Location, offset)
+			}
+		})
+	}
+}
+
+func Test_zones(t *testing.T) {
+	for name, zone := range zones {
+		if _, err := time.LoadLocation(zone); err != nil {
+			t.Errorf("failed to load the location of %q: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package rules
+
+import (
+	"context"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/internal/update"
+)
+
+// The name of the tracer output in the traces.
+const tracerName = "pocketsmith-go/rules"
+
+// API defines the parts of the *pocketsmith.Client used to apply changes.
+type API interface {
+	UpdateTransaction(
+		ctx context.Context,
+		options *pocketsmith.UpdateTransactionOptions,
+	) (*pocketsmith.Transaction, error)
+}
+
+// Result defines the outcome of applying a change.
+type Result struct {
+	Change      Change                   // The change that was applied.
+	Transaction *pocketsmith.Transaction // The updated transaction, if successful.
+	Skipped     bool                     // If the change had nothing the API can apply, so wasn't sent.
+	Err         error                    // The error returned when applying the change, if any.
+}
+
+// Apply applies the given changes through UpdateTransaction. A change that
+// fails to apply doesn't stop the others; its error is recorded in its result.
+//
+// NOTE: the API can't remove every label from a transaction, so removing the
+// last label is left in Change.Skipped; a change with nothing else to apply
+// isn't sent, and its result is marked as skipped.
+func Apply(ctx context.Context, api API, changes []Change) (results []Result) {
+	options := make([]*pocketsmith.UpdateTransactionOptions, len(changes))
+	for i, c := range changes {
+		if len(c.Fields) > 0 {
+			options[i] = c.Options
+		}
+	}
+	for i, o := range update.Apply(ctx, tracerName, api, options) {
+		results = append(results, Result{
+			Change:      changes[i],
+			Transaction: o.Transaction,
+			Skipped:     o.Skipped,
+			Err:         o.Err,
+		})
+	}
+	return results
+}
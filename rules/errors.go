@@ -0,0 +1,15 @@
+package rules
+
+import (
+	"fmt"
+)
+
+// ErrRuleInvalid is returned when a rule is invalid.
+type ErrRuleInvalid struct {
+	rule string
+	err  error
+}
+
+func (e ErrRuleInvalid) Error() string {
+	return fmt.Sprintf("invalid rule %q: %v", e.rule, e.err)
+}
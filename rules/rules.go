@@ -0,0 +1,354 @@
+// Package rules categorises transactions locally, using ordered rules that
+// can match on more than just the payee.
+//
+// Rules are evaluated against transactions to produce a set of changes (a
+// dry-run diff), which can then be applied through UpdateTransaction:
+//
+//	e, err := rules.New(rs...)
+//	changes := e.Evaluate(transactions)
+//	for _, c := range changes {
+//		fmt.Println(c)
+//	}
+//	results := rules.Apply(ctx, c, changes)
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// Text defines how a text field of a transaction is matched. If both Contains
+// and Regex are given, both must match.
+type Text struct {
+	Contains string `json:"contains,omitempty" yaml:"contains,omitempty"` // Matches if the field contains this, ignoring case.
+	Regex    string `json:"regex,omitempty"    yaml:"regex,omitempty"`    // Matches if the field matches this regular expression.
+
+	rgx *regexp.Regexp
+}
+
+// match returns if the given value matches.
+func (t *Text) match(value string) bool {
+	if t.Contains != "" && !strings.Contains(strings.ToLower(value), strings.ToLower(t.Contains)) {
+		return false
+	}
+	if t.rgx != nil && !t.rgx.MatchString(value) {
+		return false
+	}
+	return true
+}
+
+// Amount defines the range that the amount of a transaction must be within.
+// Either bound can be omitted.
+type Amount struct {
+	Min *float64 `json:"min,omitempty" yaml:"min,omitempty"` // The inclusive lower bound.
+	Max *float64 `json:"max,omitempty" yaml:"max,omitempty"` // The inclusive upper bound.
+
+	// Absolute compares the bounds against the absolute amount, so that
+	// "Amazon under $20" can be written as Max: 20, rather than Min: -20.
+	Absolute bool `json:"absolute,omitempty" yaml:"absolute,omitempty"`
+}
+
+// match returns if the given amount is within the range.
+func (a *Amount) match(amount float64) bool {
+	if a.Absolute && amount < 0 {
+		amount = -amount
+	}
+	if a.Min != nil && amount < *a.Min {
+		return false
+	}
+	if a.Max != nil && amount > *a.Max {
+		return false
+	}
+	return true
+}
+
+// Match defines the conditions a transaction must meet to match a rule. Every
+// given condition must be met.
+type Match struct {
+	Payee                 *Text    `json:"payee,omitempty"                   yaml:"payee,omitempty"`
+	OriginalPayee         *Text    `json:"original_payee,omitempty"          yaml:"original_payee,omitempty"`
+	Amount                *Amount  `json:"amount,omitempty"                  yaml:"amount,omitempty"`
+	Debit                 bool     `json:"debit,omitempty"                   yaml:"debit,omitempty"`  // Only match debits (negative amounts).
+	Credit                bool     `json:"credit,omitempty"                  yaml:"credit,omitempty"` // Only match credits (positive amounts).
+	TransactionAccountIDs []int    `json:"transaction_account_ids,omitempty" yaml:"transaction_account_ids,omitempty"`
+	Labels                []string `json:"labels,omitempty"                  yaml:"labels,omitempty"`        // Every label must be on the transaction.
+	Uncategorised         bool     `json:"uncategorised,omitempty"           yaml:"uncategorised,omitempty"` // Only match transactions without a category.
+}
+
+// Actions defines the changes made to a transaction that matches a rule.
+type Actions struct {
+	CategoryID   int32    `json:"category_id,omitempty"   yaml:"category_id,omitempty"`
+	AddLabels    []string `json:"add_labels,omitempty"    yaml:"add_labels,omitempty"`
+	RemoveLabels []string `json:"remove_labels,omitempty" yaml:"remove_labels,omitempty"`
+	Note         string   `json:"note,omitempty"          yaml:"note,omitempty"`
+	NeedsReview  *bool    `json:"needs_review,omitempty"  yaml:"needs_review,omitempty"`
+}
+
+// Rule defines a rule; when a transaction matches, the actions are applied.
+type Rule struct {
+	Name    string  `json:"name"    yaml:"name"`
+	Match   Match   `json:"match"   yaml:"match"`
+	Actions Actions `json:"actions" yaml:"actions"`
+
+	// Continue lets the rules after this one also be applied when it
+	// matches. By default, the first matching rule wins.
+	Continue bool `json:"continue,omitempty" yaml:"continue,omitempty"`
+}
+
+// compile validates the rule and compiles its regular expressions.
+func (r *Rule) compile() error {
+	for _, t := range []*Text{r.Match.Payee, r.Match.OriginalPayee} {
+		if t == nil || t.Regex == "" {
+			continue
+		}
+		rgx, err := regexp.Compile(t.Regex)
+		if err != nil {
+			return ErrRuleInvalid{r.Name, err}
+		}
+		t.rgx = rgx
+	}
+	if r.Match.Debit && r.Match.Credit {
+		return ErrRuleInvalid{r.Name, fmt.Errorf("can't match both debits and credits")}
+	}
+	a := r.Actions
+	if a.CategoryID == 0 && len(a.AddLabels) == 0 && len(a.RemoveLabels) == 0 &&
+		a.Note == "" && a.NeedsReview == nil {
+		return ErrRuleInvalid{r.Name, fmt.Errorf("no actions given")}
+	}
+	return nil
+}
+
+// Matches returns if the given transaction meets every condition of the rule.
+func (r *Rule) Matches(t pocketsmith.Transaction) bool {
+	m := r.Match
+	switch {
+	case m.Payee != nil && !m.Payee.match(t.Payee),
+		m.OriginalPayee != nil && !m.OriginalPayee.match(t.OriginalPayee),
+		m.Amount != nil && !m.Amount.match(t.Amount),
+		m.Debit && t.Amount >= 0,
+		m.Credit && t.Amount <= 0,
+		m.Uncategorised && t.Category.ID != 0:
+		return false
+	}
+	if len(m.TransactionAccountIDs) > 0 {
+		found := false
+		for _, id := range m.TransactionAccountIDs {
+			if id == t.TransactionAccount.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, label := range m.Labels {
+		if !hasLabel(t.Labels, label) {
+			return false
+		}
+	}
+	return true
+}
+
+// Engine evaluates an ordered list of rules against transactions.
+type Engine struct {
+	rules      []Rule
+	categories map[int32]string // The titles of the categories, by id; used in diffs.
+}
+
+// New returns a new Engine for the given rules, in the order they are given.
+// An error is returned if any rule is invalid.
+func New(rules ...Rule) (*Engine, error) {
+	e := &Engine{categories: make(map[int32]string)}
+	for _, r := range rules {
+		if err := r.compile(); err != nil {
+			return nil, err
+		}
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+// WithCategories gives the engine the categories of the user, so that diffs
+// show category titles rather than ids.
+func (e *Engine) WithCategories(categories pocketsmith.Categories) *Engine {
+	for _, c := range pocketsmith.NewCategoryTree(categories).Flatten() {
+		e.categories[c.ID] = c.Title
+	}
+	return e
+}
+
+// FieldChange defines a change to a single field of a transaction.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Change defines the changes the rules make to a transaction.
+type Change struct {
+	Transaction pocketsmith.Transaction               `json:"transaction"`
+	Rules       []string                              `json:"rules"` // The names of the rules that matched.
+	Fields      []FieldChange                         `json:"fields"`
+	Skipped     []FieldChange                         `json:"skipped,omitempty"` // The changes the API can't make, eg. removing the last label.
+	Options     *pocketsmith.UpdateTransactionOptions `json:"-"`                 // The options used to apply the change.
+}
+
+// String returns the change as a single line diff, eg.
+// "transaction 123 (Amazon, -12.00): category Food→Household".
+func (c Change) String() string {
+	var fields []string
+	for _, f := range c.Fields {
+		fields = append(fields, fmt.Sprintf("%s %s→%s", f.Field, quoteEmpty(f.From), quoteEmpty(f.To)))
+	}
+	for _, f := range c.Skipped {
+		fields = append(fields, fmt.Sprintf("%s %s→%s (skipped)", f.Field, quoteEmpty(f.From), quoteEmpty(f.To)))
+	}
+	return fmt.Sprintf(
+		"transaction %v (%s, %.2f): %s [%s]",
+		c.Transaction.ID,
+		c.Transaction.Payee,
+		c.Transaction.Amount,
+		strings.Join(fields, ", "),
+		strings.Join(c.Rules, ", "),
+	)
+}
+
+// Evaluate runs the rules over the given transactions, returning the changes
+// they would make. Transactions that no rule matches, or that already match
+// what the rules would set, are not returned. Nothing is updated.
+func (e *Engine) Evaluate(transactions pocketsmith.Transactions) (changes []Change) {
+	for _, t := range transactions {
+		if c, ok := e.evaluate(t); ok {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// evaluate runs the rules over the given transaction.
+func (e *Engine) evaluate(t pocketsmith.Transaction) (Change, bool) {
+
+	// determine the final state of the transaction.
+	category := t.Category.ID
+	labels := append([]string(nil), t.Labels...)
+	note := t.Note
+	needsReview := t.NeedsReview
+	var matched []string
+	for i := range e.rules {
+		r := &e.rules[i]
+		if !r.Matches(t) {
+			continue
+		}
+		matched = append(matched, r.Name)
+		a := r.Actions
+		if a.CategoryID != 0 {
+			category = a.CategoryID
+		}
+		for _, l := range a.RemoveLabels {
+			labels = removeLabel(labels, l)
+		}
+		for _, l := range a.AddLabels {
+			if !hasLabel(labels, l) {
+				labels = append(labels, l)
+			}
+		}
+		if a.Note != "" {
+			note = a.Note
+		}
+		if a.NeedsReview != nil {
+			needsReview = *a.NeedsReview
+		}
+		if !r.Continue {
+			break
+		}
+	}
+	if len(matched) == 0 {
+		return Change{}, false
+	}
+
+	// diff against the current state of the transaction.
+	c := Change{
+		Transaction: t,
+		Rules:       matched,
+		Options:     &pocketsmith.UpdateTransactionOptions{TransactionID: t.ID},
+	}
+	if category != t.Category.ID {
+		c.Fields = append(c.Fields, FieldChange{
+			"category", e.categoryTitle(t.Category.ID, t.Category.Title), e.categoryTitle(category, ""),
+		})
+		c.Options.CategoryID = category
+	}
+	if strings.Join(labels, ",") != strings.Join(t.Labels, ",") {
+		f := FieldChange{"labels", strings.Join(t.Labels, ","), strings.Join(labels, ",")}
+		if len(labels) == 0 {
+
+			// the API ignores empty labels, so the last label can't be removed.
+			c.Skipped = append(c.Skipped, f)
+		} else {
+			c.Fields = append(c.Fields, f)
+			c.Options.Labels = strings.Join(labels, ",")
+		}
+	}
+	if note != t.Note {
+		c.Fields = append(c.Fields, FieldChange{"note", t.Note, note})
+		c.Options.Note = note
+	}
+	if needsReview != t.NeedsReview {
+		c.Fields = append(c.Fields, FieldChange{
+			"needs_review", fmt.Sprint(t.NeedsReview), fmt.Sprint(needsReview),
+		})
+		c.Options.NeedsReview = &needsReview
+	}
+	if len(c.Fields) == 0 && len(c.Skipped) == 0 {
+		return Change{}, false
+	}
+	return c, true
+}
+
+// categoryTitle returns the title of the category with the given id.
+func (e *Engine) categoryTitle(id int32, fallback string) string {
+	switch {
+	case id == 0:
+		return ""
+	case e.categories[id] != "":
+		return e.categories[id]
+	case fallback != "":
+		return fallback
+	}
+	return fmt.Sprintf("#%v", id)
+}
+
+// hasLabel returns if the given labels contain the given label, ignoring
+// case.
+func hasLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeLabel returns the given labels without the given label, ignoring
+// case.
+func removeLabel(labels []string, label string) []string {
+	out := labels[:0]
+	for _, l := range labels {
+		if !strings.EqualFold(l, label) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// quoteEmpty returns the given value, or "∅" if it is empty.
+func quoteEmpty(value string) string {
+	if value == "" {
+		return "∅"
+	}
+	return value
+}
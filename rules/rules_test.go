@@ -0,0 +1,193 @@
+package rules
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// float returns a pointer to the given float.
+func float(f float64) *float64 {
+	return &f
+}
+
+func Test_Evaluate(t *testing.T) {
+	yes, no := true, false
+
+	// setup rules.
+	rs := []Rule{
+		{
+			Name:    "amazon under $20 is household",
+			Match:   Match{Payee: &Text{Contains: "amazon"}, Amount: &Amount{Max: float(20), Absolute: true}, Debit: true},
+			Actions: Actions{CategoryID: 2, NeedsReview: &no},
+		},
+		{
+			Name:     "tag card purchases",
+			Match:    Match{OriginalPayee: &Text{Regex: `^SQ \*`}},
+			Actions:  Actions{AddLabels: []string{"square"}},
+			Continue: true,
+		},
+		{
+			Name:    "coffee",
+			Match:   Match{Payee: &Text{Contains: "coffee"}, TransactionAccountIDs: []int{1}},
+			Actions: Actions{CategoryID: 3, Note: "caffeine", RemoveLabels: []string{"todo"}},
+		},
+		{
+			Name:    "flag large work expenses",
+			Match:   Match{Labels: []string{"work"}, Amount: &Amount{Max: float(-500)}},
+			Actions: Actions{NeedsReview: &yes},
+		},
+	}
+	e, err := New(rs...)
+	if err != nil {
+		t.Fatalf("New() returned an error; error=%v", err)
+	}
+	e.WithCategories(pocketsmith.Categories{
+		{ID: 1, Title: "Food", Children: []*pocketsmith.Category{{ID: 3, Title: "Coffee"}}},
+		{ID: 2, Title: "Household"},
+	})
+
+	tests := map[string]struct {
+		transaction pocketsmith.Transaction
+		want        []FieldChange
+	}{
+		"amazon under $20": {
+			transaction: pocketsmith.Transaction{
+				ID: 1, Payee: "Amazon AU", Amount: -12, NeedsReview: true,
+				Category: pocketsmith.Category{ID: 1, Title: "Food"},
+			},
+			want: []FieldChange{
+				{"category", "Food", "Household"},
+				{"needs_review", "true", "false"},
+			},
+		},
+		"amazon over $20": {
+			transaction: pocketsmith.Transaction{ID: 2, Payee: "Amazon AU", Amount: -25},
+		},
+		"continue onto the next rule": {
+			transaction: pocketsmith.Transaction{
+				ID: 3, Payee: "Coffee Co", OriginalPayee: "SQ *COFFEE", Amount: -4,
+				Labels:             []string{"todo"},
+				TransactionAccount: pocketsmith.TransactionAccount{ID: 1},
+			},
+			want: []FieldChange{
+				{"category", "", "Coffee"},
+				{"labels", "todo", "square"},
+				{"note", "", "caffeine"},
+			},
+		},
+		"wrong transaction account": {
+			transaction: pocketsmith.Transaction{
+				ID: 4, Payee: "Coffee Co", Amount: -4,
+				TransactionAccount: pocketsmith.TransactionAccount{ID: 2},
+			},
+		},
+		"labels": {
+			transaction: pocketsmith.Transaction{ID: 5, Payee: "Flights", Amount: -900, Labels: []string{"Work"}},
+			want:        []FieldChange{{"needs_review", "false", "true"}},
+		},
+		"already matches": {
+			transaction: pocketsmith.Transaction{
+				ID: 6, Payee: "amazon", Amount: -5,
+				Category: pocketsmith.Category{ID: 2, Title: "Household"},
+			},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			changes := e.Evaluate(pocketsmith.Transactions{tt.transaction})
+			var got []FieldChange
+			if len(changes) > 0 {
+				got = changes[0].Fields
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() returned unexpected changes;\nwant=%+v\ngot=%+v\n", tt.want, got)
+			}
+		})
+	}
+}
+
+// mockAPI is a mock implementation of the API interface.
+type mockAPI struct {
+	updated []*pocketsmith.UpdateTransactionOptions
+}
+
+func (m *mockAPI) UpdateTransaction(
+	_ context.Context,
+	options *pocketsmith.UpdateTransactionOptions,
+) (*pocketsmith.Transaction, error) {
+	m.updated = append(m.updated, options)
+	return &pocketsmith.Transaction{ID: options.TransactionID}, nil
+}
+
+func Test_Apply(t *testing.T) {
+	e, err := New(Rule{
+		Name:    "groceries",
+		Match:   Match{Payee: &Text{Contains: "market"}},
+		Actions: Actions{CategoryID: 9},
+	})
+	if err != nil {
+		t.Fatalf("New() returned an error; error=%v", err)
+	}
+	api := &mockAPI{}
+	results := Apply(context.Background(), api, e.Evaluate(pocketsmith.Transactions{
+		{ID: 1, Payee: "Market"},
+		{ID: 2, Payee: "Petrol"},
+	}))
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("Apply() returned unexpected results; got=%+v", results)
+	}
+	want := &pocketsmith.UpdateTransactionOptions{TransactionID: 1, CategoryID: 9}
+	if !reflect.DeepEqual(api.updated[0], want) {
+		t.Errorf("Apply() sent unexpected options;\nwant=%+v\ngot=%+v\n", want, api.updated[0])
+	}
+}
+
+func Test_Apply_lastLabel(t *testing.T) {
+	e, err := New(Rule{
+		Name:    "done",
+		Match:   Match{Labels: []string{"todo"}},
+		Actions: Actions{RemoveLabels: []string{"todo"}},
+	})
+	if err != nil {
+		t.Fatalf("New() returned an error; error=%v", err)
+	}
+	changes := e.Evaluate(pocketsmith.Transactions{{ID: 1, Labels: []string{"todo"}}})
+	if len(changes) != 1 || len(changes[0].Fields) != 0 {
+		t.Fatalf("Evaluate() returned unexpected changes; got=%+v", changes)
+	}
+	if want := []FieldChange{{"labels", "todo", ""}}; !reflect.DeepEqual(changes[0].Skipped, want) {
+		t.Errorf("Evaluate() skipped unexpected changes;\nwant=%+v\ngot=%+v\n", want, changes[0].Skipped)
+	}
+
+	// the change isn't sent, or reported as applied.
+	api := &mockAPI{}
+	results := Apply(context.Background(), api, changes)
+	if len(results) != 1 || !results[0].Skipped || results[0].Transaction != nil {
+		t.Errorf("Apply() returned unexpected results; got=%+v", results)
+	}
+	if len(api.updated) != 0 {
+		t.Errorf("Apply() sent a change with nothing to apply; got=%+v", api.updated)
+	}
+}
+
+func Test_New(t *testing.T) {
+	tests := map[string]Rule{
+		"invalid regex": {Name: "a", Match: Match{Payee: &Text{Regex: "("}}, Actions: Actions{CategoryID: 1}},
+		"no actions":    {Name: "b", Match: Match{Payee: &Text{Contains: "x"}}},
+		"debit & credit": {
+			Name: "c", Match: Match{Debit: true, Credit: true}, Actions: Actions{CategoryID: 1},
+		},
+	}
+	for name, r := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := New(r); err == nil {
+				t.Errorf("New() didn't return an error")
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"net/url"
 
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // senderRequest represents the parameters for sending a request to the API,
@@ -22,6 +23,14 @@ type senderRequest struct {
 	queries url.Values  // Any URL query parameters to send with the request.
 }
 
+// senderResponse represents the raw response returned from the API, before
+// it is unmarshalled into a result. It is what gets shared between callers
+// when identical requests are coalesced.
+type senderResponse struct {
+	resp *http.Response // The response returned from the API.
+	body []byte         // The body read from the response.
+}
+
 // apiErrorResponse represents an individual error returned when sending a
 // request to the API.
 type apiErrorResponse struct {
@@ -32,6 +41,10 @@ type apiErrorResponse struct {
 // processes the response. A 'result' interface{} can be given to unmarshal any
 // body returned in the response, which then can be used wherever this function
 // is called.
+//
+// NOTE: identical GET requests that are in-flight at the same time are
+// coalesced into a single request to the API, with the response shared
+// between all callers.
 func (c *Client) sender(
 	ctx context.Context,
 	sr senderRequest,
@@ -63,18 +76,25 @@ func (c *Client) sender(
 	// add headers to request.
 	req.Header = c.headers
 
-	// send request.
-	resp, err = c.httpClient.Do(req)
-	if err != nil {
-		return nil, ErrSenderFailedSendRequest{err}
+	// send request; coalescing identical in-flight GET requests.
+	var sresp *senderResponse
+	shared := false
+	if sr.method == http.MethodGet {
+		key := req.URL.String() + "\n" + string(body)
+		flight := c.inflight.DoChan(key, func() (interface{}, error) {
+			return c.send(req)
+		})
+		res := <-flight
+		sresp, _ = res.Val.(*senderResponse)
+		err, shared = res.Err, res.Shared
+	} else {
+		sresp, err = c.send(req)
 	}
-	defer resp.Body.Close()
-
-	// parse response.
-	b, err := io.ReadAll(resp.Body)
+	span.SetAttributes(attribute.Bool("shared", shared))
 	if err != nil {
-		return nil, ErrSenderFailedParseResponse{err}
+		return nil, err
 	}
+	resp, b := sresp.resp, sresp.body
 
 	// determine if the response was successful or a failure.
 	if http.StatusOK <= resp.StatusCode && resp.StatusCode < http.StatusMultipleChoices {
@@ -92,3 +112,22 @@ func (c *Client) sender(
 	}
 	return nil, ErrSenderInvalidResponse{errs, resp.StatusCode}
 }
+
+// send sends the given *http.Request to the API and reads the body of the
+// response, so that it can be shared between callers.
+func (c *Client) send(req *http.Request) (*senderResponse, error) {
+
+	// send request.
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, ErrSenderFailedSendRequest{err}
+	}
+	defer resp.Body.Close()
+
+	// parse response.
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, ErrSenderFailedParseResponse{err}
+	}
+	return &senderResponse{resp, b}, nil
+}
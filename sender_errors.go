@@ -48,3 +48,8 @@ func (e ErrSenderInvalidResponse) Error() string {
 		e.resp.Error,
 	)
 }
+
+// StatusCode returns the status code of the error response.
+func (e ErrSenderInvalidResponse) StatusCode() int {
+	return e.statusCode
+}
@@ -1,11 +1,17 @@
 package pocketsmith
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // A reader to break reading from a *http.Request body.
@@ -114,3 +120,102 @@ func Test_sender(t *testing.T) {
 		})
 	}
 }
+
+func Test_sender_coalesce(t *testing.T) {
+	tests := map[string]struct {
+		method  string
+		callers int
+		want    int32
+	}{
+		"identical GET requests are coalesced": {
+			method:  http.MethodGet,
+			callers: 10,
+			want:    1,
+		},
+		"identical POST requests are not coalesced": {
+			method:  http.MethodPost,
+			callers: 10,
+			want:    10,
+		},
+	}
+	for name, tt := range tests {
+
+		// setup mock; a GET request is blocked until every caller is waiting
+		// on it, so that they're all coalesced into it.
+		var calls int32
+		var callers int
+		mock := &mockRoundTripper{
+			MockFunc: func(req *http.Request) *http.Response {
+				atomic.AddInt32(&calls, 1)
+				if req.Method == http.MethodGet {
+					for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+						if waitingInSender() == callers {
+							break
+						}
+						time.Sleep(time.Millisecond)
+					}
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"id":1}`)),
+					Header:     make(http.Header),
+				}
+			},
+		}
+
+		// setup client with mock.
+		c := &Client{
+			httpClient: &http.Client{Transport: mock},
+			logger:     logger,
+			headers:    make(http.Header),
+		}
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			callers = tt.callers
+			var wg sync.WaitGroup
+			results := make([]User, tt.callers)
+			errs := make([]error, tt.callers)
+			for i := 0; i < tt.callers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = c.sender(
+						context.Background(),
+						senderRequest{method: tt.method, path: "/me"},
+						&results[i],
+					)
+				}(i)
+			}
+			wg.Wait()
+
+			// any errors?
+			for i := range errs {
+				if errs[i] != nil {
+					t.Errorf("sender() returned an error;\nerror=%v\n", errs[i])
+					return
+				}
+				if results[i].ID != 1 {
+					t.Errorf("sender() returned an unexpected result;\nwant=1\ngot=%v\n", results[i].ID)
+					return
+				}
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.want {
+				t.Errorf("sender() sent an unexpected number of requests;\nwant=%v\ngot=%v\n", tt.want, got)
+			}
+		})
+	}
+}
+
+// waitingInSender returns the number of goroutines in sender that are waiting
+// on the response of a request they sent, or joined.
+func waitingInSender() (n int) {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	for _, g := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.Contains(g, []byte("[chan receive")) && bytes.Contains(g, []byte(".(*Client).sender(")) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,299 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// Kind defines the kind of a Change.
+type Kind string
+
+const (
+	KindAdded    Kind = "added"    // The entity only exists in the newer snapshot.
+	KindRemoved  Kind = "removed"  // The entity only exists in the older snapshot.
+	KindModified Kind = "modified" // The entity exists in both snapshots, but its fields differ.
+)
+
+// Entity defines the type of entity a Change is for.
+type Entity string
+
+const (
+	EntityInstitution        Entity = "institution"
+	EntityAccount            Entity = "account"
+	EntityTransactionAccount Entity = "transaction account"
+	EntityCategory           Entity = "category"
+	EntityTransaction        Entity = "transaction"
+)
+
+// Field defines a field of an entity that changed.
+type Field struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Change defines an entity that changed between two snapshots.
+type Change struct {
+	Kind    Kind    `json:"kind"`
+	Entity  Entity  `json:"entity"`
+	ID      int64   `json:"id"`
+	Summary string  `json:"summary"`          // A short description of the entity, eg. its date, payee & amount.
+	Fields  []Field `json:"fields,omitempty"` // The fields that changed, if modified.
+}
+
+// String returns the change as a single line, eg.
+//
+//	transaction 123 category Food→Food/Groceries, amount -12.00→-12.50
+func (c Change) String() string {
+	if c.Kind != KindModified {
+		return fmt.Sprintf("%s %v %s: %s", c.Entity, c.ID, c.Kind, c.Summary)
+	}
+	fields := make([]string, len(c.Fields))
+	for i, f := range c.Fields {
+		fields[i] = fmt.Sprintf("%s %s→%s", f.Name, display(f.From), display(f.To))
+	}
+	return fmt.Sprintf("%s %v %s", c.Entity, c.ID, strings.Join(fields, ", "))
+}
+
+// display returns the given value for display, making empty values visible.
+func display(value string) string {
+	if value == "" {
+		return `""`
+	}
+	return value
+}
+
+// Diff defines the changes between two snapshots.
+type Diff []Change
+
+// Count returns the number of changes of the given kind.
+func (d Diff) Count(kind Kind) (n int) {
+	for _, c := range d {
+		if c.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteTo writes the diff to w, one change per line, followed by a summary.
+func (d Diff) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, c := range d {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(
+		&b,
+		"%v added, %v removed, %v modified\n",
+		d.Count(KindAdded),
+		d.Count(KindRemoved),
+		d.Count(KindModified),
+	)
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Compare returns the changes from the older to the newer snapshot; the
+// institutions, accounts, transaction accounts, categories and transactions
+// that were added, removed or modified, in that order, then by id.
+//
+// Derived values that change whenever anything else does, like the current
+// balances of accounts and the closing balances of transactions, and the
+// times entities were updated, are not compared.
+func Compare(older, newer *Snapshot) (diff Diff) {
+	olderTree := pocketsmith.NewCategoryTree(older.Categories)
+	newerTree := pocketsmith.NewCategoryTree(newer.Categories)
+	diff = append(diff, compare(
+		EntityInstitution,
+		records(older.Institutions, institutionRecord),
+		records(newer.Institutions, institutionRecord),
+	)...)
+	diff = append(diff, compare(
+		EntityAccount,
+		records(older.Accounts, accountRecord),
+		records(newer.Accounts, accountRecord),
+	)...)
+	diff = append(diff, compare(
+		EntityTransactionAccount,
+		records(older.TransactionAccounts, transactionAccountRecord),
+		records(newer.TransactionAccounts, transactionAccountRecord),
+	)...)
+	diff = append(diff, compare(
+		EntityCategory,
+		categoryRecords(olderTree),
+		categoryRecords(newerTree),
+	)...)
+	diff = append(diff, compare(
+		EntityTransaction,
+		records(older.Transactions, func(t pocketsmith.Transaction) record {
+			return transactionRecord(t, olderTree)
+		}),
+		records(newer.Transactions, func(t pocketsmith.Transaction) record {
+			return transactionRecord(t, newerTree)
+		}),
+	)...)
+	return diff
+}
+
+// record defines an entity flattened for comparison.
+type record struct {
+	id      int64
+	summary string
+	fields  []Field // Only Name & To are set.
+}
+
+// records flattens the given entities with fn.
+func records[T any](entities []T, fn func(T) record) []record {
+	out := make([]record, len(entities))
+	for i, e := range entities {
+		out[i] = fn(e)
+	}
+	return out
+}
+
+// compare returns the changes between the given older and newer records.
+func compare(entity Entity, older, newer []record) (changes []Change) {
+	byID := make(map[int64]record, len(older))
+	for _, r := range older {
+		byID[r.id] = r
+	}
+	seen := make(map[int64]bool, len(newer))
+	for _, r := range newer {
+		seen[r.id] = true
+		o, ok := byID[r.id]
+		if !ok {
+			changes = append(changes, Change{Kind: KindAdded, Entity: entity, ID: r.id, Summary: r.summary})
+			continue
+		}
+		var fields []Field
+		for i, f := range r.fields {
+			if from := o.fields[i].To; from != f.To {
+				fields = append(fields, Field{Name: f.Name, From: from, To: f.To})
+			}
+		}
+		if len(fields) > 0 {
+			changes = append(changes, Change{
+				Kind:    KindModified,
+				Entity:  entity,
+				ID:      r.id,
+				Summary: r.summary,
+				Fields:  fields,
+			})
+		}
+	}
+	for _, r := range older {
+		if !seen[r.id] {
+			changes = append(changes, Change{Kind: KindRemoved, Entity: entity, ID: r.id, Summary: r.summary})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes
+}
+
+// fields returns the given name & value pairs as fields.
+func fields(pairs ...string) []Field {
+	out := make([]Field, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		out = append(out, Field{Name: pairs[i], To: pairs[i+1]})
+	}
+	return out
+}
+
+// institutionRecord flattens the given institution.
+func institutionRecord(i pocketsmith.Institution) record {
+	return record{
+		id:      int64(i.ID),
+		summary: i.Title,
+		fields:  fields("title", i.Title, "currency", i.CurrencyCode),
+	}
+}
+
+// accountRecord flattens the given account.
+func accountRecord(a pocketsmith.Account) record {
+	return record{
+		id:      int64(a.ID),
+		summary: a.Title,
+		fields: fields(
+			"title", a.Title,
+			"type", string(a.Type),
+			"currency", a.CurrencyCode,
+			"net_worth", strconv.FormatBool(a.IsNetWorth),
+		),
+	}
+}
+
+// transactionAccountRecord flattens the given transaction account.
+func transactionAccountRecord(ta pocketsmith.TransactionAccount) record {
+	return record{
+		id:      int64(ta.ID),
+		summary: ta.Name,
+		fields: fields(
+			"name", ta.Name,
+			"number", ta.Number,
+			"type", ta.Type,
+			"currency", ta.CurrencyCode,
+			"institution", ta.Institution.Title,
+			"starting_balance", formatAmount(ta.StartingBalance),
+			"starting_balance_date", ta.StartingBalanceDate,
+		),
+	}
+}
+
+// categoryRecords flattens the given category tree.
+func categoryRecords(tree *pocketsmith.CategoryTree) (out []record) {
+	for _, c := range tree.Flatten() {
+		out = append(out, record{
+			id:      int64(c.ID),
+			summary: c.Path,
+			fields: fields(
+				"title", c.Title,
+				"path", c.Path,
+				"colour", c.Colour,
+				"transfer", strconv.FormatBool(c.IsTransfer),
+				"bill", strconv.FormatBool(c.IsBill),
+				"roll_up", strconv.FormatBool(c.RollUp),
+				"refund_behaviour", c.RefundBehaviour,
+			),
+		})
+	}
+	return out
+}
+
+// transactionRecord flattens the given transaction, naming its category by
+// its path in the given category tree.
+func transactionRecord(t pocketsmith.Transaction, tree *pocketsmith.CategoryTree) record {
+	category := t.Category.Title
+	if path := tree.Path(t.Category.ID); path != "" {
+		category = path
+	}
+	return record{
+		id:      int64(t.ID),
+		summary: fmt.Sprintf("%s %s %s", t.Date, t.Payee, formatAmount(t.Amount)),
+		fields: fields(
+			"date", t.Date,
+			"payee", t.Payee,
+			"original_payee", t.OriginalPayee,
+			"amount", formatAmount(t.Amount),
+			"category", category,
+			"labels", strings.Join(t.Labels, ","),
+			"note", t.Note,
+			"memo", t.Memo,
+			"cheque_number", t.ChequeNumber,
+			"transaction_account", t.TransactionAccount.Name,
+			"status", t.Status,
+			"transfer", strconv.FormatBool(t.IsTransfer),
+			"needs_review", strconv.FormatBool(t.NeedsReview),
+		),
+	}
+}
+
+// formatAmount formats the given amount with two decimal places.
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,36 @@
+package snapshot
+
+import (
+	"fmt"
+)
+
+// ErrSnapshotFailedList is returned when Take fails to list an entity from
+// the API.
+type ErrSnapshotFailedList struct {
+	entity string
+	err    error
+}
+
+func (e ErrSnapshotFailedList) Error() string {
+	return fmt.Sprintf("failed to list %s: %v", e.entity, e.err)
+}
+
+// ErrSnapshotFailedRead is returned when a snapshot fails to be read.
+type ErrSnapshotFailedRead struct {
+	path string
+	err  error
+}
+
+func (e ErrSnapshotFailedRead) Error() string {
+	return fmt.Sprintf("failed to read snapshot %s: %v", e.path, e.err)
+}
+
+// ErrSnapshotFailedWrite is returned when a snapshot fails to be written.
+type ErrSnapshotFailedWrite struct {
+	path string
+	err  error
+}
+
+func (e ErrSnapshotFailedWrite) Error() string {
+	return fmt.Sprintf("failed to write snapshot %s: %v", e.path, e.err)
+}
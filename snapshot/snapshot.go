@@ -0,0 +1,205 @@
+// Package snapshot takes point in time copies of a PocketSmith user, as JSON,
+// and compares them to report exactly what changed between them.
+//
+// Take lists a user into a Snapshot, which is written to disk with Save. Load
+// reads a snapshot back; it also reads the archives written by the backup
+// package, and the files written by sync.JSONFileStore, so that any of them
+// can be compared. Compare returns the entities added, removed and modified
+// between two snapshots, with the fields that changed:
+//
+//	transaction 123 category Food→Food/Groceries, amount -12.00→-12.50
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/backup"
+	"github.com/jmpa-io/pocketsmith-go/internal/atomicfile"
+)
+
+// The name of the tracer output in the traces.
+const tracerName = "pocketsmith-go/snapshot"
+
+// API defines the parts of the *pocketsmith.Client used by Take.
+type API interface {
+	ListInstitutionsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListInstitutionsForUser,
+	) (pocketsmith.Institutions, error)
+	ListAccountsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListAccountsForUserOptions,
+	) (pocketsmith.Accounts, error)
+	ListTransactionAccountsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionAccountsForUserOptions,
+	) (pocketsmith.TransactionAccounts, error)
+	ListCategoriesForUser(
+		ctx context.Context,
+		options *pocketsmith.ListCategoriesForUserOptions,
+	) (pocketsmith.Categories, error)
+	ListTransactionsForUserPages(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionsForUserOptions,
+		fn func(pocketsmith.Transactions) error,
+	) error
+}
+
+// Snapshot defines a point in time copy of a PocketSmith user. Its JSON
+// layout matches that of a backup.Archive.
+type Snapshot struct {
+	CreatedAt           time.Time                       `json:"created_at"`
+	Institutions        pocketsmith.Institutions        `json:"institutions"`
+	Accounts            pocketsmith.Accounts            `json:"accounts"`
+	TransactionAccounts pocketsmith.TransactionAccounts `json:"transaction_accounts"`
+	Categories          pocketsmith.Categories          `json:"categories"` // The category tree, as returned from the API.
+	Transactions        pocketsmith.Transactions        `json:"transactions"`
+}
+
+// Take lists the given user into a Snapshot.
+func Take(ctx context.Context, api API, userID int) (snapshot *Snapshot, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Take")
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to take snapshot: %v", err))
+			span.RecordError(err)
+		}
+	}()
+
+	snapshot = &Snapshot{CreatedAt: time.Now().UTC()}
+	if snapshot.Institutions, err = api.ListInstitutionsForUser(
+		newCtx,
+		&pocketsmith.ListInstitutionsForUser{UserID: userID},
+	); err != nil {
+		return nil, ErrSnapshotFailedList{"institutions", err}
+	}
+	if snapshot.Accounts, err = api.ListAccountsForUser(
+		newCtx,
+		&pocketsmith.ListAccountsForUserOptions{UserID: userID},
+	); err != nil {
+		return nil, ErrSnapshotFailedList{"accounts", err}
+	}
+	if snapshot.TransactionAccounts, err = api.ListTransactionAccountsForUser(
+		newCtx,
+		&pocketsmith.ListTransactionAccountsForUserOptions{UserID: userID},
+	); err != nil {
+		return nil, ErrSnapshotFailedList{"transaction accounts", err}
+	}
+	if snapshot.Categories, err = api.ListCategoriesForUser(
+		newCtx,
+		&pocketsmith.ListCategoriesForUserOptions{UserID: userID},
+	); err != nil {
+		return nil, ErrSnapshotFailedList{"categories", err}
+	}
+	err = api.ListTransactionsForUserPages(
+		newCtx,
+		&pocketsmith.ListTransactionsForUserOptions{UserID: userID},
+		func(batch pocketsmith.Transactions) error {
+			snapshot.Transactions = append(snapshot.Transactions, batch...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, ErrSnapshotFailedList{"transactions", err}
+	}
+	span.SetAttributes(attribute.Int("transactions", len(snapshot.Transactions)))
+	return snapshot, nil
+}
+
+// Save writes the given snapshot to the file at the given path, only readable
+// by its owner, via a temporary file that is renamed over the original, so
+// that a failed write never corrupts an existing snapshot.
+func Save(path string, snapshot *Snapshot) error {
+	b, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return ErrSnapshotFailedWrite{path, err}
+	}
+	if err := atomicfile.Write(path, b, 0o600); err != nil {
+		return ErrSnapshotFailedWrite{path, err}
+	}
+	return nil
+}
+
+// Load reads the snapshot in the file at the given path. The file can be a
+// snapshot written by Save, an archive written by backup.WriteArchive, or a
+// file written by sync.JSONFileStore.
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, ErrSnapshotFailedRead{path, err}
+	}
+	defer f.Close()
+	snapshot, err := Read(f)
+	if err != nil {
+		return nil, ErrSnapshotFailedRead{path, err}
+	}
+	return snapshot, nil
+}
+
+// Read reads a snapshot from r; see Load.
+func Read(r io.Reader) (*Snapshot, error) {
+
+	// read a backup archive, which is gzipped.
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		archive, err := backup.ReadArchive(br)
+		if err != nil {
+			return nil, err
+		}
+		return &Snapshot{
+			CreatedAt:           archive.CreatedAt,
+			Institutions:        archive.Institutions,
+			Accounts:            archive.Accounts,
+			TransactionAccounts: archive.TransactionAccounts,
+			Categories:          archive.Categories,
+			Transactions:        archive.Transactions,
+		}, nil
+	}
+
+	// read a snapshot, or a sync store; which holds the transactions in a
+	// map, by id.
+	var raw struct {
+		Snapshot
+		Transactions json.RawMessage `json:"transactions"`
+	}
+	if err := json.NewDecoder(br).Decode(&raw); err != nil {
+		return nil, err
+	}
+	snapshot := raw.Snapshot
+	if len(raw.Transactions) == 0 || string(raw.Transactions) == "null" {
+		return &snapshot, nil
+	}
+	if raw.Transactions[0] != '{' {
+		if err := json.Unmarshal(raw.Transactions, &snapshot.Transactions); err != nil {
+			return nil, err
+		}
+		return &snapshot, nil
+	}
+	var byID map[int32]pocketsmith.Transaction
+	if err := json.Unmarshal(raw.Transactions, &byID); err != nil {
+		return nil, err
+	}
+	for _, t := range byID {
+		snapshot.Transactions = append(snapshot.Transactions, t)
+	}
+	sort.Slice(snapshot.Transactions, func(i, j int) bool {
+		return snapshot.Transactions[i].ID < snapshot.Transactions[j].ID
+	})
+	return &snapshot, nil
+}
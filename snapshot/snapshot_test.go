@@ -0,0 +1,101 @@
+package snapshot
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/backup"
+)
+
+func Test_Compare(t *testing.T) {
+	groceries := &pocketsmith.Category{ID: 2, Title: "Groceries"}
+	categories := pocketsmith.Categories{{ID: 1, Title: "Food", Children: []*pocketsmith.Category{groceries}}}
+	everyday := pocketsmith.TransactionAccount{ID: 10, Name: "Everyday"}
+	older := &Snapshot{
+		Accounts:            pocketsmith.Accounts{{ID: 5, Title: "Bank"}},
+		TransactionAccounts: pocketsmith.TransactionAccounts{everyday},
+		Categories:          categories,
+		Transactions: pocketsmith.Transactions{
+			{ID: 123, Date: "2024-01-02", Payee: "Coles", Amount: -12, Category: categories[0], TransactionAccount: everyday},
+			{ID: 124, Date: "2024-01-03", Payee: "Shell", Amount: -50, ClosingBalance: 100, TransactionAccount: everyday},
+			{ID: 125, Date: "2024-01-04", Payee: "Refund", Amount: 5, TransactionAccount: everyday},
+		},
+	}
+	newer := &Snapshot{
+		Accounts:            pocketsmith.Accounts{{ID: 5, Title: "Bank", IsNetWorth: true}},
+		TransactionAccounts: pocketsmith.TransactionAccounts{everyday},
+		Categories:          categories,
+		Transactions: pocketsmith.Transactions{
+			{ID: 123, Date: "2024-01-02", Payee: "Coles", Amount: -12.5, Category: *groceries, TransactionAccount: everyday},
+			{ID: 124, Date: "2024-01-03", Payee: "Shell", Amount: -50, ClosingBalance: 90, TransactionAccount: everyday},
+			{ID: 126, Date: "2024-01-05", Payee: "Aldi", Amount: -7, Note: "milk", TransactionAccount: everyday},
+		},
+	}
+	want := []string{
+		"account 5 net_worth false→true",
+		"transaction 123 amount -12.00→-12.50, category Food→Food/Groceries",
+		"transaction 125 removed: 2024-01-04 Refund 5.00",
+		"transaction 126 added: 2024-01-05 Aldi -7.00",
+	}
+
+	// run tests.
+	diff := Compare(older, newer)
+	var got []string
+	for _, c := range diff {
+		got = append(got, c.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() returned unexpected changes;\nwant=%q\ngot=%q", want, got)
+	}
+	var buf bytes.Buffer
+	if _, err := diff.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() returned an error: %v", err)
+	}
+	if !strings.HasSuffix(buf.String(), "1 added, 1 removed, 2 modified\n") {
+		t.Errorf("WriteTo() returned unexpected summary: %q", buf.String())
+	}
+}
+
+func Test_Read(t *testing.T) {
+	archive := &backup.Archive{
+		Version:      backup.Version,
+		Transactions: pocketsmith.Transactions{{ID: 1, Payee: "Coles"}},
+	}
+	var gz bytes.Buffer
+	if err := backup.WriteArchive(&gz, archive); err != nil {
+		t.Fatalf("WriteArchive() returned an error: %v", err)
+	}
+	tests := map[string]struct {
+		input []byte
+		want  pocketsmith.Transactions
+	}{
+		"snapshot": {
+			input: []byte(`{"transactions":[{"id":1,"payee":"Coles"}]}`),
+			want:  pocketsmith.Transactions{{ID: 1, Payee: "Coles"}},
+		},
+		"sync store": {
+			input: []byte(`{"checkpoints":{},"transactions":{"2":{"id":2,"payee":"Aldi"},"1":{"id":1,"payee":"Coles"}}}`),
+			want:  pocketsmith.Transactions{{ID: 1, Payee: "Coles"}, {ID: 2, Payee: "Aldi"}},
+		},
+		"backup archive": {
+			input: gz.Bytes(),
+			want:  pocketsmith.Transactions{{ID: 1, Payee: "Coles"}},
+		},
+	}
+	for name, tt := range tests {
+
+		// run tests.
+		t.Run(name, func(t *testing.T) {
+			got, err := Read(bytes.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Read() returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Transactions, tt.want) {
+				t.Errorf("Read() returned unexpected transactions; want=%+v, got=%+v", tt.want, got.Transactions)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package pocketsmith
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// StartingBalanceFromStatement returns the starting balance the given
+// transaction account needs, so that its balance at the end of the statement
+// date matches the statement balance, eg. the closing balance printed on a
+// bank statement. The given transactions must include every transaction in
+// the account between its starting balance date and the statement date;
+// transactions outside of that range, or in other accounts, are ignored.
+//
+// As in the API, the starting balance is the balance at the start of the
+// starting balance date; transactions dated on it are applied after the
+// starting balance, and transactions dated before it are part of it. The
+// statement date can be before the starting balance date, in which case the
+// transactions between them are added back.
+func StartingBalanceFromStatement(
+	account *TransactionAccount,
+	transactions Transactions,
+	statementDate string,
+	statementBalance float64,
+) (float64, error) {
+	if _, err := time.Parse(customTimeFormat, statementDate); err != nil {
+		return 0, ErrInvalidDate{"statement date", statementDate}
+	}
+	start := account.StartingBalanceDate
+	if start != "" {
+		if _, err := time.Parse(customTimeFormat, start); err != nil {
+			return 0, ErrInvalidDate{"starting balance date", start}
+		}
+	}
+
+	// remove the transactions from the starting balance date to the end of the
+	// statement date, or add back those after the statement date and before
+	// the starting balance date; ISO dates sort as strings.
+	balance := statementBalance
+	for _, t := range transactions {
+		if t.TransactionAccount.ID != 0 && t.TransactionAccount.ID != account.ID {
+			continue
+		}
+		switch {
+		case statementDate >= start && t.Date >= start && t.Date <= statementDate:
+			balance -= t.Amount
+		case statementDate < start && t.Date > statementDate && t.Date < start:
+			balance += t.Amount
+		}
+	}
+	return math.Round(balance*100) / 100, nil
+}
+
+// UpdateStartingBalanceFromStatementOptions defines the options for fixing the
+// starting balance of a transaction account from a known statement balance.
+type UpdateStartingBalanceFromStatementOptions struct {
+	TransactionAccountID int     `validator:"required"`
+	StatementDate        string  `validator:"required"` // eg. "2024-01-31".
+	StatementBalance     float64 // The balance at the end of the statement date.
+}
+
+// UpdateStartingBalanceFromStatement recalculates the starting balance of a
+// transaction account with StartingBalanceFromStatement, using the
+// transactions in the account, and updates it, so the closing balances of its
+// transactions match the statement. The starting balance date is unchanged.
+func (c *Client) UpdateStartingBalanceFromStatement(
+	ctx context.Context,
+	options *UpdateStartingBalanceFromStatementOptions,
+) (account *TransactionAccount, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "UpdateStartingBalanceFromStatement")
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to update starting balance: %v", err))
+			span.RecordError(err)
+		}
+	}()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		return nil, err
+	}
+
+	// list the transactions between the starting balance & statement dates.
+	account, err = c.GetTransactionAccount(
+		newCtx,
+		&GetTransactionAccountOptions{TransactionAccountID: options.TransactionAccountID},
+	)
+	if err != nil {
+		return nil, err
+	}
+	list := &ListTransactionAccountTransactionsOptions{
+		TransactionAccountID: strconv.Itoa(options.TransactionAccountID),
+		StartDate:            account.StartingBalanceDate,
+		EndDate:              options.StatementDate,
+	}
+	if account.StartingBalanceDate > options.StatementDate {
+		list.StartDate, list.EndDate = options.StatementDate, account.StartingBalanceDate
+	}
+	transactions, err := c.ListTransactionAccountTransactions(newCtx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	// update the starting balance.
+	balance, err := StartingBalanceFromStatement(
+		account,
+		transactions,
+		options.StatementDate,
+		options.StatementBalance,
+	)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.Float64("from", account.StartingBalance),
+		attribute.Float64("to", balance),
+	)
+	return c.UpdateTransactionAccount(newCtx, &UpdateTransactionAccountOptions{
+		TransactionAccountID: options.TransactionAccountID,
+		StartingBalance:      &balance,
+	})
+}
@@ -0,0 +1,110 @@
+package pocketsmith
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test_StartingBalanceFromStatement(t *testing.T) {
+	account := &TransactionAccount{ID: 1, StartingBalance: 50, StartingBalanceDate: "2024-01-01"}
+	transactions := Transactions{
+		{Date: "2024-01-01", Amount: -10}, // applied after the starting balance.
+		{Date: "2024-01-05", Amount: -20.10},
+		{Date: "2024-01-10", Amount: 100},
+		{Date: "2024-01-10", Amount: -5, TransactionAccount: TransactionAccount{ID: 2}}, // another account.
+		{Date: "2024-01-20", Amount: -30},
+	}
+	tests := map[string]struct {
+		account       *TransactionAccount
+		statementDate string
+		balance       float64
+		want          float64
+		err           string
+	}{
+		"after the starting date": {
+			account:       account,
+			statementDate: "2024-01-10",
+			balance:       200,
+			want:          130.10,
+		},
+		"on the starting date": {
+			account:       account,
+			statementDate: "2024-01-01",
+			balance:       75.5,
+			want:          85.5,
+		},
+		"before the starting date": {
+			account:       &TransactionAccount{ID: 1, StartingBalanceDate: "2024-01-10"},
+			statementDate: "2024-01-04",
+			balance:       20,
+			want:          -0.10,
+		},
+		"no starting date": {
+			account:       &TransactionAccount{ID: 1},
+			statementDate: "2024-01-31",
+			balance:       0,
+			want:          -39.90,
+		},
+		"invalid statement date": {
+			account:       account,
+			statementDate: "31/01/2024",
+			err:           `invalid statement date "31/01/2024"; expected a date like 2024-01-31`,
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := StartingBalanceFromStatement(tt.account, transactions, tt.statementDate, tt.balance)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("unexpected error returned; got: %v, want: %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error returned: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected value returned; got: %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_UpdateStartingBalanceFromStatement(t *testing.T) {
+
+	// setup mock; the account starts on 2024-01-01, with one transaction
+	// since.
+	var sent string
+	c := newMockClient(func(req *http.Request) *http.Response {
+		body := `{"id":7,"starting_balance":50,"starting_balance_date":"2024-01-01"}`
+		switch {
+		case req.Method == http.MethodPut:
+			b, _ := io.ReadAll(req.Body)
+			sent = req.URL.Path + " " + string(b)
+		case strings.HasSuffix(req.URL.Path, "/transactions"):
+			if q := req.URL.Query(); q.Get("start_date") != "2024-01-01" || q.Get("end_date") != "2024-01-31" {
+				t.Errorf("transactions listed for an unexpected range; got=%v", q)
+			}
+			body = `[{"date":"2024-01-05","amount":-25}]`
+		}
+		return mockResponse(http.StatusOK, body)
+	})
+
+	// run tests.
+	_, err := c.UpdateStartingBalanceFromStatement(context.Background(), &UpdateStartingBalanceFromStatementOptions{
+		TransactionAccountID: 7,
+		StatementDate:        "2024-01-31",
+		StatementBalance:     100,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error returned: %v", err)
+	}
+	if want := `/transaction_accounts/7 {"starting_balance":125}`; sent != want {
+		t.Errorf("unexpected update sent; got: %q, want: %q", sent, want)
+	}
+}
@@ -0,0 +1,55 @@
+package sync
+
+import (
+	"fmt"
+)
+
+// ErrSyncerNilStore is returned when no store is given to the syncer.
+type ErrSyncerNilStore struct {
+}
+
+func (e ErrSyncerNilStore) Error() string {
+	return "the provided store is nil"
+}
+
+// ErrSyncerFailedList is returned when the syncer fails to list an entity
+// from the API.
+type ErrSyncerFailedList struct {
+	entity string
+	err    error
+}
+
+func (e ErrSyncerFailedList) Error() string {
+	return fmt.Sprintf("failed to list %s: %v", e.entity, e.err)
+}
+
+// ErrSyncerFailedStore is returned when the syncer fails to read from, or
+// write to, the store.
+type ErrSyncerFailedStore struct {
+	entity string
+	err    error
+}
+
+func (e ErrSyncerFailedStore) Error() string {
+	return fmt.Sprintf("failed to store %s: %v", e.entity, e.err)
+}
+
+// ErrStoreFailedRead is returned when a file store fails to read its file.
+type ErrStoreFailedRead struct {
+	path string
+	err  error
+}
+
+func (e ErrStoreFailedRead) Error() string {
+	return fmt.Sprintf("failed to read store %q: %v", e.path, e.err)
+}
+
+// ErrStoreFailedWrite is returned when a file store fails to write its file.
+type ErrStoreFailedWrite struct {
+	path string
+	err  error
+}
+
+func (e ErrStoreFailedWrite) Error() string {
+	return fmt.Sprintf("failed to write store %q: %v", e.path, e.err)
+}
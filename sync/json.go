@@ -0,0 +1,132 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/jmpa-io/pocketsmith-go"
+	"github.com/jmpa-io/pocketsmith-go/internal/atomicfile"
+)
+
+// JSONFileStore is a Store that persists everything to a single JSON file on
+// disk. The file is rewritten atomically after every change.
+type JSONFileStore struct {
+	*MemoryStore
+
+	path string // The path to the JSON file.
+}
+
+// NewJSONFileStore returns a JSONFileStore backed by the file at the given
+// path. If the file already exists, it is loaded; otherwise it is created on
+// the first write.
+func NewJSONFileStore(path string) (*JSONFileStore, error) {
+	s := &JSONFileStore{MemoryStore: NewMemoryStore(), path: path}
+
+	// load existing file, if any.
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, ErrStoreFailedRead{path, err}
+	}
+	st := newState()
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, ErrStoreFailedRead{path, err}
+	}
+	if st.Checkpoints == nil {
+		st.Checkpoints = make(map[int]*Checkpoint)
+	}
+	if st.Transactions == nil {
+		st.Transactions = make(map[int32]pocketsmith.Transaction)
+	}
+	s.state = st
+	return s, nil
+}
+
+// save writes the current state to disk, only readable by its owner, via a
+// temporary file that is renamed over the original, so that a failed write
+// never corrupts the store.
+func (s *JSONFileStore) save() error {
+	s.mu.RLock()
+	b, err := json.MarshalIndent(s.state, "", "  ")
+	s.mu.RUnlock()
+	if err != nil {
+		return ErrStoreFailedWrite{s.path, err}
+	}
+	if err := atomicfile.Write(s.path, b, 0o600); err != nil {
+		return ErrStoreFailedWrite{s.path, err}
+	}
+	return nil
+}
+
+// SetCheckpoint stores the given checkpoint.
+func (s *JSONFileStore) SetCheckpoint(ctx context.Context, checkpoint *Checkpoint) error {
+	if err := s.MemoryStore.SetCheckpoint(ctx, checkpoint); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// ReplaceInstitutions replaces all stored institutions.
+func (s *JSONFileStore) ReplaceInstitutions(
+	ctx context.Context,
+	institutions pocketsmith.Institutions,
+) error {
+	if err := s.MemoryStore.ReplaceInstitutions(ctx, institutions); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// ReplaceAccounts replaces all stored accounts.
+func (s *JSONFileStore) ReplaceAccounts(ctx context.Context, accounts pocketsmith.Accounts) error {
+	if err := s.MemoryStore.ReplaceAccounts(ctx, accounts); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// ReplaceTransactionAccounts replaces all stored transaction accounts.
+func (s *JSONFileStore) ReplaceTransactionAccounts(
+	ctx context.Context,
+	transactionAccounts pocketsmith.TransactionAccounts,
+) error {
+	if err := s.MemoryStore.ReplaceTransactionAccounts(ctx, transactionAccounts); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// ReplaceCategories replaces all stored categories.
+func (s *JSONFileStore) ReplaceCategories(
+	ctx context.Context,
+	categories pocketsmith.Categories,
+) error {
+	if err := s.MemoryStore.ReplaceCategories(ctx, categories); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// UpsertTransactions adds the given transactions, replacing any stored
+// transactions with the same id.
+func (s *JSONFileStore) UpsertTransactions(
+	ctx context.Context,
+	transactions pocketsmith.Transactions,
+) error {
+	if err := s.MemoryStore.UpsertTransactions(ctx, transactions); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// DeleteTransactions removes the stored transactions with the given ids.
+func (s *JSONFileStore) DeleteTransactions(ctx context.Context, ids ...int32) error {
+	if err := s.MemoryStore.DeleteTransactions(ctx, ids...); err != nil {
+		return err
+	}
+	return s.save()
+}
@@ -0,0 +1,176 @@
+package sync
+
+import (
+	"context"
+	"sort"
+	gosync "sync"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// state is the data held by a MemoryStore. It is also the format that a
+// JSONFileStore persists to disk.
+type state struct {
+	Checkpoints         map[int]*Checkpoint               `json:"checkpoints"`
+	Institutions        pocketsmith.Institutions          `json:"institutions"`
+	Accounts            pocketsmith.Accounts              `json:"accounts"`
+	TransactionAccounts pocketsmith.TransactionAccounts   `json:"transaction_accounts"`
+	Categories          pocketsmith.Categories            `json:"categories"`
+	Transactions        map[int32]pocketsmith.Transaction `json:"transactions"`
+}
+
+// newState returns an empty state.
+func newState() *state {
+	return &state{
+		Checkpoints:  make(map[int]*Checkpoint),
+		Transactions: make(map[int32]pocketsmith.Transaction),
+	}
+}
+
+// MemoryStore is a Store that holds everything in memory. It is useful for
+// tests, or for short-lived processes that don't need to persist anything.
+type MemoryStore struct {
+	mu    gosync.RWMutex
+	state *state
+}
+
+// NewMemoryStore returns a new, empty, MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{state: newState()}
+}
+
+// Checkpoint returns the checkpoint for the given user.
+func (s *MemoryStore) Checkpoint(_ context.Context, userID int) (*Checkpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	cp, ok := s.state.Checkpoints[userID]
+	if !ok {
+		return nil, nil
+	}
+	out := *cp
+	return &out, nil
+}
+
+// SetCheckpoint stores the given checkpoint.
+func (s *MemoryStore) SetCheckpoint(_ context.Context, checkpoint *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *checkpoint
+	s.state.Checkpoints[cp.UserID] = &cp
+	return nil
+}
+
+// Institutions returns the stored institutions.
+func (s *MemoryStore) Institutions(_ context.Context) (pocketsmith.Institutions, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(pocketsmith.Institutions(nil), s.state.Institutions...), nil
+}
+
+// ReplaceInstitutions replaces all stored institutions.
+func (s *MemoryStore) ReplaceInstitutions(
+	_ context.Context,
+	institutions pocketsmith.Institutions,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Institutions = append(pocketsmith.Institutions(nil), institutions...)
+	return nil
+}
+
+// Accounts returns the stored accounts.
+func (s *MemoryStore) Accounts(_ context.Context) (pocketsmith.Accounts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(pocketsmith.Accounts(nil), s.state.Accounts...), nil
+}
+
+// ReplaceAccounts replaces all stored accounts.
+func (s *MemoryStore) ReplaceAccounts(_ context.Context, accounts pocketsmith.Accounts) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Accounts = append(pocketsmith.Accounts(nil), accounts...)
+	return nil
+}
+
+// TransactionAccounts returns the stored transaction accounts.
+func (s *MemoryStore) TransactionAccounts(
+	_ context.Context,
+) (pocketsmith.TransactionAccounts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(pocketsmith.TransactionAccounts(nil), s.state.TransactionAccounts...), nil
+}
+
+// ReplaceTransactionAccounts replaces all stored transaction accounts.
+func (s *MemoryStore) ReplaceTransactionAccounts(
+	_ context.Context,
+	transactionAccounts pocketsmith.TransactionAccounts,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.TransactionAccounts = append(
+		pocketsmith.TransactionAccounts(nil),
+		transactionAccounts...,
+	)
+	return nil
+}
+
+// Categories returns the stored categories.
+func (s *MemoryStore) Categories(_ context.Context) (pocketsmith.Categories, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(pocketsmith.Categories(nil), s.state.Categories...), nil
+}
+
+// ReplaceCategories replaces all stored categories.
+func (s *MemoryStore) ReplaceCategories(
+	_ context.Context,
+	categories pocketsmith.Categories,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Categories = append(pocketsmith.Categories(nil), categories...)
+	return nil
+}
+
+// Transactions returns the stored transactions, ordered by date.
+func (s *MemoryStore) Transactions(_ context.Context) (pocketsmith.Transactions, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	transactions := make(pocketsmith.Transactions, 0, len(s.state.Transactions))
+	for _, t := range s.state.Transactions {
+		transactions = append(transactions, t)
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].Date != transactions[j].Date {
+			return transactions[i].Date < transactions[j].Date
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+	return transactions, nil
+}
+
+// UpsertTransactions adds the given transactions, replacing any stored
+// transactions with the same id.
+func (s *MemoryStore) UpsertTransactions(
+	_ context.Context,
+	transactions pocketsmith.Transactions,
+) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range transactions {
+		s.state.Transactions[t.ID] = t
+	}
+	return nil
+}
+
+// DeleteTransactions removes the stored transactions with the given ids.
+func (s *MemoryStore) DeleteTransactions(_ context.Context, ids ...int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.state.Transactions, id)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// Checkpoint records how far a Syncer has synced a user, so that the next
+// sync only needs to fetch what has changed since.
+type Checkpoint struct {
+	UserID int `json:"user_id"`
+
+	// LastFullSync is when the last full pull of the user was completed.
+	LastFullSync time.Time `json:"last_full_sync"`
+
+	// LastSync is when the last sync (full or incremental) was completed.
+	LastSync time.Time `json:"last_sync"`
+
+	// TransactionsUpdatedSince is the latest `updated_at` seen across all
+	// synced transactions. It is passed as `updated_since` on the next sync.
+	// It uses the time returned from the API, rather than the local clock, to
+	// avoid missing changes due to clock skew.
+	TransactionsUpdatedSince time.Time `json:"transactions_updated_since"`
+}
+
+// Store defines a local store that a Syncer mirrors a user into. Stores are
+// expected to be safe for concurrent use.
+type Store interface {
+
+	// Checkpoint returns the checkpoint for the given user, or nil if the
+	// user has never been synced.
+	Checkpoint(ctx context.Context, userID int) (*Checkpoint, error)
+
+	// SetCheckpoint stores the given checkpoint.
+	SetCheckpoint(ctx context.Context, checkpoint *Checkpoint) error
+
+	// Institutions returns the stored institutions.
+	Institutions(ctx context.Context) (pocketsmith.Institutions, error)
+
+	// ReplaceInstitutions replaces all stored institutions.
+	ReplaceInstitutions(ctx context.Context, institutions pocketsmith.Institutions) error
+
+	// Accounts returns the stored accounts.
+	Accounts(ctx context.Context) (pocketsmith.Accounts, error)
+
+	// ReplaceAccounts replaces all stored accounts.
+	ReplaceAccounts(ctx context.Context, accounts pocketsmith.Accounts) error
+
+	// TransactionAccounts returns the stored transaction accounts.
+	TransactionAccounts(ctx context.Context) (pocketsmith.TransactionAccounts, error)
+
+	// ReplaceTransactionAccounts replaces all stored transaction accounts.
+	ReplaceTransactionAccounts(
+		ctx context.Context,
+		transactionAccounts pocketsmith.TransactionAccounts,
+	) error
+
+	// Categories returns the stored categories.
+	Categories(ctx context.Context) (pocketsmith.Categories, error)
+
+	// ReplaceCategories replaces all stored categories.
+	ReplaceCategories(ctx context.Context, categories pocketsmith.Categories) error
+
+	// Transactions returns the stored transactions, ordered by date.
+	Transactions(ctx context.Context) (pocketsmith.Transactions, error)
+
+	// UpsertTransactions adds the given transactions, replacing any stored
+	// transactions with the same id.
+	UpsertTransactions(ctx context.Context, transactions pocketsmith.Transactions) error
+
+	// DeleteTransactions removes the stored transactions with the given ids.
+	DeleteTransactions(ctx context.Context, ids ...int32) error
+}
@@ -0,0 +1,480 @@
+// Package sync mirrors a PocketSmith user into a local Store.
+//
+// The first sync for a user pulls everything; subsequent syncs only fetch the
+// transactions that have changed since the last sync (via `updated_since`),
+// plus a trailing window of recent transactions that is used to detect
+// deletions.
+package sync
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// The name of the tracer output in the traces.
+const tracerName = "pocketsmith-go/sync"
+
+// API defines the parts of the *pocketsmith.Client used by a Syncer.
+type API interface {
+	ListInstitutionsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListInstitutionsForUser,
+	) (pocketsmith.Institutions, error)
+	ListAccountsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListAccountsForUserOptions,
+	) (pocketsmith.Accounts, error)
+	ListTransactionAccountsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionAccountsForUserOptions,
+	) (pocketsmith.TransactionAccounts, error)
+	ListCategoriesForUser(
+		ctx context.Context,
+		options *pocketsmith.ListCategoriesForUserOptions,
+	) (pocketsmith.Categories, error)
+	ListTransactionsForUser(
+		ctx context.Context,
+		options *pocketsmith.ListTransactionsForUserOptions,
+	) (pocketsmith.Transactions, error)
+}
+
+// Syncer mirrors a PocketSmith user into a Store.
+type Syncer struct {
+
+	// config.
+	api    API   // The API to sync from.
+	store  Store // The store to sync into.
+	userID int   // The id of the user to sync.
+
+	// behaviour.
+	deletionWindow int              // The number of days of recent transactions re-listed to detect deletions.
+	fullSyncEvery  time.Duration    // How often a full sync is forced, regardless of checkpoints.
+	now            func() time.Time // The clock used by the syncer.
+
+	// misc.
+	logger *slog.Logger // The logger used in this syncer.
+}
+
+// Option configures a Syncer.
+type Option func(*Syncer) error
+
+// WithDeletionWindow sets the number of days of recent transactions that are
+// re-listed on every incremental sync, to detect deleted transactions. Setting
+// this to 0 disables deletion detection between full syncs. Defaults to 90.
+func WithDeletionWindow(days int) Option {
+	return func(s *Syncer) error {
+		if days < 0 {
+			return fmt.Errorf("deletion window must not be negative; got %v", days)
+		}
+		s.deletionWindow = days
+		return nil
+	}
+}
+
+// WithFullSyncEvery forces a full sync when the last full sync is older than
+// the given duration. Setting this to 0 means a full sync only happens when
+// there is no checkpoint. Defaults to 0.
+func WithFullSyncEvery(d time.Duration) Option {
+	return func(s *Syncer) error {
+		s.fullSyncEvery = d
+		return nil
+	}
+}
+
+// WithLogger overwrites the default logger with the given custom logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Syncer) error {
+		s.logger = logger
+		return nil
+	}
+}
+
+// New creates and returns a new Syncer, that mirrors the given user from the
+// given API into the given store.
+func New(api API, store Store, userID int, options ...Option) (*Syncer, error) {
+
+	// check args.
+	if store == nil {
+		return nil, ErrSyncerNilStore{}
+	}
+
+	// default syncer.
+	s := &Syncer{
+		api:            api,
+		store:          store,
+		userID:         userID,
+		deletionWindow: 90,
+		now:            time.Now,
+		logger:         slog.Default(),
+	}
+
+	// overwrite syncer with any given options.
+	for _, o := range options {
+		if err := o(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Changes counts the changes made to the store for a type of entity.
+type Changes struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+}
+
+// Result describes the outcome of a sync.
+type Result struct {
+	Full                bool    `json:"full"` // If this sync was a full pull.
+	Institutions        Changes `json:"institutions"`
+	Accounts            Changes `json:"accounts"`
+	TransactionAccounts Changes `json:"transaction_accounts"`
+	Categories          Changes `json:"categories"`
+	Transactions        Changes `json:"transactions"`
+}
+
+// Sync mirrors the user into the store. The first sync (or a forced full
+// sync) pulls everything; subsequent syncs only fetch what has changed.
+func (s *Syncer) Sync(ctx context.Context) (result *Result, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "Sync")
+	defer span.End()
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, fmt.Sprintf("failed to sync: %v", err))
+			span.RecordError(err)
+		}
+	}()
+
+	// determine if this should be a full sync.
+	now := s.now()
+	checkpoint, err := s.store.Checkpoint(newCtx, s.userID)
+	if err != nil {
+		return nil, ErrSyncerFailedStore{"checkpoint", err}
+	}
+	result = &Result{Full: checkpoint == nil}
+	if checkpoint == nil {
+		checkpoint = &Checkpoint{UserID: s.userID}
+	}
+	if s.fullSyncEvery > 0 && now.Sub(checkpoint.LastFullSync) >= s.fullSyncEvery {
+		result.Full = true
+	}
+	span.SetAttributes(attribute.Bool("full", result.Full))
+
+	// sync everything but transactions; these are small enough to always be
+	// listed in full.
+	if result.Institutions, err = s.syncInstitutions(newCtx); err != nil {
+		return nil, err
+	}
+	if result.Accounts, err = s.syncAccounts(newCtx); err != nil {
+		return nil, err
+	}
+	transactionAccountIDs, changes, err := s.syncTransactionAccounts(newCtx)
+	if err != nil {
+		return nil, err
+	}
+	result.TransactionAccounts = changes
+	if result.Categories, err = s.syncCategories(newCtx); err != nil {
+		return nil, err
+	}
+
+	// sync transactions.
+	var cursor time.Time
+	if result.Full {
+		result.Transactions, cursor, err = s.syncAllTransactions(newCtx)
+	} else {
+		result.Transactions, cursor, err = s.syncChangedTransactions(
+			newCtx,
+			checkpoint.TransactionsUpdatedSince,
+			transactionAccountIDs,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// update checkpoint.
+	if cursor.After(checkpoint.TransactionsUpdatedSince) {
+		checkpoint.TransactionsUpdatedSince = cursor
+	}
+	checkpoint.LastSync = now
+	if result.Full {
+		checkpoint.LastFullSync = now
+	}
+	if err := s.store.SetCheckpoint(newCtx, checkpoint); err != nil {
+		return nil, ErrSyncerFailedStore{"checkpoint", err}
+	}
+
+	s.logger.Debug("synced user", "user_id", s.userID, "result", result)
+	return result, nil
+}
+
+// syncInstitutions replaces the stored institutions with those from the API.
+func (s *Syncer) syncInstitutions(ctx context.Context) (Changes, error) {
+	institutions, err := s.api.ListInstitutionsForUser(
+		ctx,
+		&pocketsmith.ListInstitutionsForUser{UserID: s.userID},
+	)
+	if err != nil {
+		return Changes{}, ErrSyncerFailedList{"institutions", err}
+	}
+	stored, err := s.store.Institutions(ctx)
+	if err != nil {
+		return Changes{}, ErrSyncerFailedStore{"institutions", err}
+	}
+	if err := s.store.ReplaceInstitutions(ctx, institutions); err != nil {
+		return Changes{}, ErrSyncerFailedStore{"institutions", err}
+	}
+	return diff(stored, institutions, func(i pocketsmith.Institution) (int64, time.Time) {
+		return int64(i.ID), i.UpdatedAt
+	}), nil
+}
+
+// syncAccounts replaces the stored accounts with those from the API.
+func (s *Syncer) syncAccounts(ctx context.Context) (Changes, error) {
+	accounts, err := s.api.ListAccountsForUser(
+		ctx,
+		&pocketsmith.ListAccountsForUserOptions{UserID: s.userID},
+	)
+	if err != nil {
+		return Changes{}, ErrSyncerFailedList{"accounts", err}
+	}
+	stored, err := s.store.Accounts(ctx)
+	if err != nil {
+		return Changes{}, ErrSyncerFailedStore{"accounts", err}
+	}
+	if err := s.store.ReplaceAccounts(ctx, accounts); err != nil {
+		return Changes{}, ErrSyncerFailedStore{"accounts", err}
+	}
+	return diff(stored, accounts, func(a pocketsmith.Account) (int64, time.Time) {
+		return int64(a.ID), a.UpdatedAt
+	}), nil
+}
+
+// syncTransactionAccounts replaces the stored transaction accounts with those
+// from the API, returning the ids of the transaction accounts that exist.
+func (s *Syncer) syncTransactionAccounts(ctx context.Context) (map[int]bool, Changes, error) {
+	transactionAccounts, err := s.api.ListTransactionAccountsForUser(
+		ctx,
+		&pocketsmith.ListTransactionAccountsForUserOptions{UserID: s.userID},
+	)
+	if err != nil {
+		return nil, Changes{}, ErrSyncerFailedList{"transaction accounts", err}
+	}
+	stored, err := s.store.TransactionAccounts(ctx)
+	if err != nil {
+		return nil, Changes{}, ErrSyncerFailedStore{"transaction accounts", err}
+	}
+	if err := s.store.ReplaceTransactionAccounts(ctx, transactionAccounts); err != nil {
+		return nil, Changes{}, ErrSyncerFailedStore{"transaction accounts", err}
+	}
+	ids := make(map[int]bool, len(transactionAccounts))
+	for _, ta := range transactionAccounts {
+		ids[ta.ID] = true
+	}
+	return ids, diff(
+		stored,
+		transactionAccounts,
+		func(ta pocketsmith.TransactionAccount) (int64, time.Time) {
+			return int64(ta.ID), ta.UpdatedAt
+		},
+	), nil
+}
+
+// syncCategories replaces the stored categories with those from the API.
+func (s *Syncer) syncCategories(ctx context.Context) (Changes, error) {
+	categories, err := s.api.ListCategoriesForUser(
+		ctx,
+		&pocketsmith.ListCategoriesForUserOptions{UserID: s.userID},
+	)
+	if err != nil {
+		return Changes{}, ErrSyncerFailedList{"categories", err}
+	}
+	stored, err := s.store.Categories(ctx)
+	if err != nil {
+		return Changes{}, ErrSyncerFailedStore{"categories", err}
+	}
+	if err := s.store.ReplaceCategories(ctx, categories); err != nil {
+		return Changes{}, ErrSyncerFailedStore{"categories", err}
+	}
+	return diff(flatten(stored), flatten(categories), func(c pocketsmith.Category) (int64, time.Time) {
+		return int64(c.ID), c.UpdatedAt
+	}), nil
+}
+
+// syncAllTransactions lists every transaction for the user, replacing the
+// stored transactions.
+func (s *Syncer) syncAllTransactions(ctx context.Context) (Changes, time.Time, error) {
+	transactions, err := s.api.ListTransactionsForUser(
+		ctx,
+		&pocketsmith.ListTransactionsForUserOptions{UserID: s.userID},
+	)
+	if err != nil {
+		return Changes{}, time.Time{}, ErrSyncerFailedList{"transactions", err}
+	}
+	stored, err := s.store.Transactions(ctx)
+	if err != nil {
+		return Changes{}, time.Time{}, ErrSyncerFailedStore{"transactions", err}
+	}
+	changes := diff(stored, transactions, transactionKey)
+
+	// remove any transactions that no longer exist.
+	seen := make(map[int32]bool, len(transactions))
+	for _, t := range transactions {
+		seen[t.ID] = true
+	}
+	var deleted []int32
+	for _, t := range stored {
+		if !seen[t.ID] {
+			deleted = append(deleted, t.ID)
+		}
+	}
+	if err := s.store.DeleteTransactions(ctx, deleted...); err != nil {
+		return Changes{}, time.Time{}, ErrSyncerFailedStore{"transactions", err}
+	}
+	if err := s.store.UpsertTransactions(ctx, transactions); err != nil {
+		return Changes{}, time.Time{}, ErrSyncerFailedStore{"transactions", err}
+	}
+	return changes, latest(transactions), nil
+}
+
+// syncChangedTransactions lists the transactions updated since the given
+// time, plus the transactions in the deletion window, and applies them to the
+// store.
+func (s *Syncer) syncChangedTransactions(
+	ctx context.Context,
+	since time.Time,
+	transactionAccountIDs map[int]bool,
+) (changes Changes, cursor time.Time, err error) {
+
+	// list changed transactions.
+	options := &pocketsmith.ListTransactionsForUserOptions{UserID: s.userID}
+	if !since.IsZero() {
+		options.UpdatedSince = since.UTC().Format(time.RFC3339)
+	}
+	changed, err := s.api.ListTransactionsForUser(ctx, options)
+	if err != nil {
+		return Changes{}, time.Time{}, ErrSyncerFailedList{"transactions", err}
+	}
+
+	// list transactions in the deletion window.
+	var window pocketsmith.Transactions
+	start := ""
+	if s.deletionWindow > 0 {
+		start = s.now().AddDate(0, 0, -s.deletionWindow).Format("2006-01-02")
+		window, err = s.api.ListTransactionsForUser(ctx, &pocketsmith.ListTransactionsForUserOptions{
+			UserID:                  s.userID,
+			ListTransactionsOptions: pocketsmith.ListTransactionsOptions{StartDate: start},
+		})
+		if err != nil {
+			return Changes{}, time.Time{}, ErrSyncerFailedList{"transactions", err}
+		}
+	}
+
+	// merge changed & window transactions.
+	fetched := make(pocketsmith.Transactions, 0, len(changed)+len(window))
+	seen := make(map[int32]bool, len(changed)+len(window))
+	for _, t := range append(changed, window...) {
+		if seen[t.ID] {
+			continue
+		}
+		seen[t.ID] = true
+		fetched = append(fetched, t)
+	}
+
+	// determine what was added or updated.
+	stored, err := s.store.Transactions(ctx)
+	if err != nil {
+		return Changes{}, time.Time{}, ErrSyncerFailedStore{"transactions", err}
+	}
+	changes = diff(stored, fetched, transactionKey)
+	changes.Deleted = 0
+
+	// determine what was deleted; either transactions in the window that
+	// weren't returned, or transactions in a transaction account that no
+	// longer exists.
+	windowIDs := make(map[int32]bool, len(window))
+	for _, t := range window {
+		windowIDs[t.ID] = true
+	}
+	var deleted []int32
+	for _, t := range stored {
+		switch {
+		case !transactionAccountIDs[t.TransactionAccount.ID],
+			start != "" && t.Date >= start && !windowIDs[t.ID] && !seen[t.ID]:
+			deleted = append(deleted, t.ID)
+		}
+	}
+	changes.Deleted = len(deleted)
+
+	// apply changes.
+	if err := s.store.DeleteTransactions(ctx, deleted...); err != nil {
+		return Changes{}, time.Time{}, ErrSyncerFailedStore{"transactions", err}
+	}
+	if err := s.store.UpsertTransactions(ctx, fetched); err != nil {
+		return Changes{}, time.Time{}, ErrSyncerFailedStore{"transactions", err}
+	}
+	return changes, latest(fetched), nil
+}
+
+// transactionKey returns the id and updated at time of a transaction.
+func transactionKey(t pocketsmith.Transaction) (int64, time.Time) {
+	return int64(t.ID), t.UpdatedAt
+}
+
+// latest returns the latest updated at time of the given transactions.
+func latest(transactions pocketsmith.Transactions) (out time.Time) {
+	for _, t := range transactions {
+		if t.UpdatedAt.After(out) {
+			out = t.UpdatedAt
+		}
+	}
+	return out
+}
+
+// diff compares the stored entities against the fetched entities, using the
+// given key function to identify each entity and when it was last updated.
+func diff[T any](stored, fetched []T, key func(T) (int64, time.Time)) (changes Changes) {
+	before := make(map[int64]time.Time, len(stored))
+	for _, e := range stored {
+		id, updatedAt := key(e)
+		before[id] = updatedAt
+	}
+	after := make(map[int64]bool, len(fetched))
+	for _, e := range fetched {
+		id, updatedAt := key(e)
+		after[id] = true
+		previous, ok := before[id]
+		switch {
+		case !ok:
+			changes.Added++
+		case !previous.Equal(updatedAt):
+			changes.Updated++
+		}
+	}
+	for id := range before {
+		if !after[id] {
+			changes.Deleted++
+		}
+	}
+	return changes
+}
+
+// flatten returns the given categories, and all of their children, as a flat
+// slice.
+func flatten(categories pocketsmith.Categories) (out pocketsmith.Categories) {
+	for _, c := range pocketsmith.NewCategoryTree(categories).Flatten() {
+		out = append(out, *c.Category)
+	}
+	return out
+}
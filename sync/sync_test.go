@@ -0,0 +1,172 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmpa-io/pocketsmith-go"
+)
+
+// mockAPI is a mock implementation of the API interface, that filters the
+// transactions it holds by the given options.
+type mockAPI struct {
+	institutions        pocketsmith.Institutions
+	accounts            pocketsmith.Accounts
+	transactionAccounts pocketsmith.TransactionAccounts
+	categories          pocketsmith.Categories
+	transactions        pocketsmith.Transactions
+	calls               []pocketsmith.ListTransactionsOptions
+}
+
+func (m *mockAPI) ListInstitutionsForUser(
+	context.Context,
+	*pocketsmith.ListInstitutionsForUser,
+) (pocketsmith.Institutions, error) {
+	return m.institutions, nil
+}
+
+func (m *mockAPI) ListAccountsForUser(
+	context.Context,
+	*pocketsmith.ListAccountsForUserOptions,
+) (pocketsmith.Accounts, error) {
+	return m.accounts, nil
+}
+
+func (m *mockAPI) ListTransactionAccountsForUser(
+	context.Context,
+	*pocketsmith.ListTransactionAccountsForUserOptions,
+) (pocketsmith.TransactionAccounts, error) {
+	return m.transactionAccounts, nil
+}
+
+func (m *mockAPI) ListCategoriesForUser(
+	context.Context,
+	*pocketsmith.ListCategoriesForUserOptions,
+) (pocketsmith.Categories, error) {
+	return m.categories, nil
+}
+
+func (m *mockAPI) ListTransactionsForUser(
+	_ context.Context,
+	options *pocketsmith.ListTransactionsForUserOptions,
+) (out pocketsmith.Transactions, err error) {
+	m.calls = append(m.calls, options.ListTransactionsOptions)
+	for _, t := range m.transactions {
+		if options.StartDate != "" && t.Date < options.StartDate {
+			continue
+		}
+		if options.UpdatedSince != "" {
+			since, _ := time.Parse(time.RFC3339, options.UpdatedSince)
+			if t.UpdatedAt.Before(since) {
+				continue
+			}
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// transaction returns a transaction in transaction account 1.
+func transaction(id int32, date string, updatedAt time.Time) pocketsmith.Transaction {
+	return pocketsmith.Transaction{
+		ID:                 id,
+		Date:               date,
+		UpdatedAt:          updatedAt,
+		TransactionAccount: pocketsmith.TransactionAccount{ID: 1},
+	}
+}
+
+func Test_Sync(t *testing.T) {
+
+	// setup times.
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	t1 := now.Add(-48 * time.Hour)
+	t2 := now.Add(-1 * time.Hour)
+
+	// setup mock.
+	api := &mockAPI{
+		institutions:        pocketsmith.Institutions{{ID: 1, UpdatedAt: t1}},
+		accounts:            pocketsmith.Accounts{{ID: 1, UpdatedAt: t1}},
+		transactionAccounts: pocketsmith.TransactionAccounts{{ID: 1, UpdatedAt: t1}},
+		categories: pocketsmith.Categories{
+			{ID: 1, UpdatedAt: t1, Children: []*pocketsmith.Category{{ID: 2, UpdatedAt: t1}}},
+		},
+		transactions: pocketsmith.Transactions{
+			transaction(1, "2023-01-01", t1),
+			transaction(2, "2024-06-01", t1),
+			transaction(3, "2024-06-02", t1),
+		},
+	}
+
+	// setup syncer.
+	store, err := NewJSONFileStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("NewJSONFileStore() returned an error; error=%v", err)
+	}
+	s, err := New(api, store, 1, WithDeletionWindow(90))
+	if err != nil {
+		t.Fatalf("New() returned an error; error=%v", err)
+	}
+	s.now = func() time.Time { return now }
+
+	// first sync is a full sync.
+	got, err := s.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() returned an error; error=%v", err)
+	}
+	want := &Result{
+		Full:                true,
+		Institutions:        Changes{Added: 1},
+		Accounts:            Changes{Added: 1},
+		TransactionAccounts: Changes{Added: 1},
+		Categories:          Changes{Added: 2},
+		Transactions:        Changes{Added: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sync() returned an unexpected result;\nwant=%+v\ngot=%+v\n", want, got)
+	}
+
+	// change the data in the API; update one transaction, delete one
+	// (in the window), and add one.
+	api.transactions = pocketsmith.Transactions{
+		transaction(1, "2023-01-01", t2),
+		transaction(2, "2024-06-01", t1),
+		transaction(4, "2024-06-03", t2),
+	}
+
+	// second sync is incremental, using the checkpoint.
+	api.calls = nil
+	got, err = s.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() returned an error; error=%v", err)
+	}
+	want = &Result{
+		Transactions: Changes{Added: 1, Updated: 1, Deleted: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sync() returned an unexpected result;\nwant=%+v\ngot=%+v\n", want, got)
+	}
+	if len(api.calls) == 0 || api.calls[0].UpdatedSince != t1.Format(time.RFC3339) {
+		t.Errorf("Sync() didn't use the checkpoint;\ncalls=%+v\n", api.calls)
+	}
+
+	// the store should now mirror the API, and survive being reloaded.
+	reloaded, err := NewJSONFileStore(store.path)
+	if err != nil {
+		t.Fatalf("NewJSONFileStore() returned an error; error=%v", err)
+	}
+	stored, err := reloaded.Transactions(context.Background())
+	if err != nil {
+		t.Fatalf("Transactions() returned an error; error=%v", err)
+	}
+	var ids []int32
+	for _, t := range stored {
+		ids = append(ids, t.ID)
+	}
+	if !reflect.DeepEqual(ids, []int32{1, 2, 4}) {
+		t.Errorf("store has unexpected transactions;\nwant=%v\ngot=%v\n", []int32{1, 2, 4}, ids)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -68,6 +67,88 @@ func (c *Client) ListTransactionAccounts(ctx context.Context) (TransactionAccoun
 	)
 }
 
+// GetTransactionAccountOptions defines the options for getting a transaction
+// account from Pocketsmith, by the transaction account id.
+type GetTransactionAccountOptions struct {
+	TransactionAccountID int `validator:"required"`
+}
+
+// GetTransactionAccount gets a transaction account from Pocketsmith, by the
+// transaction account id.
+// https://developers.pocketsmith.com/reference/get_transaction-accounts-id-1.
+func (c *Client) GetTransactionAccount(
+	ctx context.Context,
+	options *GetTransactionAccountOptions,
+) (account *TransactionAccount, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "GetTransactionAccount")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// get transaction account.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/transaction_accounts/%v", options.TransactionAccountID),
+	}, &account)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to get transaction account: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return account, nil
+}
+
+// UpdateTransactionAccountOptions defines the options for updating a
+// transaction account in Pocketsmith, by the transaction account id. Fields
+// left empty are unchanged.
+type UpdateTransactionAccountOptions struct {
+	TransactionAccountID int      `json:"-"                               validator:"required"`
+	InstitutionID        int      `json:"institution_id,omitempty"`        // Moves the transaction account to another institution.
+	StartingBalance      *float64 `json:"starting_balance,omitempty"`      // nil leaves the balance unchanged.
+	StartingBalanceDate  string   `json:"starting_balance_date,omitempty"` // eg. "2024-01-31".
+}
+
+// UpdateTransactionAccount updates a transaction account in Pocketsmith, by
+// the transaction account id. Changing the starting balance changes the
+// closing balance of every transaction in the account.
+// https://developers.pocketsmith.com/reference/put_transaction-accounts-id-1.
+func (c *Client) UpdateTransactionAccount(
+	ctx context.Context,
+	options *UpdateTransactionAccountOptions,
+) (account *TransactionAccount, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "UpdateTransactionAccount")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// update transaction account.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/transaction_accounts/%v", options.TransactionAccountID),
+		body:   options,
+	}, &account)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to update transaction account: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return account, nil
+}
+
 // CreateTransactionAccountTransactionOptions defines the options for creating
 // a transaction in the given transaction account in Pocketsmith, by the
 // transaction account id.
@@ -83,10 +164,21 @@ type CreateTransactionAccountTransactionOptions struct {
 	Memo                 string  `json:"memo,omitempty"`
 	ChequeNumber         string  `json:"cheque_number,omitempty"`
 	NeedsReview          bool    `json:"needs_review,omitempty"`
+
+	// Idempotent stops the same transaction being created twice, eg. when a
+	// request is retried after timing out. See IdempotencyKey.
+	Idempotent bool `json:"-"`
+
+	// IdempotencyKey is the key used when Idempotent is set, instead of one
+	// derived from the options; so that distinct transactions with identical
+	// options, eg. two coffees on the same day, aren't treated as the same.
+	IdempotencyKey string `json:"-"`
 }
 
 // CreateTransactionAccountTransaction creates a transaction in the given
-// transaction account in Pocketsmith, by the transaction account id.
+// transaction account in Pocketsmith, by the transaction account id. When
+// options.Idempotent is set, an existing transaction created with the same
+// options is returned instead of creating another.
 // https://developers.pocketsmith.com/reference/post_transaction-accounts-id-transactions-1.
 func (c *Client) CreateTransactionAccountTransaction(
 	ctx context.Context,
@@ -105,6 +197,11 @@ func (c *Client) CreateTransactionAccountTransaction(
 		return nil, err
 	}
 
+	// create transaction account transaction, only once.
+	if options.Idempotent {
+		return c.createIdempotent(newCtx, options)
+	}
+
 	// create transaction account transaction.
 	_, err = c.sender(newCtx, senderRequest{
 		method: http.MethodPost,
@@ -156,47 +253,70 @@ func (c *Client) ListTransactionAccountTransactions(
 		Start(ctx, "ListTransactionAccountTransactions")
 	defer span.End()
 
+	// list transaction account transactions.
+	err = c.ListTransactionAccountTransactionsPages(
+		newCtx,
+		options,
+		func(batch Transactions) error {
+			transactions = append(transactions, batch...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ListTransactionAccountTransactionsPages lists transactions in a transaction
+// account from Pocketsmith, by the transaction account id, calling fn with
+// each page of transactions as it is returned from the API. Returning an error
+// from fn stops the listing and returns that error.
+func (c *Client) ListTransactionAccountTransactionsPages(
+	ctx context.Context,
+	options *ListTransactionAccountTransactionsOptions,
+	fn func(Transactions) error,
+) error {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).
+		Start(ctx, "ListTransactionAccountTransactionsPages")
+	defer span.End()
+
 	// validate options.
 	if err := c.validator.StructCtx(newCtx, options); err != nil {
 		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
 		span.RecordError(err)
-		return nil, err
+		return err
 	}
 
 	// setup request.
+	queries, err := toQueries(options)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to setup queries: %v", err))
+		span.RecordError(err)
+		return err
+	}
+	if options.UpdatedSince.IsZero() {
+		delete(*queries, "updated_since") // time.Time is never omitted when marshalled.
+	}
 	sr := senderRequest{
 		method: http.MethodGet,
 		path: fmt.Sprintf(
 			"/transaction_accounts/%v/transactions",
 			options.TransactionAccountID,
 		),
-		queries: setupQueries(nil),
+		queries: setupQueries(queries),
 	}
 
 	// list transaction account transactions.
-	for {
-
-		// get batch.
-		var batch []Transaction
-		resp, err := c.sender(newCtx, sr, &batch)
-		if err != nil {
-			span.SetStatus(
-				codes.Error,
-				fmt.Sprintf("failed to list transaction account transactions: %v", err),
-			)
-			span.RecordError(err)
-			return nil, err
-		}
-
-		// extract batch data.
-		transactions = append(transactions, batch...)
-
-		// paginate?
-		next := getHeader(resp.Header, "next")
-		if next == "" {
-			break
-		}
-		sr.path = strings.Replace(next, c.endpoint, "", -1)
+	if err := c.transactionPages(newCtx, sr, fn); err != nil {
+		span.SetStatus(
+			codes.Error,
+			fmt.Sprintf("failed to list transaction account transactions: %v", err),
+		)
+		span.RecordError(err)
+		return err
 	}
-	return transactions, nil
+	return nil
 }
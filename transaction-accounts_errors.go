@@ -0,0 +1,16 @@
+package pocketsmith
+
+import (
+	"fmt"
+)
+
+// ErrInvalidDate is returned when a date isn't in the format the API uses,
+// eg. "2024-01-31".
+type ErrInvalidDate struct {
+	field string
+	value string
+}
+
+func (e ErrInvalidDate) Error() string {
+	return fmt.Sprintf("invalid %s %q; expected a date like 2024-01-31", e.field, e.value)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -25,6 +26,7 @@ type UpdateTransactionOptions struct {
 	Note          string  `json:"note,omitempty"`
 	Memo          string  `json:"memo,omitempty"`
 	ChequeNumber  string  `json:"cheque_number,omitempty"`
+	NeedsReview   *bool   `json:"needs_review,omitempty"` // nil leaves the flag unchanged.
 }
 
 // UpdateTransaction updates a transaction in Pocketsmith, by the given
@@ -59,3 +61,267 @@ func (c *Client) UpdateTransaction(
 	}
 	return transaction, nil
 }
+
+// DeleteTransactionOptions defines the options for deleting a transaction in
+// Pocketsmith, by the given transaction id.
+type DeleteTransactionOptions struct {
+	TransactionID int32 `json:"-" validator:"required"`
+}
+
+// DeleteTransaction deletes a transaction in Pocketsmith, by the given
+// transaction id.
+// https://developers.pocketsmith.com/reference/delete_transactions-id.
+func (c *Client) DeleteTransaction(ctx context.Context, options *DeleteTransactionOptions) error {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "DeleteTransaction")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return err
+	}
+
+	// delete transaction.
+	_, err := c.sender(newCtx, senderRequest{
+		method: http.MethodDelete,
+		path:   fmt.Sprintf("/transactions/%v", options.TransactionID),
+	}, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to delete transaction: %v", err))
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// ListTransactionsOptions defines the options for listing transactions for
+// the authed user.
+type ListTransactionsOptions struct {
+	StartDate     string `json:"start_date,omitempty"`
+	EndDate       string `json:"end_date,omitempty"`
+	UpdatedSince  string `json:"updated_since,omitempty"` // must be an ISO 8601 date time.
+	Uncategorised bool   `json:"uncategorised,omitempty"`
+	Type          string `json:"type,omitempty"`
+	NeedsReview   bool   `json:"needs_review,omitempty"`
+	Search        string `json:"search,omitempty"`
+}
+
+// ListTransactionsForUserOptions defines the options for listing transactions
+// for the given user, by the user id.
+type ListTransactionsForUserOptions struct {
+	UserID int `json:"-" validator:"required"`
+
+	ListTransactionsOptions
+}
+
+// ListTransactionsForUser lists the transactions for the given user in
+// Pocketsmith, by the user id.
+// https://developers.pocketsmith.com/reference/get_users-id-transactions-1.
+func (c *Client) ListTransactionsForUser(
+	ctx context.Context,
+	options *ListTransactionsForUserOptions,
+) (transactions Transactions, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListTransactionsForUser")
+	defer span.End()
+
+	// list transactions.
+	err = c.ListTransactionsForUserPages(newCtx, options, func(batch Transactions) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ListTransactionsForUserPages lists the transactions for the given user in
+// Pocketsmith, by the user id, calling fn with each page of transactions as it
+// is returned from the API. This allows large lists of transactions to be
+// streamed, rather than held in memory. Returning an error from fn stops the
+// listing and returns that error.
+func (c *Client) ListTransactionsForUserPages(
+	ctx context.Context,
+	options *ListTransactionsForUserOptions,
+	fn func(Transactions) error,
+) error {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListTransactionsForUserPages")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return err
+	}
+
+	// setup request.
+	queries, err := toQueries(options)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to setup queries: %v", err))
+		span.RecordError(err)
+		return err
+	}
+	sr := senderRequest{
+		method:  http.MethodGet,
+		path:    fmt.Sprintf("/users/%v/transactions", options.UserID),
+		queries: setupQueries(queries),
+	}
+
+	// list transactions.
+	if err := c.transactionPages(newCtx, sr, fn); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to list transactions: %v", err))
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// ListTransactionsForCategoryOptions defines the options for listing the
+// transactions in the given category, by the category id.
+type ListTransactionsForCategoryOptions struct {
+	CategoryID int32 `json:"-" validator:"required"`
+
+	ListTransactionsOptions
+}
+
+// ListTransactionsForCategoryPages lists the transactions in the given
+// category in Pocketsmith, by the category id, calling fn with each page of
+// transactions as it is returned from the API; see
+// ListTransactionsForUserPages.
+// https://developers.pocketsmith.com/reference/get_categories-id-transactions-1.
+func (c *Client) ListTransactionsForCategoryPages(
+	ctx context.Context,
+	options *ListTransactionsForCategoryOptions,
+	fn func(Transactions) error,
+) error {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListTransactionsForCategoryPages")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return err
+	}
+
+	// setup request.
+	queries, err := toQueries(options)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to setup queries: %v", err))
+		span.RecordError(err)
+		return err
+	}
+	sr := senderRequest{
+		method:  http.MethodGet,
+		path:    fmt.Sprintf("/categories/%v/transactions", options.CategoryID),
+		queries: setupQueries(queries),
+	}
+
+	// list transactions.
+	if err := c.transactionPages(newCtx, sr, fn); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to list transactions: %v", err))
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// ListTransactions, using the token attached to the client, lists the
+// transactions for the authed user. Nil options list every transaction.
+func (c *Client) ListTransactions(
+	ctx context.Context,
+	options *ListTransactionsOptions,
+) (Transactions, error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "ListTransactions")
+	defer span.End()
+
+	// list transactions for authed user.
+	if options == nil {
+		options = &ListTransactionsOptions{}
+	}
+	return c.ListTransactionsForUser(
+		newCtx,
+		&ListTransactionsForUserOptions{UserID: c.authedUser.ID, ListTransactionsOptions: *options},
+	)
+}
+
+// transactionPages sends the given senderRequest to the API, following the
+// `next` link returned in each response, and calls fn with each page of
+// transactions returned.
+func (c *Client) transactionPages(
+	ctx context.Context,
+	sr senderRequest,
+	fn func(Transactions) error,
+) error {
+	for {
+
+		// get batch.
+		var batch Transactions
+		resp, err := c.sender(ctx, sr, &batch)
+		if err != nil {
+			return err
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		// paginate?
+		// NOTE: the `next` link already contains the queries from the original
+		// request, so they don't need to be sent again.
+		next := getHeader(resp.Header, "next")
+		if next == "" {
+			return nil
+		}
+		sr.path = strings.Replace(next, c.endpoint, "", -1)
+		sr.queries = nil
+		sr.body = nil
+	}
+}
+
+// GetTransactionOptions defines the options for getting a transaction.
+type GetTransactionOptions struct {
+	TransactionID int32 `json:"-" validator:"required"`
+}
+
+// GetTransaction gets a transaction from Pocketsmith, by the transaction id.
+// https://developers.pocketsmith.com/reference/get_transactions-id-1.
+func (c *Client) GetTransaction(
+	ctx context.Context,
+	options *GetTransactionOptions,
+) (transaction *Transaction, err error) {
+
+	// setup tracing.
+	newCtx, span := otel.Tracer(c.tracerName).Start(ctx, "GetTransaction")
+	defer span.End()
+
+	// validate options.
+	if err := c.validator.StructCtx(newCtx, options); err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to validate options: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// get transaction.
+	_, err = c.sender(newCtx, senderRequest{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/transactions/%v", options.TransactionID),
+	}, &transaction)
+	if err != nil {
+		span.SetStatus(codes.Error, fmt.Sprintf("failed to get transaction: %v", err))
+		span.RecordError(err)
+		return nil, err
+	}
+	return transaction, nil
+}
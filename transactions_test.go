@@ -0,0 +1,44 @@
+package pocketsmith
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func Test_ListTransactions(t *testing.T) {
+	tests := map[string]struct {
+		options   *ListTransactionsOptions
+		wantQuery string
+	}{
+		"nil options": {
+			wantQuery: "page_size=100",
+		},
+		"with options": {
+			options:   &ListTransactionsOptions{Search: "coffee"},
+			wantQuery: "page_size=100&search=coffee",
+		},
+	}
+
+	// run tests.
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var query string
+			c := newMockClient(func(req *http.Request) *http.Response {
+				query = req.URL.RawQuery
+				return mockResponse(http.StatusOK, `[{"id":1}]`)
+			})
+			c.authedUser = &User{ID: 1}
+			transactions, err := c.ListTransactions(context.Background(), tt.options)
+			if err != nil {
+				t.Fatalf("ListTransactions() returned an error: %v", err)
+			}
+			if len(transactions) != 1 {
+				t.Errorf("ListTransactions() returned unexpected transactions; got=%+v", transactions)
+			}
+			if query != tt.wantQuery {
+				t.Errorf("ListTransactions() sent an unexpected query;\nwant=%q\ngot=%q", tt.wantQuery, query)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ type User struct {
 	AvatarURL               string `json:"avatar_url"`
 	BetaUser                bool   `json:"beta_user"`
 	TimeZone                string `json:"time_zone"`
+	UTCOffset               int    `json:"utc_offset"` // The offset of the time zone from UTC, in minutes.
 	WeekStartDay            int    `json:"week_start_day"`
 	IsReviewingTransactions bool   `json:"is_reviewing_transactions"`
 	BaseCurrencyCode        string `json:"base_currency_code"`
@@ -95,8 +96,7 @@ func (c *Client) GetUser(ctx context.Context, options *GetUserOptions) (user *Us
 	// get user.
 	_, err = c.sender(newCtx, senderRequest{
 		method: http.MethodGet,
-		path:   "/users/id",
-		body:   options,
+		path:   fmt.Sprintf("/users/%v", options.UserID),
 	}, &user)
 	if err != nil {
 		span.SetStatus(codes.Error, fmt.Sprintf("failed to get user: %v", err))